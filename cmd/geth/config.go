@@ -228,6 +228,11 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		cfg.Eth.OverrideOptimismInterop = &v
 	}
 
+	if ctx.IsSet(utils.OverrideGolemBaseKaolin.Name) {
+		v := ctx.Uint64(utils.OverrideGolemBaseKaolin.Name)
+		cfg.Eth.OverrideGolemBaseKaolin = &v
+	}
+
 	if ctx.IsSet(utils.OverrideVerkle.Name) {
 		v := ctx.Uint64(utils.OverrideVerkle.Name)
 		cfg.Eth.OverrideVerkle = &v
@@ -236,6 +241,9 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	// Start metrics export if enabled
 	utils.SetupMetrics(&cfg.Metrics)
 
+	// Start trace export if enabled
+	utils.SetupTelemetry(ctx, stack)
+
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
 
 	// Create gauge with geth system and build information
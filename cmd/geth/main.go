@@ -73,6 +73,7 @@ var (
 		utils.OverrideOptimismIsthmus,
 		utils.OverrideOptimismJovian,
 		utils.OverrideOptimismInterop,
+		utils.OverrideGolemBaseKaolin,
 		utils.EnablePersonal, // deprecated
 		utils.TxPoolLocalsFlag,
 		utils.TxPoolNoLocalsFlag,
@@ -179,6 +180,9 @@ var (
 		utils.BeaconGenesisTimeFlag,
 		utils.BeaconCheckpointFlag,
 		utils.GolemBaseWriteAheadLogDir,
+		utils.GolemBaseHistoryFlag,
+		utils.GolemBaseQueryMaxSlotsFlag,
+		utils.GolemBaseQueryTimeoutFlag,
 	}, utils.NetworkFlags, utils.DatabaseFlags)
 
 	rpcFlags = []cli.Flag{
@@ -211,6 +215,15 @@ var (
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateLimitBurstFlag,
+		utils.RPCRateLimitCostsFlag,
+		utils.RPCRateLimitAPIKeyHeaderFlag,
+		utils.RPCRateLimitAPIKeysFlag,
+		utils.RPCJWTAuthFlag,
+		utils.RPCRecordFlag,
+		utils.RPCRecordMaxSizeFlag,
+		utils.RPCRecordMaxFilesFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -228,6 +241,9 @@ var (
 		utils.MetricsInfluxDBTokenFlag,
 		utils.MetricsInfluxDBBucketFlag,
 		utils.MetricsInfluxDBOrganizationFlag,
+		utils.TelemetryEndpointFlag,
+		utils.TelemetrySampleRatioFlag,
+		utils.TelemetryServiceNameFlag,
 	}
 )
 
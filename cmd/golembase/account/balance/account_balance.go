@@ -30,7 +30,7 @@ func AccountBalance() *cli.Command {
 			},
 		},
 		Action: func(c *cli.Context) error {
-			userAccount, err := useraccount.Load()
+			address, err := useraccount.LoadAddress(c)
 			if err != nil {
 				return fmt.Errorf("failed to load user account: %w", err)
 			}
@@ -43,12 +43,12 @@ func AccountBalance() *cli.Command {
 				return fmt.Errorf("failed to dial node: %w", err)
 			}
 
-			balance, err := ethclient.BalanceAt(ctx, userAccount.Address, nil)
+			balance, err := ethclient.BalanceAt(ctx, address, nil)
 			if err != nil {
 				return fmt.Errorf("failed to get balance: %w", err)
 			}
 
-			fmt.Println("Address:", userAccount.Address.Hex())
+			fmt.Println("Address:", address.Hex())
 			fmt.Println("Balance:", humanize.Commaf(EthToFloat(balance)), "ETH")
 
 			return nil
@@ -12,46 +12,76 @@ import (
 )
 
 func Create() *cli.Command {
+	cfg := struct {
+		dev bool
+	}{}
 	return &cli.Command{
 		Name:  "create",
 		Usage: "Create a new account",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "dev",
+				Usage:       "create the unencrypted development key instead of a keystore account (development only)",
+				Destination: &cfg.dev,
+			},
+		},
 		Action: func(c *cli.Context) error {
-			privageKeyPath, err := xdg.ConfigFile(useraccount.PrivateKeyPath)
+			if cfg.dev {
+				return createDevKey()
+			}
+
+			passphrase, err := useraccount.Passphrase(c, "Passphrase for the new account: ", true)
 			if err != nil {
-				return fmt.Errorf("failed to get config file path: %w", err)
+				return err
 			}
 
-			fmt.Println("privageKeyPath", privageKeyPath)
-
-			privageKeyBytes, err := os.ReadFile(privageKeyPath)
-			switch {
-			case errors.Is(err, os.ErrNotExist):
-				privateKey, err := crypto.GenerateKey()
-				if err != nil {
-					return fmt.Errorf("failed to generate private key: %w", err)
-				}
-				privateKeyBytes := crypto.FromECDSA(privateKey)
-				err = os.WriteFile(privageKeyPath, privateKeyBytes, 0600)
-				if err != nil {
-					return fmt.Errorf("failed to write private key: %w", err)
-				}
-
-				fmt.Println("Private key generated and saved to", privageKeyPath)
-				fmt.Println("Address:", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
-
-			case err != nil:
-				return fmt.Errorf("failed to read private key: %w", err)
-			default:
-				privateKey, err := crypto.ToECDSA(privageKeyBytes)
-				if err != nil {
-					return fmt.Errorf("failed to deserialize private key: %w", err)
-				}
-				fmt.Println("Private key already exists")
-				fmt.Println("Address:", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+			account, err := useraccount.OpenKeystore(c).NewAccount(passphrase)
+			if err != nil {
+				return fmt.Errorf("failed to create account: %w", err)
 			}
 
-			return nil
+			fmt.Println("Account created in", account.URL.Path)
+			fmt.Println("Address:", account.Address.Hex())
 
+			return nil
 		},
 	}
 }
+
+func createDevKey() error {
+	privageKeyPath, err := xdg.ConfigFile(useraccount.PrivateKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to get config file path: %w", err)
+	}
+
+	fmt.Println("privageKeyPath", privageKeyPath)
+
+	privageKeyBytes, err := os.ReadFile(privageKeyPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		privateKey, err := crypto.GenerateKey()
+		if err != nil {
+			return fmt.Errorf("failed to generate private key: %w", err)
+		}
+		privateKeyBytes := crypto.FromECDSA(privateKey)
+		err = os.WriteFile(privageKeyPath, privateKeyBytes, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to write private key: %w", err)
+		}
+
+		fmt.Println("Private key generated and saved to", privageKeyPath)
+		fmt.Println("Address:", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+
+	case err != nil:
+		return fmt.Errorf("failed to read private key: %w", err)
+	default:
+		privateKey, err := crypto.ToECDSA(privageKeyBytes)
+		if err != nil {
+			return fmt.Errorf("failed to deserialize private key: %w", err)
+		}
+		fmt.Println("Private key already exists")
+		fmt.Println("Address:", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	}
+
+	return nil
+}
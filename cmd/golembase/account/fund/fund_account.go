@@ -41,7 +41,7 @@ func FundAccount() *cli.Command {
 		Name:  "fund",
 		Usage: "Fund an account",
 		Action: func(c *cli.Context) error {
-			userAccount, err := useraccount.Load()
+			address, err := useraccount.LoadAddress(c)
 			if err != nil {
 				return fmt.Errorf("failed to load user account: %w", err)
 			}
@@ -85,7 +85,7 @@ func FundAccount() *cli.Command {
 				MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1e9)), // 1 Gwei
 				MaxFeePerGas:         (*hexutil.Big)(big.NewInt(5e9)), // 5 Gwei
 				Gas:                  (*hexutil.Uint64)(pointerOf(uint64(2_800_000))),
-				To:                   pointerOf(userAccount.Address), //
+				To:                   pointerOf(address), //
 				Value:                (*hexutil.Big)(EthToWei(cfg.value)),
 			}
 
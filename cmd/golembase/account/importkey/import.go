@@ -22,6 +22,10 @@ func ImportAccount() *cli.Command {
 				Usage:    "Private key in hex format",
 				Required: true,
 			},
+			&cli.BoolFlag{
+				Name:  "dev",
+				Usage: "save the key as the unencrypted development key instead of importing it into the keystore (development only)",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			hexKey := c.String("privatekey")
@@ -34,6 +38,25 @@ func ImportAccount() *cli.Command {
 				return fmt.Errorf("invalid private key: %w", err)
 			}
 
+			address := crypto.PubkeyToAddress(privateKeyBytes.PublicKey)
+
+			if !c.Bool("dev") {
+				passphrase, err := useraccount.Passphrase(c, "Passphrase for the imported account: ", true)
+				if err != nil {
+					return err
+				}
+
+				account, err := useraccount.OpenKeystore(c).ImportECDSA(privateKeyBytes, passphrase)
+				if err != nil {
+					return fmt.Errorf("failed to import account: %w", err)
+				}
+
+				fmt.Println("Successfully imported account into", account.URL.Path)
+				fmt.Println("Address:", address.Hex())
+
+				return nil
+			}
+
 			// Get path to store the private key
 			privateKeyPath, err := xdg.ConfigFile(useraccount.PrivateKeyPath)
 			if err != nil {
@@ -51,7 +74,6 @@ func ImportAccount() *cli.Command {
 				return fmt.Errorf("failed to write private key: %w", err)
 			}
 
-			address := crypto.PubkeyToAddress(privateKeyBytes.PublicKey)
 			fmt.Println("Successfully imported account")
 			fmt.Println("Address:", address.Hex())
 
@@ -1,3 +1,7 @@
 package useraccount
 
+// PrivateKeyPath is the path of the unencrypted development key, relative to the XDG config directory.
 const PrivateKeyPath = "golembase/private.key"
+
+// KeystorePath is the path of the default keystore directory, relative to the XDG data directory.
+const KeystorePath = "golembase/keystore"
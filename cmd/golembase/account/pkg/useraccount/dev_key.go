@@ -0,0 +1,38 @@
+package useraccount
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/adrg/xdg"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+)
+
+// loadDevKey loads the unencrypted development key.
+func loadDevKey() (*UserAccount, error) {
+	privageKeyPath, err := xdg.ConfigFile(PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config file path: %w", err)
+	}
+
+	privageKeyBytes, err := os.ReadFile(privageKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	privateKey, err := crypto.ToECDSA(privageKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize private key: %w", err)
+	}
+
+	fmt.Fprintln(os.Stderr, "WARNING: using the unencrypted development key", privageKeyPath)
+
+	return &UserAccount{
+		Address: crypto.PubkeyToAddress(privateKey.PublicKey),
+		signTx: func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+			return types.SignTx(tx, types.LatestSignerForChainID(chainID), privateKey)
+		},
+	}, nil
+}
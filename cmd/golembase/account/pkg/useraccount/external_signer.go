@@ -0,0 +1,28 @@
+package useraccount
+
+import (
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/accounts/external"
+	"github.com/urfave/cli/v2"
+)
+
+// loadFromExternalSigner selects an account of the external signer.
+// Every transaction is sent to the signer (e.g. Clef) for approval and signing,
+// the key never leaves the signer.
+func loadFromExternalSigner(c *cli.Context) (*UserAccount, error) {
+	signer, err := external.NewExternalSigner(c.String(signerFlag))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer: %w", err)
+	}
+
+	account, err := selectAccount(signer.Accounts(), c.String(accountFlag))
+	if err != nil {
+		return nil, fmt.Errorf("external signer: %w", err)
+	}
+
+	return &UserAccount{
+		Address: account.Address,
+		signTx:  accountSigner(signer, account),
+	}, nil
+}
@@ -0,0 +1,44 @@
+package useraccount
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	keystoreFlag     = "keystore"
+	accountFlag      = "account"
+	passwordFileFlag = "password-file"
+	signerFlag       = "signer"
+	devKeyFlag       = "dev-key"
+)
+
+// Flags returns the global flags selecting the account used to sign transactions.
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    keystoreFlag,
+			Usage:   "directory of the passphrase-encrypted keystore (default: $XDG_DATA_HOME/" + KeystorePath + ")",
+			EnvVars: []string{"GOLEMBASE_KEYSTORE"},
+		},
+		&cli.StringFlag{
+			Name:    accountFlag,
+			Usage:   "address of the account to use (default: the first account of the keystore or the external signer)",
+			EnvVars: []string{"GOLEMBASE_ACCOUNT"},
+		},
+		&cli.StringFlag{
+			Name:    passwordFileFlag,
+			Usage:   "file containing the keystore passphrase, the passphrase is prompted for if not given",
+			EnvVars: []string{"GOLEMBASE_PASSWORD_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    signerFlag,
+			Usage:   "URL of an external signer (Clef) to sign transactions with instead of the keystore",
+			EnvVars: []string{"GOLEMBASE_SIGNER"},
+		},
+		&cli.BoolFlag{
+			Name:    devKeyFlag,
+			Usage:   "use the unencrypted development key at $XDG_CONFIG_HOME/" + PrivateKeyPath + " (development only)",
+			EnvVars: []string{"GOLEMBASE_DEV_KEY"},
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package useraccount
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/jeffcogswell/golembase-op-geth/accounts"
+	"github.com/jeffcogswell/golembase-op-geth/accounts/keystore"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/console/prompt"
+	"github.com/urfave/cli/v2"
+)
+
+// OpenKeystore opens the keystore selected by the --keystore flag.
+func OpenKeystore(c *cli.Context) *keystore.KeyStore {
+	dir := c.String(keystoreFlag)
+	if dir == "" {
+		dir = filepath.Join(xdg.DataHome, KeystorePath)
+	}
+	return keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// Passphrase reads the keystore passphrase from the --password-file flag or prompts for it.
+// When confirm is set a prompted passphrase has to be entered twice.
+func Passphrase(c *cli.Context, prompt string, confirm bool) (string, error) {
+	if path := c.String(passwordFileFlag); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	passphrase, err := promptPassword(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	if confirm {
+		again, err := promptPassword("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+func promptPassword(p string) (string, error) {
+	passphrase, err := prompt.Stdin.PromptPassword(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
+
+// loadFromKeystore unlocks the selected account of the keystore.
+func loadFromKeystore(c *cli.Context) (*UserAccount, error) {
+	ks := OpenKeystore(c)
+
+	account, err := selectAccount(ks.Accounts(), c.String(accountFlag))
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+
+	passphrase, err := Passphrase(c, fmt.Sprintf("Passphrase for %s: ", account.Address.Hex()), false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ks.Unlock(account, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock account %s: %w", account.Address.Hex(), err)
+	}
+
+	return &UserAccount{
+		Address: account.Address,
+		signTx:  accountSigner(ks, account),
+	}, nil
+}
+
+// selectAccount returns the account with the given address, or the first account if address is empty.
+func selectAccount(all []accounts.Account, address string) (accounts.Account, error) {
+	if len(all) == 0 {
+		return accounts.Account{}, fmt.Errorf("no accounts found, create one with `golembase account create`")
+	}
+
+	if address == "" {
+		return all[0], nil
+	}
+
+	if !common.IsHexAddress(address) {
+		return accounts.Account{}, fmt.Errorf("invalid account address %q", address)
+	}
+
+	for _, a := range all {
+		if a.Address == common.HexToAddress(address) {
+			return a, nil
+		}
+	}
+
+	return accounts.Account{}, fmt.Errorf("account %s not found", address)
+}
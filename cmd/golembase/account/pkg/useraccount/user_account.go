@@ -1,39 +1,74 @@
 package useraccount
 
 import (
-	"crypto/ecdsa"
 	"fmt"
-	"os"
+	"math/big"
 
-	"github.com/adrg/xdg"
+	"github.com/jeffcogswell/golembase-op-geth/accounts"
 	"github.com/jeffcogswell/golembase-op-geth/common"
-	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/urfave/cli/v2"
 )
 
+// UserAccount is the account the CLI signs transactions with.
+// Depending on the global flags its key lives in the keystore, in an external signer,
+// or in the unencrypted development key file.
 type UserAccount struct {
-	Address    common.Address
-	PrivateKey *ecdsa.PrivateKey
+	Address common.Address
+	signTx  func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
 }
 
-func Load() (*UserAccount, error) {
-	privageKeyPath, err := xdg.ConfigFile(PrivateKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get config file path: %w", err)
-	}
+// SignTx signs the transaction for the given chain.
+func (a *UserAccount) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return a.signTx(tx, chainID)
+}
 
-	privageKeyBytes, err := os.ReadFile(privageKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read private key: %w", err)
+// Load loads the account selected by the global flags:
+//   - with --signer the account is managed by the external signer,
+//   - with --dev-key the unencrypted development key is used,
+//   - otherwise the account is unlocked from the keystore.
+func Load(c *cli.Context) (*UserAccount, error) {
+	switch {
+	case c.String(signerFlag) != "" && c.Bool(devKeyFlag):
+		return nil, fmt.Errorf("--%s and --%s are mutually exclusive", signerFlag, devKeyFlag)
+	case c.String(signerFlag) != "":
+		return loadFromExternalSigner(c)
+	case c.Bool(devKeyFlag):
+		return loadDevKey()
+	default:
+		return loadFromKeystore(c)
 	}
+}
 
-	privateKey, err := crypto.ToECDSA(privageKeyBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to deserialize private key: %w", err)
+// LoadAddress returns the address of the account selected by the global flags without unlocking it.
+func LoadAddress(c *cli.Context) (common.Address, error) {
+	switch {
+	case c.String(signerFlag) != "" && c.Bool(devKeyFlag):
+		return common.Address{}, fmt.Errorf("--%s and --%s are mutually exclusive", signerFlag, devKeyFlag)
+	case c.String(signerFlag) != "" || c.Bool(devKeyFlag):
+		// the dev key is not encrypted and the external signer only lists accounts
+		account, err := Load(c)
+		if err != nil {
+			return common.Address{}, err
+		}
+		return account.Address, nil
+	default:
+		account, err := selectAccount(OpenKeystore(c).Accounts(), c.String(accountFlag))
+		if err != nil {
+			return common.Address{}, fmt.Errorf("keystore: %w", err)
+		}
+		return account.Address, nil
 	}
+}
 
-	return &UserAccount{
-		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
-		PrivateKey: privateKey,
-	}, nil
+// txSigner is implemented by the keystore and by the external signer.
+type txSigner interface {
+	SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
 
+// accountSigner returns a function signing transactions with the account of the signer.
+func accountSigner(signer txSigner, account accounts.Account) func(*types.Transaction, *big.Int) (*types.Transaction, error) {
+	return func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return signer.SignTx(account, tx, chainID)
+	}
 }
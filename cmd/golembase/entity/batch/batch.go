@@ -0,0 +1,85 @@
+package batch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account/pkg/useraccount"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/events"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/sendtx"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/pkg/output"
+	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/urfave/cli/v2"
+)
+
+func Batch() *cli.Command {
+	cfg := struct {
+		nodeURL string
+		output  string
+	}{}
+	return &cli.Command{
+		Name:      "batch",
+		Usage:     "Apply a file of create, update, delete and extend operations in a single transaction",
+		ArgsUsage: "<file.json|file.jsonl|->",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "node-url",
+				Usage:       "The URL of the node to connect to",
+				Value:       "http://localhost:8545",
+				EnvVars:     []string{"NODE_URL"},
+				Destination: &cfg.nodeURL,
+			},
+			output.Flag(&cfg.output),
+		},
+		Action: func(c *cli.Context) error {
+			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
+			defer cancel()
+
+			out, err := output.New(cfg.output, os.Stdout, events.Header...)
+			if err != nil {
+				return err
+			}
+
+			fileName := c.Args().First()
+			if fileName == "" {
+				return fmt.Errorf("batch file is required")
+			}
+
+			var r io.Reader = os.Stdin
+			if fileName != "-" {
+				f, err := os.Open(fileName)
+				if err != nil {
+					return fmt.Errorf("failed to open batch file: %w", err)
+				}
+				defer f.Close()
+				r = f
+			}
+
+			storageTx, err := readStorageTransaction(r)
+			if err != nil {
+				return err
+			}
+
+			userAccount, err := useraccount.Load(c)
+			if err != nil {
+				return fmt.Errorf("failed to load user account: %w", err)
+			}
+
+			// Connect to the geth node
+			client, err := ethclient.DialContext(ctx, cfg.nodeURL)
+			if err != nil {
+				return fmt.Errorf("failed to connect to node: %w", err)
+			}
+			defer client.Close()
+
+			receipt, err := sendtx.SendStorageTransaction(ctx, client, userAccount, storageTx)
+			if err != nil {
+				return err
+			}
+
+			return events.Write(out, events.FromLogs(receipt.Logs))
+		},
+	}
+}
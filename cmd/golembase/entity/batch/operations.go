@@ -0,0 +1,149 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+)
+
+// operation is a single operation of a batch file, exactly one of its fields must be set.
+type operation struct {
+	Create *createOperation     `json:"create,omitempty"`
+	Update *updateOperation     `json:"update,omitempty"`
+	Delete *common.Hash         `json:"delete,omitempty"`
+	Extend *storagetx.ExtendTTL `json:"extend,omitempty"`
+}
+
+// createOperation mirrors storagetx.Create with the payload given as a string.
+type createOperation struct {
+	TTL                uint64                     `json:"ttl"`
+	Payload            string                     `json:"payload"`
+	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
+	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations"`
+	Namespace          string                     `json:"namespace"`
+	Name               string                     `json:"name"`
+}
+
+// updateOperation mirrors storagetx.Update with the payload given as a string.
+type updateOperation struct {
+	EntityKey          common.Hash                `json:"entityKey"`
+	TTL                uint64                     `json:"ttl"`
+	Payload            string                     `json:"payload"`
+	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
+	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations"`
+}
+
+// readStorageTransaction reads the operations of a batch file into a single storage transaction.
+// The file is either a JSON array of operations or a sequence of JSON operations, one per line (JSONL).
+func readStorageTransaction(r io.Reader) (*storagetx.StorageTransaction, error) {
+	br := bufio.NewReader(r)
+
+	ops, err := readOperations(br)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("batch contains no operations")
+	}
+
+	storageTx := &storagetx.StorageTransaction{}
+
+	for i, op := range ops {
+		set := 0
+		if op.Create != nil {
+			set++
+			storageTx.Create = append(storageTx.Create, storagetx.Create{
+				TTL:                op.Create.TTL,
+				Payload:            []byte(op.Create.Payload),
+				StringAnnotations:  op.Create.StringAnnotations,
+				NumericAnnotations: op.Create.NumericAnnotations,
+				TagAnnotations:     op.Create.TagAnnotations,
+				Namespace:          op.Create.Namespace,
+				Name:               op.Create.Name,
+			})
+		}
+		if op.Update != nil {
+			set++
+			storageTx.Update = append(storageTx.Update, storagetx.Update{
+				EntityKey:          op.Update.EntityKey,
+				TTL:                op.Update.TTL,
+				Payload:            []byte(op.Update.Payload),
+				StringAnnotations:  op.Update.StringAnnotations,
+				NumericAnnotations: op.Update.NumericAnnotations,
+				TagAnnotations:     op.Update.TagAnnotations,
+			})
+		}
+		if op.Delete != nil {
+			set++
+			storageTx.Delete = append(storageTx.Delete, *op.Delete)
+		}
+		if op.Extend != nil {
+			set++
+			storageTx.Extend = append(storageTx.Extend, *op.Extend)
+		}
+
+		if set != 1 {
+			return nil, fmt.Errorf("operation %d: exactly one of create, update, delete or extend must be set", i)
+		}
+	}
+
+	return storageTx, nil
+}
+
+func readOperations(br *bufio.Reader) ([]operation, error) {
+	first, err := peekNonSpace(br)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch: %w", err)
+	}
+
+	dec := json.NewDecoder(br)
+	dec.DisallowUnknownFields()
+
+	if first == '[' {
+		ops := []operation{}
+		err = dec.Decode(&ops)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode batch: %w", err)
+		}
+		return ops, nil
+	}
+
+	ops := []operation{}
+	for {
+		op := operation{}
+		err = dec.Decode(&op)
+		if errors.Is(err, io.EOF) {
+			return ops, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode operation %d: %w", len(ops), err)
+		}
+		ops = append(ops, op)
+	}
+}
+
+// peekNonSpace returns the first non-whitespace byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsRune([]byte(" \t\r\n"), rune(b)) {
+			return b, br.UnreadByte()
+		}
+	}
+}
@@ -2,27 +2,30 @@ package create
 
 import (
 	"fmt"
-	"math/big"
 	"os"
 	"os/signal"
 
-	"github.com/jeffcogswell/golembase-op-geth/accounts/abi/bind"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account/pkg/useraccount"
-	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/annotations"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/events"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/sendtx"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/pkg/output"
 	"github.com/jeffcogswell/golembase-op-geth/ethclient"
-	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
-	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
-	"github.com/jeffcogswell/golembase-op-geth/rlp"
 	"github.com/urfave/cli/v2"
 )
 
 func Create() *cli.Command {
 
 	cfg := struct {
-		nodeURL string
-		data    string
-		ttl     uint64
+		nodeURL            string
+		data               string
+		ttl                uint64
+		namespace          string
+		name               string
+		stringAnnotations  cli.StringSlice
+		numericAnnotations cli.StringSlice
+		output             string
 	}{}
 	return &cli.Command{
 		Name:  "create",
@@ -49,107 +52,82 @@ func Create() *cli.Command {
 				EnvVars:     []string{"ENTITY_TTL"},
 				Destination: &cfg.ttl,
 			},
+			&cli.StringFlag{
+				Name:        "namespace",
+				Usage:       "namespace of the named entity",
+				EnvVars:     []string{"ENTITY_NAMESPACE"},
+				Destination: &cfg.namespace,
+			},
+			&cli.StringFlag{
+				Name:        "name",
+				Usage:       "name of the entity, the entity key is derived from the owner, namespace and name",
+				EnvVars:     []string{"ENTITY_NAME"},
+				Destination: &cfg.name,
+			},
+			&cli.StringSliceFlag{
+				Name:        "string-annotation",
+				Usage:       "string annotation of the entity as key=value, can be repeated",
+				Destination: &cfg.stringAnnotations,
+			},
+			&cli.StringSliceFlag{
+				Name:        "numeric-annotation",
+				Usage:       "numeric annotation of the entity as key=number, can be repeated",
+				Destination: &cfg.numericAnnotations,
+			},
+			output.Flag(&cfg.output),
 		},
 		Action: func(c *cli.Context) error {
 
 			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
 			defer cancel()
 
-			userAccount, err := useraccount.Load()
+			out, err := output.New(cfg.output, os.Stdout, events.Header...)
 			if err != nil {
-				return fmt.Errorf("failed to load user account: %w", err)
+				return err
 			}
 
-			// Connect to the geth node
-			client, err := ethclient.DialContext(ctx, cfg.nodeURL)
+			stringAnnotations, err := annotations.ParseStringAnnotations(cfg.stringAnnotations.Value())
 			if err != nil {
-				return fmt.Errorf("failed to connect to node: %w", err)
+				return err
 			}
-			defer client.Close()
 
-			// Get the chain ID
-			chainID, err := client.ChainID(ctx)
+			numericAnnotations, err := annotations.ParseNumericAnnotations(cfg.numericAnnotations.Value())
 			if err != nil {
-				return fmt.Errorf("failed to get chain ID: %w", err)
+				return err
+			}
+
+			userAccount, err := useraccount.Load(c)
+			if err != nil {
+				return fmt.Errorf("failed to load user account: %w", err)
 			}
 
-			// Get the nonce for the sender account
-			nonce, err := client.PendingNonceAt(ctx, userAccount.Address)
+			// Connect to the geth node
+			client, err := ethclient.DialContext(ctx, cfg.nodeURL)
 			if err != nil {
-				return fmt.Errorf("failed to get nonce: %w", err)
+				return fmt.Errorf("failed to connect to node: %w", err)
 			}
+			defer client.Close()
 
 			// Create the storage transaction
 			storageTx := &storagetx.StorageTransaction{
 				Create: []storagetx.Create{
 					{
-						TTL:     c.Uint64("ttl"),
-						Payload: []byte(c.String("data")),
-						StringAnnotations: []entity.StringAnnotation{
-							{
-								Key:   "foo",
-								Value: "bar",
-							},
-						},
+						TTL:                cfg.ttl,
+						Payload:            []byte(cfg.data),
+						StringAnnotations:  stringAnnotations,
+						NumericAnnotations: numericAnnotations,
+						Namespace:          cfg.namespace,
+						Name:               cfg.name,
 					},
 				},
 			}
 
-			// Encode the storage transaction
-			txData, err := rlp.EncodeToBytes(storageTx)
+			receipt, err := sendtx.SendStorageTransaction(ctx, client, userAccount, storageTx)
 			if err != nil {
-				return fmt.Errorf("failed to encode storage tx: %w", err)
+				return err
 			}
 
-			// Create the GolemBaseUpdateStorageTx
-			tx := &types.DynamicFeeTx{
-				ChainID:   chainID,
-				Nonce:     nonce,
-				Gas:       1_000_000,
-				Data:      txData,
-				To:        &address.GolemBaseStorageProcessorAddress,
-				GasTipCap: big.NewInt(1e9), // 1 Gwei
-				GasFeeCap: big.NewInt(5e9), // 5 Gwei
-			}
-
-			// Use the London signer since we're using a dynamic fee transaction
-			signer := types.LatestSignerForChainID(chainID)
-
-			// return nil, fmt.Errorf("signer: %#v", signer)
-
-			// Create and sign the transaction
-			signedTx, err := types.SignNewTx(userAccount.PrivateKey, signer, tx)
-			if err != nil {
-				return fmt.Errorf("failed to sign transaction: %w", err)
-			}
-
-			txHash := signedTx.Hash()
-
-			err = client.SendTransaction(ctx, signedTx)
-			if err != nil {
-				return fmt.Errorf("failed to send tx: %w", err)
-			}
-
-			receipt, err := bind.WaitMinedHash(ctx, client, txHash)
-			if err != nil {
-				return fmt.Errorf("failed to wait for tx: %w", err)
-			}
-
-			if receipt.Status != types.ReceiptStatusSuccessful {
-				return fmt.Errorf("tx failed")
-			}
-
-			for _, log := range receipt.Logs {
-				if log.Topics[0] == storagetx.GolemBaseStorageEntityCreated {
-					fmt.Println("Entity created", "key", log.Topics[1])
-				}
-			}
-
-			return nil
+			return events.Write(out, events.FromLogs(receipt.Logs))
 		},
 	}
 }
-
-func pointerOf[T any](v T) *T {
-	return &v
-}
@@ -2,18 +2,16 @@ package delete
 
 import (
 	"fmt"
-	"math/big"
 	"os"
 	"os/signal"
 
-	"github.com/jeffcogswell/golembase-op-geth/accounts/abi/bind"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account/pkg/useraccount"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/events"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/sendtx"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/pkg/output"
 	"github.com/jeffcogswell/golembase-op-geth/common"
-	"github.com/jeffcogswell/golembase-op-geth/core/types"
 	"github.com/jeffcogswell/golembase-op-geth/ethclient"
-	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
-	"github.com/jeffcogswell/golembase-op-geth/rlp"
 	"github.com/urfave/cli/v2"
 )
 
@@ -21,6 +19,7 @@ func Delete() *cli.Command {
 	cfg := struct {
 		nodeURL string
 		key     string
+		output  string
 	}{}
 	return &cli.Command{
 		Name:  "delete",
@@ -40,12 +39,18 @@ func Delete() *cli.Command {
 				EnvVars:     []string{"ENTITY_KEY"},
 				Destination: &cfg.key,
 			},
+			output.Flag(&cfg.output),
 		},
 		Action: func(c *cli.Context) error {
 			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
 			defer cancel()
 
-			userAccount, err := useraccount.Load()
+			out, err := output.New(cfg.output, os.Stdout, events.Header...)
+			if err != nil {
+				return err
+			}
+
+			userAccount, err := useraccount.Load(c)
 			if err != nil {
 				return fmt.Errorf("failed to load user account: %w", err)
 			}
@@ -57,74 +62,17 @@ func Delete() *cli.Command {
 			}
 			defer client.Close()
 
-			// Get the chain ID
-			chainID, err := client.ChainID(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to get chain ID: %w", err)
-			}
-
-			// Get the nonce for the sender account
-			nonce, err := client.PendingNonceAt(ctx, userAccount.Address)
-			if err != nil {
-				return fmt.Errorf("failed to get nonce: %w", err)
-			}
-
 			// Create the storage transaction
 			storageTx := &storagetx.StorageTransaction{
-				Delete: []common.Hash{
-					common.HexToHash(c.String("key")),
-				},
-			}
-
-			// Encode the storage transaction
-			txData, err := rlp.EncodeToBytes(storageTx)
-			if err != nil {
-				return fmt.Errorf("failed to encode storage tx: %w", err)
-			}
-
-			// Create the GolemBaseUpdateStorageTx
-			tx := &types.DynamicFeeTx{
-				ChainID:   chainID,
-				Nonce:     nonce,
-				Gas:       1_000_000,
-				Data:      txData,
-				To:        &address.GolemBaseStorageProcessorAddress,
-				GasTipCap: big.NewInt(1e9), // 1 Gwei
-				GasFeeCap: big.NewInt(5e9), // 5 Gwei
+				Delete: []common.Hash{common.HexToHash(cfg.key)},
 			}
 
-			// Use the London signer since we're using a dynamic fee transaction
-			signer := types.LatestSignerForChainID(chainID)
-
-			// Create and sign the transaction
-			signedTx, err := types.SignNewTx(userAccount.PrivateKey, signer, tx)
+			receipt, err := sendtx.SendStorageTransaction(ctx, client, userAccount, storageTx)
 			if err != nil {
-				return fmt.Errorf("failed to sign transaction: %w", err)
-			}
-
-			txHash := signedTx.Hash()
-
-			err = client.SendTransaction(ctx, signedTx)
-			if err != nil {
-				return fmt.Errorf("failed to send tx: %w", err)
-			}
-
-			receipt, err := bind.WaitMinedHash(ctx, client, txHash)
-			if err != nil {
-				return fmt.Errorf("failed to wait for tx: %w", err)
-			}
-
-			if receipt.Status != types.ReceiptStatusSuccessful {
-				return fmt.Errorf("tx failed")
-			}
-
-			for _, log := range receipt.Logs {
-				if log.Topics[0] == storagetx.GolemBaseStorageEntityDeleted {
-					fmt.Println("Entity deleted", "key", log.Topics[1])
-				}
+				return err
 			}
 
-			return nil
+			return events.Write(out, events.FromLogs(receipt.Logs))
 		},
 	}
 }
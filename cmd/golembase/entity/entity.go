@@ -1,9 +1,13 @@
 package entity
 
 import (
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/batch"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/create"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/delete"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/extend"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/keepalive"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/update"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/watch"
 	"github.com/urfave/cli/v2"
 )
 
@@ -15,6 +19,10 @@ func Entity() *cli.Command {
 			create.Create(),
 			delete.Delete(),
 			update.Update(),
+			extend.Extend(),
+			batch.Batch(),
+			watch.Watch(),
+			keepalive.KeepAlive(),
 		},
 	}
 }
@@ -0,0 +1,89 @@
+package extend
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account/pkg/useraccount"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/events"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/sendtx"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/pkg/output"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/urfave/cli/v2"
+)
+
+func Extend() *cli.Command {
+	cfg := struct {
+		nodeURL string
+		keys    cli.StringSlice
+		blocks  uint64
+		output  string
+	}{}
+	return &cli.Command{
+		Name:  "extend",
+		Usage: "Extend the TTL of existing entities",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "node-url",
+				Usage:       "The URL of the node to connect to",
+				Value:       "http://localhost:8545",
+				EnvVars:     []string{"NODE_URL"},
+				Destination: &cfg.nodeURL,
+			},
+			&cli.StringSliceFlag{
+				Name:        "key",
+				Usage:       "key of the entity to extend, can be repeated",
+				Required:    true,
+				EnvVars:     []string{"ENTITY_KEY"},
+				Destination: &cfg.keys,
+			},
+			&cli.Uint64Flag{
+				Name:        "blocks",
+				Usage:       "number of blocks to extend the TTL of the entities by",
+				Value:       100,
+				EnvVars:     []string{"ENTITY_EXTEND_BY"},
+				Destination: &cfg.blocks,
+			},
+			output.Flag(&cfg.output),
+		},
+		Action: func(c *cli.Context) error {
+			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
+			defer cancel()
+
+			out, err := output.New(cfg.output, os.Stdout, events.Header...)
+			if err != nil {
+				return err
+			}
+
+			userAccount, err := useraccount.Load(c)
+			if err != nil {
+				return fmt.Errorf("failed to load user account: %w", err)
+			}
+
+			// Connect to the geth node
+			client, err := ethclient.DialContext(ctx, cfg.nodeURL)
+			if err != nil {
+				return fmt.Errorf("failed to connect to node: %w", err)
+			}
+			defer client.Close()
+
+			storageTx := &storagetx.StorageTransaction{}
+			for _, key := range cfg.keys.Value() {
+				storageTx.Extend = append(storageTx.Extend, storagetx.ExtendTTL{
+					EntityKey:      common.HexToHash(key),
+					NumberOfBlocks: cfg.blocks,
+				})
+			}
+
+			receipt, err := sendtx.SendStorageTransaction(ctx, client, userAccount, storageTx)
+			if err != nil {
+				return err
+			}
+
+			return events.Write(out, events.FromLogs(receipt.Logs))
+		},
+	}
+}
@@ -0,0 +1,187 @@
+package keepalive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account/pkg/useraccount"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/sendtx"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtype"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+func KeepAlive() *cli.Command {
+	cfg := struct {
+		nodeURL   string
+		margin    uint64
+		extendBy  uint64
+		interval  time.Duration
+		batchSize uint64
+		keys      cli.StringSlice
+	}{}
+	return &cli.Command{
+		Name:  "keepalive",
+		Usage: "Keep owned entities alive by extending their TTL before they expire",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "node-url",
+				Usage:       "The URL of the node to connect to",
+				Value:       "http://localhost:8545",
+				EnvVars:     []string{"NODE_URL"},
+				Destination: &cfg.nodeURL,
+			},
+			&cli.Uint64Flag{
+				Name:        "margin",
+				Usage:       "extend entities expiring within this number of blocks",
+				Value:       100,
+				EnvVars:     []string{"KEEPALIVE_MARGIN"},
+				Destination: &cfg.margin,
+			},
+			&cli.Uint64Flag{
+				Name:        "extend-by",
+				Usage:       "number of blocks to extend the TTL of the entities by",
+				Value:       1000,
+				EnvVars:     []string{"KEEPALIVE_EXTEND_BY"},
+				Destination: &cfg.extendBy,
+			},
+			&cli.DurationFlag{
+				Name:        "interval",
+				Usage:       "how often to check for expiring entities",
+				Value:       10 * time.Second,
+				EnvVars:     []string{"KEEPALIVE_INTERVAL"},
+				Destination: &cfg.interval,
+			},
+			&cli.Uint64Flag{
+				Name:        "batch-size",
+				Usage:       "maximum number of entities extended in one transaction",
+				Value:       100,
+				EnvVars:     []string{"KEEPALIVE_BATCH_SIZE"},
+				Destination: &cfg.batchSize,
+			},
+			&cli.StringSliceFlag{
+				Name:        "key",
+				Usage:       "only keep the entities with these keys alive (default: all owned entities)",
+				EnvVars:     []string{"ENTITY_KEY"},
+				Destination: &cfg.keys,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
+			defer cancel()
+
+			if cfg.batchSize == 0 {
+				return fmt.Errorf("batch size must be positive")
+			}
+
+			if cfg.margin == 0 {
+				return fmt.Errorf("margin must be positive")
+			}
+
+			userAccount, err := useraccount.Load(c)
+			if err != nil {
+				return fmt.Errorf("failed to load user account: %w", err)
+			}
+
+			rpcClient, err := rpc.DialContext(ctx, cfg.nodeURL)
+			if err != nil {
+				return fmt.Errorf("failed to connect to node: %w", err)
+			}
+			defer rpcClient.Close()
+
+			client := ethclient.NewClient(rpcClient)
+
+			keys := []common.Hash{}
+			for _, key := range cfg.keys.Value() {
+				keys = append(keys, common.HexToHash(key))
+			}
+
+			ticker := time.NewTicker(cfg.interval)
+			defer ticker.Stop()
+
+			for {
+				expiring, err := expiringEntities(ctx, rpcClient, client, userAccount.Address, cfg.margin)
+				if err != nil {
+					return err
+				}
+
+				if len(keys) > 0 {
+					expiring = slices.DeleteFunc(expiring, func(key common.Hash) bool {
+						return !slices.Contains(keys, key)
+					})
+				}
+
+				for batch := range slices.Chunk(expiring, int(cfg.batchSize)) {
+					err = extend(ctx, client, userAccount, batch, cfg.extendBy)
+					if err != nil {
+						return err
+					}
+					fmt.Println("Extended", len(batch), "entities by", cfg.extendBy, "blocks")
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+}
+
+// expiringEntities returns the keys of the entities of owner that expire within margin blocks.
+func expiringEntities(ctx context.Context, rpcClient *rpc.Client, client *ethclient.Client, owner common.Address, margin uint64) ([]common.Hash, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	keys := []common.Hash{}
+
+	var cursor *golemtype.ExpiringEntitiesCursor
+	for {
+		page := &golemtype.ExpiringEntitiesPage{}
+		err = rpcClient.CallContext(
+			ctx,
+			page,
+			"golembase_getEntitiesExpiringBetween",
+			head+1,
+			head+margin,
+			owner,
+			cursor,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get expiring entities: %w", err)
+		}
+
+		for _, e := range page.Entities {
+			keys = append(keys, e.Key)
+		}
+
+		if page.Next == nil {
+			return keys, nil
+		}
+		cursor = page.Next
+	}
+}
+
+// extend submits a single transaction extending the TTL of all keys and waits for it to be mined.
+func extend(ctx context.Context, client *ethclient.Client, userAccount *useraccount.UserAccount, keys []common.Hash, numberOfBlocks uint64) error {
+	storageTx := &storagetx.StorageTransaction{}
+	for _, key := range keys {
+		storageTx.Extend = append(storageTx.Extend, storagetx.ExtendTTL{
+			EntityKey:      key,
+			NumberOfBlocks: numberOfBlocks,
+		})
+	}
+
+	_, err := sendtx.SendStorageTransaction(ctx, client, userAccount, storageTx)
+	return err
+}
@@ -0,0 +1,53 @@
+package annotations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+)
+
+// ParseStringAnnotations parses annotations given as key=value.
+func ParseStringAnnotations(values []string) ([]entity.StringAnnotation, error) {
+	annotations := []entity.StringAnnotation{}
+	for _, v := range values {
+		key, value, err := split(v)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, entity.StringAnnotation{
+			Key:   key,
+			Value: value,
+		})
+	}
+	return annotations, nil
+}
+
+// ParseNumericAnnotations parses annotations given as key=number.
+func ParseNumericAnnotations(values []string) ([]entity.NumericAnnotation, error) {
+	annotations := []entity.NumericAnnotation{}
+	for _, v := range values {
+		key, value, err := split(v)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid numeric annotation %q: %w", v, err)
+		}
+		annotations = append(annotations, entity.NumericAnnotation{
+			Key:   key,
+			Value: n,
+		})
+	}
+	return annotations, nil
+}
+
+func split(v string) (string, string, error) {
+	key, value, found := strings.Cut(v, "=")
+	if !found || key == "" {
+		return "", "", fmt.Errorf("invalid annotation %q, expected key=value", v)
+	}
+	return key, value, nil
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"strconv"
+
+	"github.com/holiman/uint256"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/pkg/output"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+)
+
+const (
+	Created  = "created"
+	Updated  = "updated"
+	Deleted  = "deleted"
+	Extended = "extended"
+)
+
+// Event is an entity event decoded from a log of the storage processor.
+// ExpiresAtBlock is zero for deleted entities.
+type Event struct {
+	Type           string      `json:"type"`
+	Key            common.Hash `json:"key"`
+	ExpiresAtBlock uint64      `json:"expiresAtBlock,omitempty"`
+	BlockNumber    uint64      `json:"blockNumber"`
+	TxHash         common.Hash `json:"txHash"`
+}
+
+// Header is the table header matching Row.
+var Header = []string{"TYPE", "KEY", "EXPIRES AT BLOCK", "BLOCK", "TX"}
+
+// FromLog decodes an entity event, it returns false if the log is not an entity event.
+func FromLog(log *types.Log) (Event, bool) {
+	if log.Address != address.GolemBaseStorageProcessorAddress || len(log.Topics) < 2 {
+		return Event{}, false
+	}
+
+	e := Event{
+		Key:         log.Topics[1],
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+	}
+
+	switch log.Topics[0] {
+	case storagetx.GolemBaseStorageEntityCreated:
+		e.Type = Created
+	case storagetx.GolemBaseStorageEntityUpdated:
+		e.Type = Updated
+	case storagetx.GolemBaseStorageEntityDeleted:
+		e.Type = Deleted
+	case storagetx.GolemBaseStorageEntityTTLExtended:
+		e.Type = Extended
+	default:
+		return Event{}, false
+	}
+
+	// created and updated logs carry the expiration block,
+	// extended logs carry the old and the new expiration block
+	if len(log.Data) >= 32 {
+		data := log.Data[len(log.Data)-32:]
+		e.ExpiresAtBlock = new(uint256.Int).SetBytes32(data).Uint64()
+	}
+
+	return e, true
+}
+
+// FromLogs decodes all entity events of logs.
+func FromLogs(logs []*types.Log) []Event {
+	events := []Event{}
+	for _, log := range logs {
+		e, ok := FromLog(log)
+		if ok {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// Row returns the table row of the event.
+func (e Event) Row() []string {
+	expiresAt := ""
+	if e.Type != Deleted {
+		expiresAt = strconv.FormatUint(e.ExpiresAtBlock, 10)
+	}
+	return []string{
+		e.Type,
+		e.Key.Hex(),
+		expiresAt,
+		strconv.FormatUint(e.BlockNumber, 10),
+		e.TxHash.Hex(),
+	}
+}
+
+// Write writes all events and flushes the writer.
+func Write(w *output.Writer, events []Event) error {
+	for _, e := range events {
+		err := w.Write(e, e.Row()...)
+		if err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
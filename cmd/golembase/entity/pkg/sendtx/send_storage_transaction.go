@@ -0,0 +1,76 @@
+package sendtx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/jeffcogswell/golembase-op-geth"
+	"github.com/jeffcogswell/golembase-op-geth/accounts/abi/bind"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account/pkg/useraccount"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+// SendStorageTransaction signs the storage transaction with the user account, submits it to the
+// storage processor and waits for it to be mined. It fails if the transaction was reverted.
+func SendStorageTransaction(ctx context.Context, client *ethclient.Client, userAccount *useraccount.UserAccount, storageTx *storagetx.StorageTransaction) (*types.Receipt, error) {
+	txData, err := rlp.EncodeToBytes(storageTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode storage tx: %w", err)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	nonce, err := client.PendingNonceAt(ctx, userAccount.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	gas, err := client.EstimateGas(ctx, ethereum.CallMsg{
+		From: userAccount.Address,
+		To:   &address.GolemBaseStorageProcessorAddress,
+		Data: txData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	// Use a dynamic fee transaction
+	tx := &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		Gas:       gas,
+		Data:      txData,
+		To:        &address.GolemBaseStorageProcessorAddress,
+		GasTipCap: big.NewInt(1e9), // 1 Gwei
+		GasFeeCap: big.NewInt(5e9), // 5 Gwei
+	}
+
+	signedTx, err := userAccount.SignTx(types.NewTx(tx), chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	err = client.SendTransaction(ctx, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	receipt, err := bind.WaitMinedHash(ctx, client, signedTx.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for tx: %w", err)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("tx %s failed", signedTx.Hash())
+	}
+
+	return receipt, nil
+}
@@ -2,28 +2,29 @@ package update
 
 import (
 	"fmt"
-	"math/big"
 	"os"
 	"os/signal"
 
-	"github.com/jeffcogswell/golembase-op-geth/accounts/abi/bind"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account/pkg/useraccount"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/annotations"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/events"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/sendtx"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/pkg/output"
 	"github.com/jeffcogswell/golembase-op-geth/common"
-	"github.com/jeffcogswell/golembase-op-geth/core/types"
 	"github.com/jeffcogswell/golembase-op-geth/ethclient"
-	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
-	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
-	"github.com/jeffcogswell/golembase-op-geth/rlp"
 	"github.com/urfave/cli/v2"
 )
 
 func Update() *cli.Command {
 	cfg := struct {
-		nodeURL string
-		data    string
-		key     string
-		ttl     uint64
+		nodeURL            string
+		data               string
+		key                string
+		ttl                uint64
+		stringAnnotations  cli.StringSlice
+		numericAnnotations cli.StringSlice
+		output             string
 	}{}
 	return &cli.Command{
 		Name:  "update",
@@ -57,101 +58,68 @@ func Update() *cli.Command {
 				EnvVars:     []string{"ENTITY_TTL"},
 				Destination: &cfg.ttl,
 			},
+			&cli.StringSliceFlag{
+				Name:        "string-annotation",
+				Usage:       "new string annotation of the entity as key=value, can be repeated",
+				Destination: &cfg.stringAnnotations,
+			},
+			&cli.StringSliceFlag{
+				Name:        "numeric-annotation",
+				Usage:       "new numeric annotation of the entity as key=number, can be repeated",
+				Destination: &cfg.numericAnnotations,
+			},
+			output.Flag(&cfg.output),
 		},
 		Action: func(c *cli.Context) error {
 			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
 			defer cancel()
 
-			userAccount, err := useraccount.Load()
+			out, err := output.New(cfg.output, os.Stdout, events.Header...)
 			if err != nil {
-				return fmt.Errorf("failed to load user account: %w", err)
+				return err
 			}
 
-			// Connect to the geth node
-			client, err := ethclient.DialContext(ctx, cfg.nodeURL)
+			stringAnnotations, err := annotations.ParseStringAnnotations(cfg.stringAnnotations.Value())
 			if err != nil {
-				return fmt.Errorf("failed to connect to node: %w", err)
+				return err
+			}
+
+			numericAnnotations, err := annotations.ParseNumericAnnotations(cfg.numericAnnotations.Value())
+			if err != nil {
+				return err
 			}
-			defer client.Close()
 
-			// Get the chain ID
-			chainID, err := client.ChainID(ctx)
+			userAccount, err := useraccount.Load(c)
 			if err != nil {
-				return fmt.Errorf("failed to get chain ID: %w", err)
+				return fmt.Errorf("failed to load user account: %w", err)
 			}
 
-			// Get the nonce for the sender account
-			nonce, err := client.PendingNonceAt(ctx, userAccount.Address)
+			// Connect to the geth node
+			client, err := ethclient.DialContext(ctx, cfg.nodeURL)
 			if err != nil {
-				return fmt.Errorf("failed to get nonce: %w", err)
+				return fmt.Errorf("failed to connect to node: %w", err)
 			}
+			defer client.Close()
 
 			// Create the storage transaction
 			storageTx := &storagetx.StorageTransaction{
 				Update: []storagetx.Update{
 					{
-						EntityKey: common.HexToHash(c.String("key")),
-						TTL:       c.Uint64("ttl"),
-						Payload:   []byte(c.String("data")),
-						StringAnnotations: []entity.StringAnnotation{
-							{
-								Key:   "foo",
-								Value: "bar",
-							},
-						},
+						EntityKey:          common.HexToHash(cfg.key),
+						TTL:                cfg.ttl,
+						Payload:            []byte(cfg.data),
+						StringAnnotations:  stringAnnotations,
+						NumericAnnotations: numericAnnotations,
 					},
 				},
 			}
 
-			// Encode the storage transaction
-			txData, err := rlp.EncodeToBytes(storageTx)
-			if err != nil {
-				return fmt.Errorf("failed to encode storage tx: %w", err)
-			}
-
-			// Create the GolemBaseUpdateStorageTx
-			tx := &types.DynamicFeeTx{
-				ChainID:   chainID,
-				Nonce:     nonce,
-				Gas:       1_000_000,
-				Data:      txData,
-				To:        &address.GolemBaseStorageProcessorAddress,
-				GasTipCap: big.NewInt(1e9), // 1 Gwei
-				GasFeeCap: big.NewInt(5e9), // 5 Gwei
-			}
-
-			// Use the London signer since we're using a dynamic fee transaction
-			signer := types.LatestSignerForChainID(chainID)
-
-			// Create and sign the transaction
-			signedTx, err := types.SignNewTx(userAccount.PrivateKey, signer, tx)
-			if err != nil {
-				return fmt.Errorf("failed to sign transaction: %w", err)
-			}
-
-			txHash := signedTx.Hash()
-
-			err = client.SendTransaction(ctx, signedTx)
-			if err != nil {
-				return fmt.Errorf("failed to send tx: %w", err)
-			}
-
-			receipt, err := bind.WaitMinedHash(ctx, client, txHash)
+			receipt, err := sendtx.SendStorageTransaction(ctx, client, userAccount, storageTx)
 			if err != nil {
-				return fmt.Errorf("failed to wait for tx: %w", err)
-			}
-
-			if receipt.Status != types.ReceiptStatusSuccessful {
-				return fmt.Errorf("tx failed")
-			}
-
-			for _, log := range receipt.Logs {
-				if log.Topics[0] == storagetx.GolemBaseStorageEntityUpdated {
-					fmt.Println("Entity updated", "key", log.Topics[1])
-				}
+				return err
 			}
 
-			return nil
+			return events.Write(out, events.FromLogs(receipt.Logs))
 		},
 	}
 }
@@ -0,0 +1,218 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity/pkg/events"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/pkg/output"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+// maxBlocksPerQuery bounds the block range of a single eth_getLogs call when catching up.
+const maxBlocksPerQuery = 1000
+
+func Watch() *cli.Command {
+	cfg := struct {
+		nodeURL   string
+		fromBlock uint64
+		interval  time.Duration
+		keys      cli.StringSlice
+		output    string
+	}{}
+	return &cli.Command{
+		Name:  "watch",
+		Usage: "Stream entity events (created, updated, deleted, extended)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "node-url",
+				Usage:       "The URL of the node to connect to, new blocks are pushed over ws:// and polled over http://",
+				Value:       "http://localhost:8545",
+				EnvVars:     []string{"NODE_URL"},
+				Destination: &cfg.nodeURL,
+			},
+			&cli.Uint64Flag{
+				Name:        "from-block",
+				Usage:       "first block to stream events from (default: the next block)",
+				Destination: &cfg.fromBlock,
+			},
+			&cli.DurationFlag{
+				Name:        "interval",
+				Usage:       "how often to poll for new blocks when the node does not support subscriptions",
+				Value:       2 * time.Second,
+				Destination: &cfg.interval,
+			},
+			&cli.StringSliceFlag{
+				Name:        "key",
+				Usage:       "only stream the events of the entities with these keys (default: all entities)",
+				EnvVars:     []string{"ENTITY_KEY"},
+				Destination: &cfg.keys,
+			},
+			output.Flag(&cfg.output),
+		},
+		Action: func(c *cli.Context) error {
+			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
+			defer cancel()
+
+			out, err := output.New(cfg.output, os.Stdout, events.Header...)
+			if err != nil {
+				return err
+			}
+
+			client, err := ethclient.DialContext(ctx, cfg.nodeURL)
+			if err != nil {
+				return fmt.Errorf("failed to connect to node: %w", err)
+			}
+			defer client.Close()
+
+			query := ethereum.FilterQuery{
+				Addresses: []common.Address{address.GolemBaseStorageProcessorAddress},
+				Topics: [][]common.Hash{
+					{
+						storagetx.GolemBaseStorageEntityCreated,
+						storagetx.GolemBaseStorageEntityUpdated,
+						storagetx.GolemBaseStorageEntityDeleted,
+						storagetx.GolemBaseStorageEntityTTLExtended,
+					},
+				},
+			}
+
+			if len(cfg.keys.Value()) > 0 {
+				keys := []common.Hash{}
+				for _, key := range cfg.keys.Value() {
+					keys = append(keys, common.HexToHash(key))
+				}
+				query.Topics = append(query.Topics, keys)
+			}
+
+			next := cfg.fromBlock
+			if !c.IsSet("from-block") {
+				head, err := client.BlockNumber(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to get block number: %w", err)
+				}
+				next = head + 1
+			}
+
+			newBlocks, err := newBlockNotifications(ctx, client, cfg.interval)
+			if err != nil {
+				return err
+			}
+
+			for {
+				head, err := client.BlockNumber(ctx)
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return fmt.Errorf("failed to get block number: %w", err)
+				}
+
+				for next <= head {
+					to := min(head, next+maxBlocksPerQuery-1)
+
+					query.FromBlock = new(big.Int).SetUint64(next)
+					query.ToBlock = new(big.Int).SetUint64(to)
+
+					logs, err := client.FilterLogs(ctx, query)
+					if err != nil {
+						if ctx.Err() != nil {
+							return nil
+						}
+						return fmt.Errorf("failed to get logs: %w", err)
+					}
+
+					es := []events.Event{}
+					for _, log := range logs {
+						e, ok := events.FromLog(&log)
+						if ok {
+							es = append(es, e)
+						}
+					}
+
+					err = events.Write(out, es)
+					if err != nil {
+						return err
+					}
+
+					next = to + 1
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case _, ok := <-newBlocks:
+					if !ok {
+						return fmt.Errorf("new block subscription closed")
+					}
+				}
+			}
+		},
+	}
+}
+
+// newBlockNotifications returns a channel that receives a value whenever there may be a new block.
+// It uses a new head subscription if the node supports it and a ticker otherwise.
+func newBlockNotifications(ctx context.Context, client *ethclient.Client, interval time.Duration) (<-chan struct{}, error) {
+	notifications := make(chan struct{}, 1)
+
+	notify := func() {
+		select {
+		case notifications <- struct{}{}:
+		default:
+		}
+	}
+
+	heads := make(chan *types.Header)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	switch {
+	case errors.Is(err, rpc.ErrNotificationsUnsupported):
+		// poll below
+	case err != nil:
+		return nil, fmt.Errorf("failed to subscribe to new heads: %w", err)
+	default:
+		go func() {
+			defer sub.Unsubscribe()
+			defer close(notifications)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-sub.Err():
+					return
+				case <-heads:
+					notify()
+				}
+			}
+		}()
+
+		return notifications, nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				notify()
+			}
+		}
+	}()
+
+	return notifications, nil
+}
@@ -5,10 +5,12 @@ import (
 	"os"
 
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/account/pkg/useraccount"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/blocks"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/cat"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/entity"
 	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/query"
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/token"
 	"github.com/urfave/cli/v2"
 )
 
@@ -17,6 +19,7 @@ func main() {
 	app := &cli.App{
 		Name:  "golembase CLI",
 		Usage: "Golem Base",
+		Flags: useraccount.Flags(),
 
 		Commands: []*cli.Command{
 			account.Account(),
@@ -25,6 +28,7 @@ func main() {
 			blocks.Blocks(),
 			cat.Cat(),
 			query.Query(),
+			token.Token(),
 		},
 	}
 
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// FormatTable prints results as an aligned table with a header line.
+	FormatTable = "table"
+	// FormatJSON prints every result as a JSON object on its own line.
+	FormatJSON = "json"
+)
+
+// Flag returns the --output flag selecting the output format of a command.
+func Flag(destination *string) *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:        "output",
+		Aliases:     []string{"o"},
+		Usage:       "output format, one of: table, json",
+		Value:       FormatTable,
+		EnvVars:     []string{"OUTPUT_FORMAT"},
+		Destination: destination,
+	}
+}
+
+// Writer writes results in the selected format.
+// The table header is written before the first row.
+type Writer struct {
+	format        string
+	out           io.Writer
+	table         *tabwriter.Writer
+	header        []string
+	headerWritten bool
+}
+
+// New creates a Writer for the given format.
+func New(format string, out io.Writer, header ...string) (*Writer, error) {
+	switch format {
+	case FormatTable, FormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+
+	return &Writer{
+		format: format,
+		out:    out,
+		table:  tabwriter.NewWriter(out, 0, 0, 2, ' ', 0),
+		header: header,
+	}, nil
+}
+
+// Write writes one result: v is encoded in the json format, row in the table format.
+func (w *Writer) Write(v any, row ...string) error {
+	if w.format == FormatJSON {
+		return json.NewEncoder(w.out).Encode(v)
+	}
+
+	if !w.headerWritten {
+		_, err := fmt.Fprintln(w.table, strings.Join(w.header, "\t"))
+		if err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	_, err := fmt.Fprintln(w.table, strings.Join(row, "\t"))
+	return err
+}
+
+// Flush writes out the buffered table rows.
+// Streaming commands call it after every batch of results.
+func (w *Writer) Flush() error {
+	return w.table.Flush()
+}
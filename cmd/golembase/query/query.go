@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 
+	"github.com/jeffcogswell/golembase-op-geth/cmd/golembase/pkg/output"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtype"
 	"github.com/jeffcogswell/golembase-op-geth/rpc"
 	"github.com/urfave/cli/v2"
@@ -13,6 +14,7 @@ import (
 func Query() *cli.Command {
 	cfg := struct {
 		nodeURL string
+		output  string
 	}{}
 	return &cli.Command{
 		Name:  "query",
@@ -25,12 +27,18 @@ func Query() *cli.Command {
 				EnvVars:     []string{"NODE_URL"},
 				Destination: &cfg.nodeURL,
 			},
+			output.Flag(&cfg.output),
 		},
 		Action: func(c *cli.Context) error {
 
 			ctx, stop := signal.NotifyContext(c.Context, os.Interrupt)
 			defer stop()
 
+			out, err := output.New(cfg.output, os.Stdout, "KEY", "PAYLOAD")
+			if err != nil {
+				return err
+			}
+
 			query := c.Args().First()
 			if query == "" {
 				return fmt.Errorf("query is required")
@@ -55,11 +63,13 @@ func Query() *cli.Command {
 			}
 
 			for _, r := range res {
-				fmt.Println(r.Key)
-				fmt.Println("  payload:", string(r.Value))
+				err = out.Write(r, r.Key.Hex(), string(r.Value))
+				if err != nil {
+					return err
+				}
 			}
 
-			return nil
+			return out.Flush()
 		},
 	}
 }
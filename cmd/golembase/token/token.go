@@ -0,0 +1,80 @@
+package token
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/node"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+func Token() *cli.Command {
+	cfg := struct {
+		jwtSecret  string
+		subject    string
+		namespaces cli.StringSlice
+		methods    cli.StringSlice
+		expiry     time.Duration
+	}{}
+	return &cli.Command{
+		Name:  "token",
+		Usage: "issue a JWT that is restricted to namespaces and methods",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "jwtsecret",
+				Usage:       "Path to the JWT secret of the node (--authrpc.jwtsecret)",
+				Required:    true,
+				Destination: &cfg.jwtSecret,
+			},
+			&cli.StringFlag{
+				Name:        "subject",
+				Usage:       "Subject of the token, identifies its bearer for rate limiting",
+				Destination: &cfg.subject,
+			},
+			&cli.StringSliceFlag{
+				Name:        "namespaces",
+				Usage:       "Namespaces the bearer may call, e.g. eth,golembase",
+				Destination: &cfg.namespaces,
+			},
+			&cli.StringSliceFlag{
+				Name:        "methods",
+				Usage:       "Single methods the bearer may call, e.g. engine_forkchoiceUpdatedV3",
+				Destination: &cfg.methods,
+			},
+			&cli.DurationFlag{
+				Name:        "expiry",
+				Usage:       "How long the token is valid",
+				Value:       30 * 24 * time.Hour,
+				Destination: &cfg.expiry,
+			},
+		},
+		Action: func(c *cli.Context) error {
+
+			data, err := os.ReadFile(cfg.jwtSecret)
+			if err != nil {
+				return fmt.Errorf("failed to read JWT secret: %w", err)
+			}
+			secret := common.FromHex(strings.TrimSpace(string(data)))
+			if len(secret) != 32 {
+				return fmt.Errorf("invalid JWT secret in %s", cfg.jwtSecret)
+			}
+
+			token, err := node.NewScopedJWTToken(secret, cfg.subject, rpc.Scope{
+				Namespaces: cfg.namespaces.Value(),
+				Methods:    cfg.methods.Value(),
+				Expiry:     time.Now().Add(cfg.expiry),
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(token)
+
+			return nil
+		},
+	}
+}
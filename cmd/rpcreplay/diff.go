@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// difference is a value that differs between the recorded and the replayed response.
+type difference struct {
+	path     string // e.g. "/transactions/0/hash", "" for the whole result
+	recorded string
+	replayed string
+}
+
+func (d difference) String() string {
+	p := d.path
+	if p == "" {
+		p = "/"
+	}
+	return fmt.Sprintf("%s: %s != %s", p, d.recorded, d.replayed)
+}
+
+// ignoreList are the fields whose values may differ between responses. A pattern without
+// a slash matches fields by name at any depth, e.g. "timestamp". A pattern with slashes
+// matches the path of a field, e.g. "/transactions/*/hash". Both may contain the
+// wildcards of path.Match.
+type ignoreList []string
+
+func (l ignoreList) ignores(p string) bool {
+	name := p[strings.LastIndex(p, "/")+1:]
+	for _, pattern := range l {
+		subject := p
+		if !strings.Contains(pattern, "/") {
+			subject = name
+		}
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// diffJSON returns the differences between two JSON values that are not ignored.
+func diffJSON(recorded, replayed json.RawMessage, ignore ignoreList) ([]difference, error) {
+	a, err := decodeJSON(recorded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recorded value: %w", err)
+	}
+	b, err := decodeJSON(replayed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replayed value: %w", err)
+	}
+	var diffs []difference
+	diffValues("", a, b, ignore, &diffs)
+	return diffs, nil
+}
+
+func decodeJSON(data json.RawMessage) (any, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func diffValues(p string, a, b any, ignore ignoreList, diffs *[]difference) {
+	if p != "" && ignore.ignores(p) {
+		return
+	}
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			keys := make(map[string]struct{})
+			for k := range a {
+				keys[k] = struct{}{}
+			}
+			for k := range b {
+				keys[k] = struct{}{}
+			}
+			sorted := make([]string, 0, len(keys))
+			for k := range keys {
+				sorted = append(sorted, k)
+			}
+			sort.Strings(sorted)
+			for _, k := range sorted {
+				diffValues(p+"/"+k, a[k], b[k], ignore, diffs)
+			}
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < max(len(a), len(b)); i++ {
+				var ai, bi any
+				if i < len(a) {
+					ai = a[i]
+				}
+				if i < len(b) {
+					bi = b[i]
+				}
+				diffValues(p+"/"+strconv.Itoa(i), ai, bi, ignore, diffs)
+			}
+			return
+		}
+	}
+	ja, jb := encodeJSON(a), encodeJSON(b)
+	if ja != jb {
+		*diffs = append(*diffs, difference{p, ja, jb})
+	}
+}
+
+func encodeJSON(v any) string {
+	if v == nil {
+		return "null"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
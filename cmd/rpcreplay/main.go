@@ -0,0 +1,78 @@
+// rpcreplay replays JSON-RPC calls recorded by a node (see --rpc.record) against
+// another node and reports the responses that differ from the recorded ones.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jeffcogswell/golembase-op-geth/internal/flags"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var app *cli.App
+
+var (
+	urlFlag = &cli.StringFlag{
+		Name:  "url",
+		Usage: "URL of the node to replay the calls against",
+		Value: "http://localhost:8545",
+	}
+	ignoreFlag = &cli.StringSliceFlag{
+		Name:  "ignore",
+		Usage: "fields whose values may differ, by name (e.g. 'timestamp') or by path (e.g. '/transactions/*/hash')",
+	}
+	skipFlag = &cli.StringSliceFlag{
+		Name:  "skip",
+		Usage: "methods not to replay, with wildcards (e.g. 'eth_call', 'debug_*')",
+	}
+	includeFlag = &cli.StringSliceFlag{
+		Name:  "include",
+		Usage: "methods to replay although they change the state or administer the node, with wildcards (e.g. 'eth_sendRawTransaction', 'admin_*')",
+	}
+)
+
+func init() {
+	app = flags.NewApp("JSON-RPC recording replayer")
+	app.ArgsUsage = "<recording.jsonl>..."
+	app.Flags = []cli.Flag{urlFlag, ignoreFlag, skipFlag, includeFlag}
+	app.Action = replayRecordings
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func replayRecordings(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return fmt.Errorf("no recording given, see --help")
+	}
+	client, err := rpc.DialContext(context.Background(), ctx.String(urlFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	r := &replayer{
+		client:  client,
+		ignore:  ignoreList(ctx.StringSlice(ignoreFlag.Name)),
+		skip:    ctx.StringSlice(skipFlag.Name),
+		include: ctx.StringSlice(includeFlag.Name),
+		out:     os.Stdout,
+	}
+	for _, file := range ctx.Args().Slice() {
+		if err := r.replayFile(context.Background(), file); err != nil {
+			return err
+		}
+	}
+	r.printSummary()
+	if r.differing > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+)
+
+// maxLineSize is the size of the longest recorded call that can be replayed.
+const maxLineSize = 64 * 1024 * 1024
+
+type recordedRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type recordedResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *recordedError  `json:"error"`
+}
+
+type recordedError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *recordedError) String() string {
+	if e == nil {
+		return "no error"
+	}
+	return fmt.Sprintf("error %d %q", e.Code, e.Message)
+}
+
+// defaultSkip are the methods that change the state of the node or of its chain, or
+// administer it. They are not replayed unless they are included explicitly, so that
+// replaying a recording does not resend transactions or reconfigure the node.
+var defaultSkip = []string{
+	"eth_sendRawTransaction",
+	"eth_sendTransaction",
+	"personal_*",
+	"admin_*",
+	"miner_*",
+}
+
+// replayer sends recorded calls to a node and compares the responses.
+type replayer struct {
+	client  *rpc.Client
+	ignore  ignoreList
+	skip    []string
+	include []string
+	out     io.Writer
+
+	replayed, differing, skipped int
+	recordedTime, replayTime     time.Duration
+}
+
+// replayFile replays the calls of a recording in the order in which they were recorded.
+func (r *replayer) replayFile(ctx context.Context, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		var call rpc.RecordedCall
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			return fmt.Errorf("%s:%d: invalid recorded call: %w", file, line, err)
+		}
+		if err := r.replay(ctx, fmt.Sprintf("%s:%d", file, line), &call); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (r *replayer) replay(ctx context.Context, location string, call *rpc.RecordedCall) error {
+	var (
+		req  recordedRequest
+		resp recordedResponse
+	)
+	if err := json.Unmarshal(call.Request, &req); err != nil {
+		return fmt.Errorf("%s: invalid recorded request: %w", location, err)
+	}
+	if err := json.Unmarshal(call.Response, &resp); err != nil {
+		return fmt.Errorf("%s: invalid recorded response: %w", location, err)
+	}
+	if r.skips(req.Method) {
+		r.skipped++
+		return nil
+	}
+	var params []json.RawMessage
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return fmt.Errorf("%s: invalid recorded params: %w", location, err)
+		}
+	}
+	args := make([]any, len(params))
+	for i, param := range params {
+		args[i] = param
+	}
+
+	var result json.RawMessage
+	start := time.Now()
+	err := r.client.CallContext(ctx, &result, req.Method, args...)
+	r.replayTime += time.Since(start)
+	r.recordedTime += call.Duration
+	r.replayed++
+
+	var replayedErr *recordedError
+	if err != nil {
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) {
+			return fmt.Errorf("%s: %s: %w", location, req.Method, err)
+		}
+		replayedErr = &recordedError{Code: rpcErr.ErrorCode(), Message: rpcErr.Error()}
+	}
+
+	var diffs []difference
+	switch {
+	case resp.Error != nil || replayedErr != nil:
+		if resp.Error == nil || replayedErr == nil || *resp.Error != *replayedErr {
+			diffs = []difference{{recorded: resp.Error.String(), replayed: replayedErr.String()}}
+		}
+	default:
+		if diffs, err = diffJSON(resp.Result, result, r.ignore); err != nil {
+			return fmt.Errorf("%s: %s: %w", location, req.Method, err)
+		}
+	}
+	if len(diffs) > 0 {
+		r.differing++
+		fmt.Fprintf(r.out, "%s: %s %s\n", location, req.Method, req.Params)
+		for _, d := range diffs {
+			fmt.Fprintf(r.out, "    %v\n", d)
+		}
+	}
+	return nil
+}
+
+// skips reports whether a method is not replayed. Subscriptions are never replayed,
+// their notifications are not recorded. The methods of defaultSkip are only replayed
+// if they are included, unless they are skipped as well.
+func (r *replayer) skips(method string) bool {
+	if strings.HasSuffix(method, "_subscribe") || strings.HasSuffix(method, "_unsubscribe") {
+		return true
+	}
+	if matchesAny(r.skip, method) {
+		return true
+	}
+	return matchesAny(defaultSkip, method) && !matchesAny(r.include, method)
+}
+
+// matchesAny reports whether the method matches one of the patterns.
+func matchesAny(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *replayer) printSummary() {
+	fmt.Fprintf(r.out, "replayed %d calls, %d differ, %d skipped\n", r.replayed, r.differing, r.skipped)
+	if r.replayed > 0 {
+		fmt.Fprintf(r.out, "recorded time %v, replay time %v\n", r.recordedTime, r.replayTime)
+	}
+}
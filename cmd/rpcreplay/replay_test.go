@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+)
+
+func TestDiffJSON(t *testing.T) {
+	t.Parallel()
+
+	recorded := `{"number":"0x1","timestamp":"0x10","txs":[{"hash":"0xa","to":"0xb"},{"hash":"0xc"}],"size":1}`
+	for _, test := range []struct {
+		replayed string
+		ignore   ignoreList
+		want     []string
+	}{
+		{replayed: recorded},
+		{
+			replayed: `{"number":"0x1","timestamp":"0x11","txs":[{"hash":"0xa","to":"0xb"},{"hash":"0xc"}],"size":1.0}`,
+			want:     []string{`/size: 1 != 1.0`, `/timestamp: "0x10" != "0x11"`},
+		},
+		{
+			replayed: `{"number":"0x1","timestamp":"0x11","txs":[{"hash":"0xd","to":"0xb"},{"hash":"0xe"}],"size":1}`,
+			ignore:   ignoreList{"timestamp", "/txs/*/hash"},
+		},
+		{
+			replayed: `{"number":"0x1","timestamp":"0x10","txs":[{"hash":"0xa"}],"extra":true,"size":1}`,
+			ignore:   ignoreList{"/txs/0/hash"},
+			want:     []string{`/extra: null != true`, `/txs/0/to: "0xb" != null`, `/txs/1: {"hash":"0xc"} != null`},
+		},
+		{
+			replayed: `null`,
+			ignore:   ignoreList{"txs"},
+			want:     []string{`/: {"number":"0x1","size":1,"timestamp":"0x10","txs":[{"hash":"0xa","to":"0xb"},{"hash":"0xc"}]} != null`},
+		},
+	} {
+		diffs, err := diffJSON([]byte(recorded), []byte(test.replayed), test.ignore)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range diffs {
+			got = append(got, d.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("replayed %s, ignoring %v:\ngot  %q\nwant %q", test.replayed, test.ignore, got, test.want)
+		}
+	}
+}
+
+type blockService struct {
+	// salt changes the hashes of the blocks from block 2 on
+	salt string
+}
+
+type block struct {
+	Number    int    `json:"number"`
+	Hash      string `json:"hash"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+func (s *blockService) GetBlock(number int) (*block, error) {
+	if number < 0 {
+		return nil, errors.New("negative block number")
+	}
+	hash := fmt.Sprintf("0x%02x", number)
+	if number >= 2 {
+		hash += s.salt
+	}
+	return &block{Number: number, Hash: hash, Timestamp: time.Now().UnixNano()}, nil
+}
+
+func newBlockServer(t *testing.T, salt string, recorder *rpc.Recorder) *rpc.Client {
+	t.Helper()
+
+	srv := rpc.NewServer()
+	t.Cleanup(srv.Stop)
+	if err := srv.RegisterName("test", &blockService{salt: salt}); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetRecorder(recorder)
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	client, err := rpc.Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "calls.jsonl")
+	recorder := rpc.NewRecorder(rpc.RecordConfig{Path: file})
+	recording := newBlockServer(t, "", recorder)
+	for _, number := range []int{1, 2, -1} {
+		recording.Call(nil, "test_getBlock", number)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		salt   string
+		ignore ignoreList
+		skip   []string
+		want   string
+	}{
+		{
+			ignore: ignoreList{"timestamp"},
+			want:   "replayed 3 calls, 0 differ, 0 skipped\n",
+		},
+		{
+			salt:   "ff",
+			ignore: ignoreList{"timestamp"},
+			want: file + `:2: test_getBlock [2]
+    /hash: "0x02" != "0x02ff"
+replayed 3 calls, 1 differ, 0 skipped
+`,
+		},
+		{
+			salt:   "ff",
+			ignore: ignoreList{"timestamp", "hash"},
+			skip:   []string{"test_*"},
+			want:   "replayed 0 calls, 0 differ, 3 skipped\n",
+		},
+	} {
+		out := new(strings.Builder)
+		r := &replayer{
+			client: newBlockServer(t, test.salt, nil),
+			ignore: test.ignore,
+			skip:   test.skip,
+			out:    out,
+		}
+		if err := r.replayFile(context.Background(), file); err != nil {
+			t.Fatal(err)
+		}
+		r.printSummary()
+		got := out.String()
+		if i := strings.Index(got, "recorded time"); i >= 0 {
+			got = got[:i]
+		}
+		if got != test.want {
+			t.Errorf("salt %q, ignoring %v, skipping %v:\ngot:\n%s\nwant:\n%s", test.salt, test.ignore, test.skip, got, test.want)
+		}
+	}
+}
+
+func TestSkips(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		skip, include []string
+		method        string
+		want          bool
+	}{
+		{method: "eth_getBlockByNumber", want: false},
+		{method: "eth_subscribe", want: true},
+		// state-changing and admin methods are skipped by default
+		{method: "eth_sendRawTransaction", want: true},
+		{method: "eth_sendTransaction", want: true},
+		{method: "personal_unlockAccount", want: true},
+		{method: "admin_addPeer", want: true},
+		{method: "miner_setExtra", want: true},
+		{include: []string{"eth_sendRawTransaction"}, method: "eth_sendRawTransaction", want: false},
+		{include: []string{"admin_*"}, method: "admin_addPeer", want: false},
+		{include: []string{"admin_*"}, method: "miner_setExtra", want: true},
+		{include: []string{"eth_subscribe"}, method: "eth_subscribe", want: true},
+		{skip: []string{"admin_*"}, include: []string{"admin_*"}, method: "admin_addPeer", want: true},
+		{skip: []string{"debug_*"}, method: "debug_traceTransaction", want: true},
+	} {
+		r := &replayer{skip: test.skip, include: test.include}
+		if got := r.skips(test.method); got != test.want {
+			t.Errorf("skipping %v, including %v: skips(%q) = %v, want %v", test.skip, test.include, test.method, got, test.want)
+		}
+	}
+}
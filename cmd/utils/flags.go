@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"math/big"
 	"net"
@@ -58,10 +59,12 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/ethdb"
 	"github.com/jeffcogswell/golembase-op-geth/ethdb/remotedb"
 	"github.com/jeffcogswell/golembase-op-geth/ethstats"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/history"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/wal"
 	"github.com/jeffcogswell/golembase-op-geth/graphql"
 	"github.com/jeffcogswell/golembase-op-geth/internal/ethapi"
 	"github.com/jeffcogswell/golembase-op-geth/internal/flags"
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry/exporter"
 	"github.com/jeffcogswell/golembase-op-geth/log"
 	"github.com/jeffcogswell/golembase-op-geth/metrics"
 	"github.com/jeffcogswell/golembase-op-geth/metrics/exp"
@@ -295,6 +298,11 @@ var (
 		Usage:    "Manually specify the Optimsim Interop feature-set fork timestamp, overriding the bundled setting",
 		Category: flags.EthCategory,
 	}
+	OverrideGolemBaseKaolin = &cli.Uint64Flag{
+		Name:     "override.golembase.kaolin",
+		Usage:    "Manually specify the Golem Base Kaolin upgrade timestamp, overriding the bundled setting",
+		Category: flags.EthCategory,
+	}
 	SyncModeFlag = &cli.StringFlag{
 		Name:     "syncmode",
 		Usage:    `Blockchain sync mode ("snap" or "full")`,
@@ -785,6 +793,54 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCRateLimitFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit",
+		Usage:    "Tokens per second of a client of the HTTP and WebSocket endpoints, spent by method calls (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitBurstFlag = &cli.IntFlag{
+		Name:     "rpc.ratelimit.burst",
+		Usage:    "Maximum number of tokens of a rate limited client",
+		Value:    node.DefaultConfig.RPCRateLimit.Burst,
+		Category: flags.APICategory,
+	}
+	RPCRateLimitCostsFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.costs",
+		Usage:    "Comma separated token costs of methods, overriding the defaults (e.g. 'eth_call=5,debug_trace*=50')",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitAPIKeyHeaderFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.apikeyheader",
+		Usage:    "HTTP header identifying rate limited clients by API key instead of IP address",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitAPIKeysFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.apikeys",
+		Usage:    "Comma separated API keys accepted in the rpc.ratelimit.apikeyheader, other keys are limited by IP address",
+		Category: flags.APICategory,
+	}
+	RPCJWTAuthFlag = &cli.BoolFlag{
+		Name:     "rpc.jwtauth",
+		Usage:    "Require a JWT signed with the authrpc.jwtsecret on the HTTP and WebSocket endpoints",
+		Category: flags.APICategory,
+	}
+	RPCRecordFlag = &cli.StringFlag{
+		Name:     "rpc.record",
+		Usage:    "File to record the calls of the HTTP and WebSocket endpoints and their responses to, as JSON lines",
+		Category: flags.APICategory,
+	}
+	RPCRecordMaxSizeFlag = &cli.IntFlag{
+		Name:     "rpc.record.maxsize",
+		Usage:    "Size in megabytes at which the recording is rotated",
+		Value:    100,
+		Category: flags.APICategory,
+	}
+	RPCRecordMaxFilesFlag = &cli.IntFlag{
+		Name:     "rpc.record.maxfiles",
+		Usage:    "Number of rotated recordings to keep (0 = all)",
+		Value:    10,
+		Category: flags.APICategory,
+	}
 
 	// Network Settings
 	MaxPeersFlag = &cli.IntFlag{
@@ -869,6 +925,23 @@ var (
 		Usage:    "Path to the write-ahead log directory for the Golem Base",
 		Category: flags.MiscCategory,
 	}
+	GolemBaseHistoryFlag = &cli.BoolFlag{
+		Name:     "golembase.history",
+		Usage:    "Maintain an index of entity revisions for the Golem Base history APIs",
+		Category: flags.MiscCategory,
+	}
+	GolemBaseQueryMaxSlotsFlag = &cli.Uint64Flag{
+		Name:     "golembase.query.maxslots",
+		Usage:    "Maximum number of storage slots a single Golem Base query can read (0 = no limit)",
+		Value:    node.DefaultConfig.GolemBaseQueryMaxSlots,
+		Category: flags.MiscCategory,
+	}
+	GolemBaseQueryTimeoutFlag = &cli.DurationFlag{
+		Name:     "golembase.query.timeout",
+		Usage:    "Maximum time a single Golem Base query can take (0 = no limit)",
+		Value:    node.DefaultConfig.GolemBaseQueryTimeout,
+		Category: flags.MiscCategory,
+	}
 
 	// Console
 	JSpathFlag = &flags.DirectoryFlag{
@@ -1076,6 +1149,24 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Value:    metrics.DefaultConfig.InfluxDBOrganization,
 		Category: flags.MetricsCategory,
 	}
+
+	TelemetryEndpointFlag = &cli.StringFlag{
+		Name:     "telemetry.endpoint",
+		Usage:    "OTLP/HTTP traces endpoint of an OpenTelemetry collector (e.g. http://localhost:4318/v1/traces), tracing is disabled if empty",
+		Category: flags.MetricsCategory,
+	}
+	TelemetrySampleRatioFlag = &cli.Float64Flag{
+		Name:     "telemetry.sampleratio",
+		Usage:    "Fraction of the traces that are sampled",
+		Value:    1,
+		Category: flags.MetricsCategory,
+	}
+	TelemetryServiceNameFlag = &cli.StringFlag{
+		Name:     "telemetry.servicename",
+		Usage:    "Service name of the exported spans",
+		Value:    "geth",
+		Category: flags.MetricsCategory,
+	}
 )
 
 var (
@@ -1311,6 +1402,43 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCRateLimit.Rate = ctx.Float64(RPCRateLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitBurstFlag.Name) {
+		cfg.RPCRateLimit.Burst = ctx.Int(RPCRateLimitBurstFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitCostsFlag.Name) {
+		// the default costs are shared, copy them before overriding
+		costs := maps.Clone(cfg.RPCRateLimit.MethodCosts)
+		if costs == nil {
+			costs = make(map[string]int)
+		}
+		for _, entry := range SplitAndTrim(ctx.String(RPCRateLimitCostsFlag.Name)) {
+			method, value, ok := strings.Cut(entry, "=")
+			cost, err := strconv.Atoi(value)
+			if !ok || err != nil || cost < 0 {
+				Fatalf("Invalid method cost %q in --%s", entry, RPCRateLimitCostsFlag.Name)
+			}
+			costs[method] = cost
+		}
+		cfg.RPCRateLimit.MethodCosts = costs
+	}
+	if ctx.IsSet(RPCRateLimitAPIKeyHeaderFlag.Name) {
+		cfg.RPCRateLimit.APIKeyHeader = ctx.String(RPCRateLimitAPIKeyHeaderFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitAPIKeysFlag.Name) {
+		cfg.RPCRateLimit.APIKeys = SplitAndTrim(ctx.String(RPCRateLimitAPIKeysFlag.Name))
+	}
+
+	if ctx.IsSet(RPCRecordFlag.Name) {
+		cfg.RPCRecord = rpc.RecordConfig{
+			Path:     ctx.String(RPCRecordFlag.Name),
+			MaxSize:  ctx.Int(RPCRecordMaxSizeFlag.Name),
+			MaxFiles: ctx.Int(RPCRecordMaxFilesFlag.Name),
+		}
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1490,6 +1618,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(JWTSecretFlag.Name) {
 		cfg.JWTSecret = ctx.String(JWTSecretFlag.Name)
 	}
+	if ctx.IsSet(RPCJWTAuthFlag.Name) {
+		cfg.RPCJWTAuth = ctx.Bool(RPCJWTAuthFlag.Name)
+	}
 	if ctx.IsSet(EnablePersonal.Name) {
 		log.Warn(fmt.Sprintf("Option --%s is deprecated. The 'personal' RPC namespace has been removed.", EnablePersonal.Name))
 	}
@@ -1539,6 +1670,18 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.GolemBaseWriteAheadLogDir = ctx.String(GolemBaseWriteAheadLogDir.Name)
 	}
 
+	if ctx.IsSet(GolemBaseHistoryFlag.Name) {
+		cfg.GolemBaseHistory = ctx.Bool(GolemBaseHistoryFlag.Name)
+	}
+
+	if ctx.IsSet(GolemBaseQueryMaxSlotsFlag.Name) {
+		cfg.GolemBaseQueryMaxSlots = ctx.Uint64(GolemBaseQueryMaxSlotsFlag.Name)
+	}
+
+	if ctx.IsSet(GolemBaseQueryTimeoutFlag.Name) {
+		cfg.GolemBaseQueryTimeout = ctx.Duration(GolemBaseQueryTimeoutFlag.Name)
+	}
+
 	// deprecation notice for log debug flags (TODO: find a more appropriate place to put these?)
 	if ctx.IsSet(LogBacktraceAtFlag.Name) {
 		log.Warn("log.backtrace flag is deprecated")
@@ -2238,6 +2381,43 @@ func SetupMetrics(cfg *metrics.Config) {
 	go metrics.CollectProcessMetrics(3 * time.Second)
 }
 
+// telemetryService flushes the spans that were not exported yet when the node stops.
+type telemetryService struct {
+	shutdown func(context.Context) error
+}
+
+func (s *telemetryService) Start() error { return nil }
+
+func (s *telemetryService) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.shutdown(ctx)
+}
+
+// SetupTelemetry enables the export of OpenTelemetry traces if an endpoint is configured.
+func SetupTelemetry(ctx *cli.Context, stack *node.Node) {
+	if !ctx.IsSet(TelemetryEndpointFlag.Name) {
+		return
+	}
+	config := exporter.Config{
+		Endpoint:    ctx.String(TelemetryEndpointFlag.Name),
+		ServiceName: ctx.String(TelemetryServiceNameFlag.Name),
+		SampleRatio: ctx.Float64(TelemetrySampleRatioFlag.Name),
+	}
+	if config.Endpoint == "" {
+		return
+	}
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		Fatalf("Invalid --%s %v, must be between 0 and 1", TelemetrySampleRatioFlag.Name, config.SampleRatio)
+	}
+	shutdown, err := exporter.Setup(config)
+	if err != nil {
+		Fatalf("Failed to set up tracing: %v", err)
+	}
+	log.Info("Enabling OpenTelemetry tracing", "endpoint", config.Endpoint, "sampleratio", config.SampleRatio)
+	stack.RegisterLifecycle(&telemetryService{shutdown: shutdown})
+}
+
 // SplitTagsFlag parses a comma-separated list of k=v metrics tags.
 func SplitTagsFlag(tagsFlag string) map[string]string {
 	tags := strings.Split(tagsFlag, ",")
@@ -2416,10 +2596,32 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		}
 	}
 
+	// the hooks read the state of the chain created below
+	var chain *core.BlockChain
+	onNewBlock := []func(block *types.Block, receipts []*types.Receipt) error{}
+
 	walDir := stack.Config().GolemBaseWriteAheadLogDir
 	if walDir != "" {
-		chain, err := core.NewBlockChainWithOnNewBlock(chainDb, cache, gspec, nil, engine, vmcfg, nil, func(block *types.Block, receipts []*types.Receipt) error {
-			return wal.WriteLogForBlock(walDir, block, config.ChainID, receipts)
+		onNewBlock = append(onNewBlock, func(block *types.Block, receipts []*types.Receipt) error {
+			return wal.WriteLogForBlock(walDir, block, config.ChainID, receipts, wal.ParentStateOf(chain, block))
+		})
+	}
+
+	if stack.Config().GolemBaseHistory {
+		onNewBlock = append(onNewBlock, func(block *types.Block, receipts []*types.Receipt) error {
+			return history.IndexBlock(chainDb, block, config.ChainID, receipts, wal.ParentStateOf(chain, block))
+		})
+	}
+
+	if len(onNewBlock) > 0 {
+		chain, err = core.NewBlockChainWithOnNewBlock(chainDb, cache, gspec, nil, engine, vmcfg, nil, func(block *types.Block, receipts []*types.Receipt) error {
+			for _, fn := range onNewBlock {
+				err := fn(block, receipts)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
 		})
 		if err != nil {
 			Fatalf("Can't create BlockChain with onNewBlock: %v", err)
@@ -2428,7 +2630,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	}
 
 	// Disable transaction indexing/unindexing by default.
-	chain, err := core.NewBlockChain(chainDb, cache, gspec, nil, engine, vmcfg, nil)
+	chain, err = core.NewBlockChain(chainDb, cache, gspec, nil, engine, vmcfg, nil)
 	if err != nil {
 		Fatalf("Can't create BlockChain: %v", err)
 	}
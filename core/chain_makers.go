@@ -51,6 +51,10 @@ type BlockGen struct {
 	uncles      []*types.Header
 	withdrawals []*types.Withdrawal
 
+	// golemBaseHousekeeping is the receipt of the housekeeping that ran before the
+	// transactions, nil before the Kaolin upgrade
+	golemBaseHousekeeping *types.Receipt
+
 	engine consensus.Engine
 }
 
@@ -406,6 +410,14 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 			ProcessParentBlockHash(b.header.ParentHash, evm)
 		}
 
+		// Golem Base housekeeping runs before the transactions of the block
+		blockContext := NewEVMBlockContext(b.header, cm, &b.header.Coinbase, b.cm.config, b.statedb)
+		housekeeping, err := ProcessGolemBaseHousekeeping(vm.NewEVM(blockContext, statedb, cm.config, vm.Config{}))
+		if err != nil {
+			panic(err)
+		}
+		b.golemBaseHousekeeping = housekeeping
+
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
@@ -417,6 +429,10 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 			b.header.RequestsHash = &reqHash
 		}
 
+		if b.golemBaseHousekeeping != nil {
+			b.receipts = types.AppendGolemBaseHousekeepingReceipt(b.receipts, b.golemBaseHousekeeping, common.Hash{}, b.header.Number.Uint64())
+		}
+
 		body := types.Body{Transactions: b.txs, Uncles: b.uncles, Withdrawals: b.withdrawals}
 		block, err := b.engine.FinalizeAndAssemble(cm, b.header, statedb, &body, b.receipts)
 		if err != nil {
@@ -449,26 +465,30 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		// Here we assign the final block hash and other info into the receipt.
 		// In order for DeriveFields to work, the transaction and receipt lists need to be
 		// of equal length. If AddUncheckedTx or AddUncheckedReceipt are used, there will be
-		// extra ones, so we just trim the lists here.
-		receiptsCount := len(receipts)
+		// extra ones, so we just trim the lists here. Since the Golem Base Kaolin upgrade,
+		// the last receipt is the one of housekeeping, which follows the trimmed list.
+		derived := receipts
+		var housekeeping types.Receipts
+		if config.IsGolemBaseKaolin(block.Time()) {
+			housekeeping = derived[len(derived)-1:]
+			derived = derived[:len(derived)-1]
+		}
 		txs := block.Transactions()
-		if len(receipts) > len(txs) {
-			receipts = receipts[:len(txs)]
-		} else if len(receipts) < len(txs) {
-			txs = txs[:len(receipts)]
+		if len(derived) > len(txs) {
+			derived = derived[:len(txs)]
+		} else if len(derived) < len(txs) {
+			txs = txs[:len(derived)]
 		}
+		derived = append(derived[:len(derived):len(derived)], housekeeping...)
 		var blobGasPrice *big.Int
 		if block.ExcessBlobGas() != nil {
 			blobGasPrice = eip4844.CalcBlobFee(cm.config, block.Header())
 		}
-		if err := receipts.DeriveFields(config, block.Hash(), block.NumberU64(), block.Time(), block.BaseFee(), blobGasPrice, txs); err != nil {
+		if err := derived.DeriveFields(config, block.Hash(), block.NumberU64(), block.Time(), block.BaseFee(), blobGasPrice, txs); err != nil {
 			panic(err)
 		}
 
-		// Re-expand to ensure all receipts are returned.
-		receipts = receipts[:receiptsCount]
-
-		// Advance the chain.
+		// Advance the chain, with all receipts.
 		cm.add(block, receipts)
 		parent = block
 	}
@@ -301,6 +301,9 @@ type ChainOverrides struct {
 	OverrideOptimismJovian   *uint64
 	OverrideOptimismInterop  *uint64
 	ApplySuperchainUpgrades  bool
+
+	// Golem Base additions
+	OverrideGolemBaseKaolin *uint64
 }
 
 // apply applies the chain overrides on the supplied chain config.
@@ -374,6 +377,15 @@ func (o *ChainOverrides) apply(cfg *params.ChainConfig) error {
 	if o.OverrideOptimismInterop != nil {
 		cfg.InteropTime = o.OverrideOptimismInterop
 	}
+	if o.OverrideGolemBaseKaolin != nil {
+		// the Golem Base config may be shared with other chain configs, copy it before changing it
+		var golemBase params.GolemBaseConfig
+		if cfg.GolemBase != nil {
+			golemBase = *cfg.GolemBase
+		}
+		golemBase.KaolinTime = o.OverrideGolemBaseKaolin
+		cfg.GolemBase = &golemBase
+	}
 
 	return cfg.CheckConfigForkOrder()
 }
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/consensus/ethash"
+	"github.com/jeffcogswell/golembase-op-geth/core/rawdb"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/core/vm"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+// TestGolemBaseHousekeeping checks that under Kaolin housekeeping runs in every block, even
+// without transactions, and that its logs make up a receipt of their own after the receipts
+// of the transactions of the block.
+func TestGolemBaseHousekeeping(t *testing.T) {
+	config := *params.TestChainConfig
+	config.GolemBase = &params.GolemBaseConfig{KaolinTime: new(uint64)}
+
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer    = types.LatestSigner(gspec.Config)
+		processor = address.GolemBaseStorageProcessorAddress
+	)
+
+	data, err := rlp.EncodeToBytes(&storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 1, Payload: []byte("hello")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the entity expires in block 2, which has no transactions
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, b *BlockGen) {
+		if i == 0 {
+			tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: b.TxNonce(addr), To: &processor, Gas: 1000000, GasPrice: b.header.BaseFee, Data: data})
+			b.AddTx(tx)
+		}
+	})
+
+	db := rawdb.NewMemoryDatabase()
+	chain, err := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// block 1 has the receipt of the storage transaction and an empty housekeeping receipt
+	receipts := chain.GetReceiptsByHash(blocks[0].Hash())
+	if len(receipts) != 2 || receipts[0].Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("block 1: want the storage transaction and the housekeeping receipts, have %v", receipts)
+	}
+	if len(receipts[1].Logs) != 0 || receipts[1].CumulativeGasUsed != receipts[0].CumulativeGasUsed {
+		t.Errorf("block 1: unexpected housekeeping receipt %+v", receipts[1])
+	}
+	entityKey := receipts[0].Logs[0].Topics[1]
+
+	// block 2 deleted the entity without any transaction
+	receipts = chain.GetReceiptsByHash(blocks[1].Hash())
+	if len(receipts) != 1 || len(receipts[0].Logs) != 1 {
+		t.Fatalf("block 2: want the housekeeping receipt with one log, have %v", receipts)
+	}
+	housekeeping := types.GolemBaseHousekeepingReceipt(blocks[1].Transactions(), receipts)
+	if housekeeping == nil || housekeeping.TxHash != types.GolemBaseHousekeepingTxHash(2) {
+		t.Fatalf("block 2: housekeeping receipt not found: %+v", receipts[0])
+	}
+	l := housekeeping.Logs[0]
+	if l.Address != processor || l.Topics[0] != storagetx.GolemBaseStorageEntityDeleted || l.Topics[1] != entityKey {
+		t.Errorf("block 2: unexpected housekeeping log %+v", l)
+	}
+	if l.TxHash != housekeeping.TxHash || l.BlockHash != blocks[1].Hash() || l.TxIndex != 0 || l.Index != 0 {
+		t.Errorf("block 2: housekeeping log not attributed to the housekeeping receipt: %+v", l)
+	}
+	if !types.BloomLookup(blocks[1].Bloom(), processor) || !types.BloomLookup(blocks[1].Bloom(), entityKey) {
+		t.Errorf("block 2: housekeeping log is not part of the logs bloom")
+	}
+
+	state, err := chain.StateAt(blocks[1].Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allentities.Contains(state, entityKey) {
+		t.Errorf("expired entity %s was not deleted", entityKey.Hex())
+	}
+	if entityexpiration.HasBacklog(state) {
+		t.Errorf("block 2 was added to the expiration backlog")
+	}
+	if !state.Exist(address.GolemBaseStorageProcessorAddress) {
+		t.Errorf("storage processor account was deleted")
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/consensus/ethash"
+	"github.com/jeffcogswell/golembase-op-geth/core/rawdb"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/core/vm"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/annotationindex"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+// TestGolemBaseKaolinMigration checks that the entities stored before Kaolin are
+// accounted for in the owner usage and the annotation key index once Kaolin activates.
+func TestGolemBaseKaolinMigration(t *testing.T) {
+	var (
+		key, _     = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr       = crypto.PubkeyToAddress(key.PublicKey)
+		kaolinTime = uint64(30)
+		config     = *params.TestChainConfig
+	)
+	// blocks are 10 seconds apart, so Kaolin activates at block 3
+	config.GolemBase = &params.GolemBaseConfig{KaolinTime: &kaolinTime}
+	var (
+		gspec = &Genesis{
+			Config: &config,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer    = types.LatestSigner(gspec.Config)
+		processor = address.GolemBaseStorageProcessorAddress
+	)
+
+	storageTx := func(b *BlockGen, stx *storagetx.StorageTransaction) *types.Transaction {
+		data, err := rlp.EncodeToBytes(stx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: b.TxNonce(addr), To: &processor, Gas: 1000000, GasPrice: b.header.BaseFee, Data: data})
+		b.AddTx(tx)
+		return tx
+	}
+
+	var created common.Hash
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, b *BlockGen) {
+		switch i {
+		case 0:
+			// before Kaolin, the storage processor account is created by the housekeeping of deposits
+			b.AddTx(types.NewTx(&types.DepositTx{
+				From:  common.HexToAddress("0xDeaDDEaDDeAdDeAdDEAdDEaddeAddEAdDEAd0001"),
+				To:    &types.L1BlockAddr,
+				Value: big.NewInt(0),
+				Gas:   1000000,
+			}))
+			tx := storageTx(b, &storagetx.StorageTransaction{Create: []storagetx.Create{
+				{TTL: 100, Payload: []byte("aa"), StringAnnotations: []entity.StringAnnotation{{Key: "kind", Value: "a"}}, NumericAnnotations: []entity.NumericAnnotation{{Key: "n", Value: 1}}},
+				{TTL: 100, Payload: []byte("b"), StringAnnotations: []entity.StringAnnotation{{Key: "kind", Value: "b"}}},
+			}})
+			created = crypto.Keccak256Hash(tx.Hash().Bytes(), []byte("aa"), common.LeftPadBytes([]byte{0}, 32))
+		case 3:
+			storageTx(b, &storagetx.StorageTransaction{Delete: []common.Hash{created}})
+		}
+	})
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	for i, want := range []struct {
+		migrated bool
+		usage    ownerusage.Usage
+		kinds    uint64
+	}{
+		{false, ownerusage.Usage{}, 0},
+		{false, ownerusage.Usage{}, 0},
+		{true, ownerusage.Usage{Entities: 2, PayloadBytes: 3, Annotations: 3}, 2},
+		{true, ownerusage.Usage{Entities: 1, PayloadBytes: 1, Annotations: 1}, 1},
+	} {
+		statedb, err := chain.StateAt(blocks[i].Root())
+		if err != nil {
+			t.Fatal(err)
+		}
+		number := blocks[i].NumberU64()
+		if have := entity.IsMigratedToKaolin(statedb); have != want.migrated {
+			t.Errorf("block %d: have migrated %v, want %v", number, have, want.migrated)
+		}
+		if have := ownerusage.Get(statedb, addr); have != want.usage {
+			t.Errorf("block %d: have owner usage %+v, want %+v", number, have, want.usage)
+		}
+		if have := ownerusage.Total(statedb); have != want.usage {
+			t.Errorf("block %d: have total usage %+v, want %+v", number, have, want.usage)
+		}
+		if have := keyset.Size(statedb, annotationindex.AnnotationKeyIndexKey("kind")).Uint64(); have != want.kinds {
+			t.Errorf("block %d: have %d entities with annotation key kind, want %d", number, have, want.kinds)
+		}
+	}
+
+	receipts := chain.GetReceiptsByHash(blocks[3].Hash())
+	if len(receipts) != 2 || receipts[0].Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("deleting an entity stored before Kaolin failed")
+	}
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/consensus/ethash"
+	"github.com/jeffcogswell/golembase-op-geth/core/rawdb"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/core/vm"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+// TestGolemBasePreKaolinReplay replays blocks of a chain without the Kaolin upgrade and
+// checks that their state roots and receipts are the ones produced before Golem Base
+// upgrades existed, so that the history of existing chains still validates.
+func TestGolemBasePreKaolinReplay(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer    = types.LatestSigner(gspec.Config)
+		processor = address.GolemBaseStorageProcessorAddress
+	)
+
+	storageTx := func(b *BlockGen, stx *storagetx.StorageTransaction) *types.Transaction {
+		data, err := rlp.EncodeToBytes(stx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: b.TxNonce(addr), To: &processor, Gas: 1000000, GasPrice: b.header.BaseFee, Data: data})
+		b.AddTx(tx)
+		return tx
+	}
+	// before Kaolin, housekeeping runs in deposit transactions
+	deposit := func(b *BlockGen) {
+		b.AddTx(types.NewTx(&types.DepositTx{
+			From:  common.HexToAddress("0xDeaDDEaDDeAdDeAdDEAdDEaddeAddEAdDEAd0001"),
+			To:    &types.L1BlockAddr,
+			Value: big.NewInt(0),
+			Gas:   1000000,
+		}))
+	}
+	str := func(k, v string) []entity.StringAnnotation { return []entity.StringAnnotation{{Key: k, Value: v}} }
+	num := func(k string, v uint64) []entity.NumericAnnotation {
+		return []entity.NumericAnnotation{{Key: k, Value: v}}
+	}
+
+	var updated common.Hash
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, b *BlockGen) {
+		switch i {
+		case 0:
+			deposit(b)
+			tx := storageTx(b, &storagetx.StorageTransaction{Create: []storagetx.Create{
+				{TTL: 1, Payload: []byte("a"), StringAnnotations: str("kind", "a"), NumericAnnotations: num("n", 1)},
+				{TTL: 10, Payload: []byte("bb"), StringAnnotations: str("kind", "b"), NumericAnnotations: num("n", 2)},
+				{TTL: 2, Payload: []byte("d"), StringAnnotations: str("kind", "d")},
+			}})
+			updated = crypto.Keccak256Hash(tx.Hash().Bytes(), []byte("bb"), common.LeftPadBytes([]byte{1}, 32))
+			// fails after its create was applied, which is not reverted before Kaolin
+			storageTx(b, &storagetx.StorageTransaction{
+				Create: []storagetx.Create{{TTL: 10, Payload: []byte("c"), StringAnnotations: str("kind", "c")}},
+				Update: []storagetx.Update{{EntityKey: common.Hash{1}, TTL: 10, Payload: []byte("x")}},
+			})
+		case 1:
+			deposit(b)
+			storageTx(b, &storagetx.StorageTransaction{
+				Update: []storagetx.Update{{EntityKey: updated, TTL: 10, Payload: []byte("bbb"), NumericAnnotations: num("n", 3)}},
+				Extend: []storagetx.ExtendTTL{{EntityKey: updated, NumberOfBlocks: 5}},
+			})
+		case 2:
+			deposit(b)
+			storageTx(b, &storagetx.StorageTransaction{Delete: []common.Hash{updated}})
+		}
+	})
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	type receipt struct {
+		status uint64
+		logs   int
+	}
+	for i, want := range []struct {
+		root     common.Hash
+		receipts []receipt
+	}{
+		{
+			root:     common.HexToHash("0x76ecfbe1cf237d7fbe5d8c513201bcabcbaca947e8c75af2774d1533b4c603b5"),
+			receipts: []receipt{{1, 0}, {1, 3}, {0, 0}},
+		},
+		{
+			// the deposit deletes the entity expiring at block 2
+			root:     common.HexToHash("0xf59bebfa257dc3f3c010065f9e271a106f24db4d47d5a05e6bba808b5f85c65d"),
+			receipts: []receipt{{1, 1}, {1, 2}},
+		},
+		{
+			root:     common.HexToHash("0x2e1a270441707da271165eec4169fb2b79b7adf55202a6cd23971d6ef11a78ee"),
+			receipts: []receipt{{1, 1}, {1, 1}},
+		},
+		{
+			root: common.HexToHash("0xe800204d71dab89cba19f01d9352b3c8d75795812e5f0a984c95e17b94398d96"),
+		},
+	} {
+		block := blocks[i]
+		if block.Root() != want.root {
+			t.Errorf("block %d: have root %s, want %s", block.NumberU64(), block.Root().Hex(), want.root.Hex())
+		}
+		receipts := chain.GetReceiptsByHash(block.Hash())
+		if len(receipts) != len(want.receipts) {
+			t.Fatalf("block %d: have %d receipts, want %d", block.NumberU64(), len(receipts), len(want.receipts))
+		}
+		for j, r := range receipts {
+			if have := (receipt{r.Status, len(r.Logs)}); have != want.receipts[j] {
+				t.Errorf("block %d, receipt %d: have status %d with %d logs, want status %d with %d logs", block.NumberU64(), j, have.status, have.logs, want.receipts[j].status, want.receipts[j].logs)
+			}
+		}
+	}
+}
@@ -27,6 +27,11 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
 	"github.com/jeffcogswell/golembase-op-geth/core/vm"
 	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtracing"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/housekeepingtx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
 	"github.com/jeffcogswell/golembase-op-geth/params"
 )
 
@@ -54,7 +59,11 @@ func NewStateProcessor(config *params.ChainConfig, chain *HeaderChain) *StatePro
 // Process returns the receipts and logs accumulated during the process and
 // returns the amount of gas that was used in the process. If any of the
 // transactions failed to execute due to insufficient gas it will return an error.
-func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (*ProcessResult, error) {
+func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (_ *ProcessResult, err error) {
+	ctx, span := startBlockSpan(block)
+	defer func() { telemetry.EndSpan(span, err) }()
+	cfg.TraceContext = ctx
+
 	var (
 		receipts    types.Receipts
 		usedGas     = new(uint64)
@@ -88,6 +97,12 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	if p.config.IsPrague(block.Number(), block.Time()) || p.config.IsVerkle(block.Number(), block.Time()) {
 		ProcessParentBlockHash(block.ParentHash(), evm)
 	}
+	// Golem Base housekeeping runs right before the first transaction, its receipt
+	// follows the receipts of the transactions
+	housekeeping, err := ProcessGolemBaseHousekeeping(evm)
+	if err != nil {
+		return nil, err
+	}
 
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
@@ -97,13 +112,21 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		}
 		statedb.SetTxContext(tx.Hash(), i)
 
+		txCtx, txSpan := startEVMSpan(evm, "core.applyTransaction", tx.Hash())
+		evm.Config.TraceContext = txCtx
 		receipt, err := ApplyTransactionWithEVM(msg, gp, statedb, blockNumber, blockHash, tx, usedGas, evm)
+		evm.Config.TraceContext = ctx
+		telemetry.EndSpan(txSpan, err)
 		if err != nil {
 			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
 		receipts = append(receipts, receipt)
 		allLogs = append(allLogs, receipt.Logs...)
 	}
+	if housekeeping != nil {
+		receipts = types.AppendGolemBaseHousekeepingReceipt(receipts, housekeeping, blockHash, blockNumber.Uint64())
+		allLogs = append(allLogs, housekeeping.Logs...)
+	}
 
 	isIsthmus := p.config.IsIsthmus(block.Time())
 
@@ -298,6 +321,71 @@ func ProcessParentBlockHash(prevHash common.Hash, evm *vm.EVM) {
 	evm.StateDB.Finalise(true)
 }
 
+// ProcessGolemBaseHousekeeping runs the Golem Base housekeeping of the block under the
+// Kaolin upgrade: it deletes the entities that expire at the block and removes the
+// abandoned uploads. It is a system operation that runs once per block, right before the
+// first transaction, also in blocks without transactions. It returns the receipt of
+// housekeeping, which the caller appends to the receipts of the transactions with
+// types.AppendGolemBaseHousekeepingReceipt once they ran.
+//
+// Before Kaolin, housekeeping runs inside every deposit transaction instead, and the
+// returned receipt is nil.
+func ProcessGolemBaseHousekeeping(evm *vm.EVM) (_ *types.Receipt, err error) {
+	rules := evm.ChainConfig().GolemBaseRules(evm.Context.Time)
+	if !rules.IsKaolin {
+		return nil, nil
+	}
+
+	// nothing was ever stored if the storage processor account does not exist
+	if !evm.StateDB.Exist(address.GolemBaseStorageProcessorAddress) {
+		return types.NewGolemBaseHousekeepingReceipt(nil), nil
+	}
+
+	_, span := startEVMSpan(evm, "golembase.housekeeping", common.Hash{})
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	if tracer := evm.Config.Tracer; tracer != nil {
+		onSystemCallStart(tracer, evm.GetVMContext())
+		if tracer.OnSystemCallEnd != nil {
+			defer tracer.OnSystemCallEnd()
+		}
+	}
+
+	blockNumber := evm.Context.BlockNumber.Uint64()
+
+	// entities stored before Kaolin are accounted for in the first Kaolin blocks
+	if err := entity.MigrateToKaolin(evm.StateDB, entity.KaolinMigrationEntitiesPerBlock); err != nil {
+		return nil, fmt.Errorf("failed to migrate to Kaolin in block %d: %w", blockNumber, err)
+	}
+
+	var hooks *golemtracing.Hooks
+	if tracer := evm.Config.Tracer; tracer != nil {
+		hooks = tracer.GolemBase
+	}
+
+	logs, err := housekeepingtx.Execute(rules, blockNumber, evm.StateDB, evm.ChainConfig().GolemBaseMaxExpirationsPerBlock(), hooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute housekeeping of block %d: %w", blockNumber, err)
+	}
+	evm.StateDB.Finalise(true)
+	return types.NewGolemBaseHousekeepingReceipt(logs), nil
+}
+
+// createGolemBaseStorageProcessor creates the account of the storage processor under the
+// Kaolin upgrade, if it does not exist yet. The account holds the entities in its storage,
+// it is given a nonce so it is not deleted as an empty account at the end of the transaction.
+// Before Kaolin, the account is created by the housekeeping of deposit transactions.
+// A new account holds no entities stored before Kaolin, so it is marked as migrated.
+func createGolemBaseStorageProcessor(evm *vm.EVM) {
+	if !evm.ChainConfig().GolemBaseRules(evm.Context.Time).IsKaolin || evm.StateDB.Exist(address.GolemBaseStorageProcessorAddress) {
+		return
+	}
+	evm.StateDB.CreateAccount(address.GolemBaseStorageProcessorAddress)
+	evm.StateDB.CreateContract(address.GolemBaseStorageProcessorAddress)
+	evm.StateDB.SetNonce(address.GolemBaseStorageProcessorAddress, 1, tracing.NonceChangeNewContract)
+	entity.MarkMigratedToKaolin(evm.StateDB)
+}
+
 // ProcessWithdrawalQueue calls the EIP-7002 withdrawal queue contract.
 // It returns the opaque request data returned by the contract.
 func ProcessWithdrawalQueue(requests *[][]byte, evm *vm.EVM) {
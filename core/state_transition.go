@@ -28,8 +28,11 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/core/vm"
 	"github.com/jeffcogswell/golembase-op-geth/crypto/kzg4844"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtracing"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/housekeepingtx"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
 	"github.com/jeffcogswell/golembase-op-geth/params"
 	"github.com/holiman/uint256"
 )
@@ -281,6 +284,14 @@ func (st *stateTransition) to() common.Address {
 	return *st.msg.To
 }
 
+// golemBaseHooks returns the Golem Base hooks of the tracer, or nil.
+func (st *stateTransition) golemBaseHooks() *golemtracing.Hooks {
+	if st.evm.Config.Tracer == nil {
+		return nil
+	}
+	return st.evm.Config.Tracer.GolemBase
+}
+
 func (st *stateTransition) buyGas() error {
 	mgval := new(big.Int).SetUint64(st.msg.GasLimit)
 	mgval.Mul(mgval, st.msg.GasPrice)
@@ -617,25 +628,37 @@ func (st *stateTransition) innerExecute() (*ExecutionResult, error) {
 
 		switch {
 		case st.to() == address.GolemBaseStorageProcessorAddress:
+			createGolemBaseStorageProcessor(st.evm)
 			st.evm.Context.Transfer(st.evm.StateDB, msg.From, st.to(), value)
 
 			if len(st.msg.Data) > 0 {
 				var logs []*types.Log
+				golemBaseRules := st.evm.ChainConfig().GolemBaseRules(st.evm.Context.Time)
+				// since Kaolin, the storage transaction is atomic, so any state changes
+				// made before a failing operation have to be reverted
+				snapshot := st.state.Snapshot()
 				// run the storage transaction
-				logs, vmerr = storagetx.ExecuteTransaction(st.msg.Data, st.msg.BlockNumber, st.msg.TransactionHash, msg.From, st.evm.StateDB)
+				_, span := startEVMSpan(st.evm, "golembase.storageTransaction", st.msg.TransactionHash)
+				logs, vmerr = storagetx.ExecuteTransaction(st.msg.Data, golemBaseRules, st.msg.BlockNumber, st.msg.TransactionHash, msg.From, st.evm.StateDB, ownerusage.QuotaOf(st.evm.ChainConfig()), st.golemBaseHooks())
+				telemetry.EndSpan(span, vmerr)
 				if err != nil {
 					return nil, fmt.Errorf("failed to execute storage transaction: %w", err)
 				}
 
-				if vmerr == nil {
+				if vmerr != nil {
+					if golemBaseRules.IsKaolin {
+						st.state.RevertToSnapshot(snapshot)
+					}
+				} else {
 					// add logs of the storage transaction
 					for _, log := range logs {
 						st.evm.StateDB.AddLog(log)
 					}
 				}
 			}
-		case msg.IsDepositTx:
-
+		case msg.IsDepositTx && !st.evm.ChainConfig().GolemBaseRules(st.evm.Context.Time).IsKaolin:
+			// before Kaolin, housekeeping runs in every deposit transaction,
+			// see ProcessGolemBaseHousekeeping for Kaolin
 			logs, err := housekeepingtx.ExecuteTransaction(st.msg.BlockNumber, st.msg.TransactionHash, st.evm.StateDB)
 			if err != nil {
 				return nil, fmt.Errorf("failed to execute housekeeping transaction: %w", err)
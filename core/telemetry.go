@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/core/vm"
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startBlockSpan starts the span of processing the block. Blocks are not processed on
+// behalf of a traced caller, so every block is the root of its own trace.
+func startBlockSpan(block *types.Block) (context.Context, trace.Span) {
+	ctx, span := telemetry.StartSpan(context.Background(), "core.processBlock")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.Int64("block.number", block.Number().Int64()),
+			attribute.String("block.hash", block.Hash().Hex()),
+			attribute.Int("block.transactions", len(block.Transactions())),
+		)
+	}
+	return ctx, span
+}
+
+// startEVMSpan starts a span as a child of the span the EVM executes in, if any.
+func startEVMSpan(evm *vm.EVM, name string, txHash common.Hash) (context.Context, trace.Span) {
+	ctx := evm.Config.TraceContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := telemetry.StartSpan(ctx, name)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int64("block.number", evm.Context.BlockNumber.Int64()))
+		if txHash != (common.Hash{}) {
+			span.SetAttributes(attribute.String("tx.hash", txHash.Hex()))
+		}
+	}
+	return ctx, span
+}
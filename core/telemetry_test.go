@@ -0,0 +1,92 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/consensus/ethash"
+	"github.com/jeffcogswell/golembase-op-geth/core/rawdb"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/core/vm"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestBlockProcessingSpans checks that processing a block exports the spans of its
+// transactions, of the storage transactions in them and of the housekeeping.
+func TestBlockProcessingSpans(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		config = *params.TestChainConfig
+	)
+	config.GolemBase = &params.GolemBaseConfig{KaolinTime: new(uint64)}
+	var (
+		gspec = &Genesis{
+			Config: &config,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer    = types.LatestSigner(gspec.Config)
+		processor = address.GolemBaseStorageProcessorAddress
+	)
+
+	// the storage processor account is created in the first block, so housekeeping runs from the second
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, b *BlockGen) {
+		data, err := rlp.EncodeToBytes(&storagetx.StorageTransaction{Create: []storagetx.Create{{TTL: 100, Payload: []byte{byte(i)}}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: b.TxNonce(addr), To: &processor, Gas: 1000000, GasPrice: b.header.BaseFee, Data: data})
+		b.AddTx(tx)
+	})
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	recorder := tracetest.NewSpanRecorder()
+	telemetry.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer telemetry.SetTracerProvider(nil)
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	names := map[trace.SpanID]string{}
+	for _, span := range recorder.Ended() {
+		names[span.SpanContext().SpanID()] = span.Name()
+	}
+	// the spans of the second block, the first one in which housekeeping runs
+	parents := map[string]string{}
+	for _, span := range recorder.Ended() {
+		for _, attr := range span.Attributes() {
+			if attr.Key == "block.number" && attr.Value.AsInt64() == 2 {
+				parents[span.Name()] = names[span.Parent().SpanID()]
+			}
+		}
+	}
+	for name, parent := range map[string]string{
+		"core.processBlock":            "",
+		"golembase.housekeeping":       "core.processBlock",
+		"core.applyTransaction":        "core.processBlock",
+		"golembase.storageTransaction": "core.applyTransaction",
+	} {
+		have, ok := parents[name]
+		if !ok {
+			t.Errorf("span %s was not exported", name)
+			continue
+		}
+		if have != parent {
+			t.Errorf("span %s: have parent %q, want %q", name, have, parent)
+		}
+	}
+}
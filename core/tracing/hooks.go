@@ -29,6 +29,7 @@ import (
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtracing"
 	"github.com/jeffcogswell/golembase-op-geth/params"
 	"github.com/holiman/uint256"
 )
@@ -215,6 +216,8 @@ type Hooks struct {
 	OnLog           LogHook
 	// Block hash read
 	OnBlockHashRead BlockHashReadHook
+	// Golem Base storage and housekeeping transactions
+	GolemBase *golemtracing.Hooks
 }
 
 // BalanceChangeReason is used to indicate the reason for a balance change, useful
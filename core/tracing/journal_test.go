@@ -298,6 +298,8 @@ func newTracerAllHooks() *tracerAllHooks {
 		t.hooksCalled[hooksType.Field(i).Name] = false
 	}
 	delete(t.hooksCalled, "OnNonceChange")
+	// GolemBase is a set of hooks, not a hook
+	delete(t.hooksCalled, "GolemBase")
 	return t
 }
 
@@ -322,7 +324,7 @@ func (t *tracerAllHooks) hooks() *Hooks {
 	hooksValue := reflect.ValueOf(h).Elem()
 	for i := 0; i < hooksValue.NumField(); i++ {
 		field := hooksValue.Type().Field(i)
-		if field.Name == "OnNonceChange" {
+		if field.Name == "OnNonceChange" || field.Type.Kind() != reflect.Func {
 			continue
 		}
 		hookMethod := reflect.MakeFunc(field.Type, func(args []reflect.Value) []reflect.Value {
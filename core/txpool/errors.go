@@ -56,4 +56,8 @@ var (
 	// input transaction of non-blob type when a blob transaction from this sender
 	// remains pending (and vice-versa).
 	ErrAlreadyReserved = errors.New("address already reserved")
+
+	// ErrInvalidStorageTx is returned if a Golem Base storage transaction can not
+	// be decoded or uses operations of an upgrade that is not active at the head.
+	ErrInvalidStorageTx = errors.New("invalid storage transaction")
 )
@@ -28,8 +28,11 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/core/state"
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
 	"github.com/jeffcogswell/golembase-op-geth/crypto/kzg4844"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
 	"github.com/jeffcogswell/golembase-op-geth/log"
 	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
 )
 
 // L1 Info Gas Overhead is the amount of gas the the L1 info deposit consumes.
@@ -113,6 +116,12 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 	if !rules.IsPrague && tx.Type() == types.SetCodeTxType {
 		return fmt.Errorf("%w: type %d rejected, pool not yet in Prague", core.ErrTxTypeNotSupported, tx.Type())
 	}
+	// Ensure storage transactions only use the active Golem Base upgrades
+	if to := tx.To(); to != nil && *to == address.GolemBaseStorageProcessorAddress && len(tx.Data()) > 0 {
+		if err := validateStorageTx(tx.Data(), opts.Config.GolemBaseRules(head.Time)); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidStorageTx, err)
+		}
+	}
 	// Check whether the init code size has been exceeded
 	if rules.IsShanghai && tx.To() == nil && len(tx.Data()) > params.MaxInitCodeSize {
 		return fmt.Errorf("%w: code size %v, limit %v", core.ErrMaxInitCodeSizeExceeded, len(tx.Data()), params.MaxInitCodeSize)
@@ -196,6 +205,16 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 	return nil
 }
 
+// validateStorageTx checks that the data of a storage transaction decodes and is
+// valid under the Golem Base rules.
+func validateStorageTx(data []byte, rules params.GolemBaseRules) error {
+	var stx storagetx.StorageTransaction
+	if err := rlp.DecodeBytes(data, &stx); err != nil {
+		return err
+	}
+	return stx.Validate(rules)
+}
+
 func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) error {
 	if len(sidecar.Blobs) != len(hashes) {
 		return fmt.Errorf("invalid number of %d blobs compared to %d blob hashes", len(sidecar.Blobs), len(hashes))
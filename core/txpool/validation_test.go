@@ -0,0 +1,55 @@
+package txpool
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStorageTransactionRules(t *testing.T) {
+	config := *params.TestChainConfig
+	kaolinTime := uint64(10)
+	config.GolemBase = &params.GolemBaseConfig{KaolinTime: &kaolinTime}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSigner(&config)
+
+	opts := &ValidationOptions{
+		Config:  &config,
+		Accept:  1 << types.LegacyTxType,
+		MaxSize: 128 * 1024,
+		MinTip:  big.NewInt(0),
+	}
+
+	newStorageTx := func(data []byte) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.LegacyTx{
+			To:       &address.GolemBaseStorageProcessorAddress,
+			Gas:      1_000_000,
+			GasPrice: big.NewInt(params.InitialBaseFee),
+			Data:     data,
+		})
+	}
+
+	named, err := rlp.EncodeToBytes(&storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 100, Payload: []byte("hello"), Name: "greeting"}},
+	})
+	require.NoError(t, err)
+
+	head := func(time uint64) *types.Header {
+		return &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), GasLimit: 30_000_000, Time: time}
+	}
+
+	// named entities are rejected until Kaolin is active at the head
+	require.ErrorIs(t, ValidateTransaction(newStorageTx(named), head(kaolinTime-1), signer, opts), ErrInvalidStorageTx)
+	require.NoError(t, ValidateTransaction(newStorageTx(named), head(kaolinTime), signer, opts))
+
+	require.ErrorIs(t, ValidateTransaction(newStorageTx([]byte{0xff}), head(kaolinTime), signer, opts), ErrInvalidStorageTx)
+}
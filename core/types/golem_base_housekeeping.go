@@ -0,0 +1,80 @@
+package types
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+)
+
+// GolemBaseHousekeepingTxHash returns the hash the receipt and the logs of the Golem Base
+// housekeeping of the block are attributed to. Housekeeping is a system operation of the
+// block, the hash does not belong to any transaction.
+func GolemBaseHousekeepingTxHash(blockNumber uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte("golemBaseHousekeeping"), binary.BigEndian.AppendUint64(nil, blockNumber))
+}
+
+// NewGolemBaseHousekeepingReceipt returns the receipt of the Golem Base housekeeping of a block
+// with the logs it emitted. Since the Kaolin upgrade, every block has one, right after the
+// receipts of its transactions, so it is part of the receipt root and the logs bloom of the
+// block like any other receipt, see AppendGolemBaseHousekeepingReceipt.
+func NewGolemBaseHousekeepingReceipt(logs []*Log) *Receipt {
+	if logs == nil {
+		logs = []*Log{}
+	}
+	r := &Receipt{
+		Type:   LegacyTxType,
+		Status: ReceiptStatusSuccessful,
+		Logs:   logs,
+	}
+	r.Bloom = CreateBloom(r)
+	return r
+}
+
+// AppendGolemBaseHousekeepingReceipt appends the housekeeping receipt r of a block to the
+// receipts of its transactions and fills in its fields. Housekeeping uses no gas, so its
+// cumulative gas used is the one of the last transaction. hash is the hash of the block,
+// it is empty while the block is built.
+func AppendGolemBaseHousekeepingReceipt(txReceipts []*Receipt, r *Receipt, hash common.Hash, number uint64) []*Receipt {
+	r.CumulativeGasUsed = 0
+	if len(txReceipts) > 0 {
+		r.CumulativeGasUsed = txReceipts[len(txReceipts)-1].CumulativeGasUsed
+	}
+	DeriveGolemBaseHousekeepingFields(r, hash, number, txReceipts)
+	return append(txReceipts[:len(txReceipts):len(txReceipts)], r)
+}
+
+// GolemBaseHousekeepingReceipt returns the receipt of the Golem Base housekeeping among the
+// receipts of a block with the transactions txs, or nil if the block has none.
+func GolemBaseHousekeepingReceipt(txs Transactions, receipts []*Receipt) *Receipt {
+	if len(receipts) <= len(txs) {
+		return nil
+	}
+	return receipts[len(txs)]
+}
+
+// DeriveGolemBaseHousekeepingFields fills in the fields of the housekeeping receipt of a block
+// that are not stored. txReceipts are the receipts of the transactions of the block, the
+// housekeeping receipt and its logs are indexed after them.
+func DeriveGolemBaseHousekeepingFields(r *Receipt, hash common.Hash, number uint64, txReceipts []*Receipt) {
+	logIndex := uint(0)
+	for _, receipt := range txReceipts {
+		logIndex += uint(len(receipt.Logs))
+	}
+
+	r.TxHash = GolemBaseHousekeepingTxHash(number)
+	r.BlockHash = hash
+	r.BlockNumber = new(big.Int).SetUint64(number)
+	r.TransactionIndex = uint(len(txReceipts))
+	r.GasUsed = 0
+
+	for _, l := range r.Logs {
+		l.BlockNumber = number
+		l.BlockHash = hash
+		l.TxHash = r.TxHash
+		l.TxIndex = r.TransactionIndex
+		l.Index = logIndex
+		logIndex++
+	}
+}
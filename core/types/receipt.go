@@ -529,10 +529,16 @@ func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, nu
 	signer := MakeSigner(config, new(big.Int).SetUint64(number), time)
 
 	logIndex := uint(0)
-	if len(txs) != len(rs) {
+	// since the Golem Base Kaolin upgrade, the receipt of the housekeeping of the block
+	// follows the receipts of the transactions
+	receiptCount := len(txs)
+	if config.IsGolemBaseKaolin(time) {
+		receiptCount++
+	}
+	if receiptCount != len(rs) {
 		return errors.New("transaction and receipt count mismatch")
 	}
-	for i := 0; i < len(rs); i++ {
+	for i := 0; i < len(txs); i++ {
 		// The transaction type and hash can be retrieved from the transaction itself
 		rs[i].Type = txs[i].Type()
 		rs[i].TxHash = txs[i].Hash()
@@ -579,12 +585,15 @@ func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, nu
 			logIndex++
 		}
 	}
+	if len(rs) > len(txs) {
+		DeriveGolemBaseHousekeepingFields(rs[len(txs)], hash, number, rs[:len(txs)])
+	}
 	if config.Optimism != nil && len(txs) >= 2 && config.IsBedrock(new(big.Int).SetUint64(number)) { // need at least an info tx and a non-info tx
 		gasParams, err := extractL1GasParams(config, time, txs[0].Data())
 		if err != nil {
 			return err
 		}
-		for i := 0; i < len(rs); i++ {
+		for i := 0; i < len(txs); i++ {
 			if txs[i].IsDepositTx() {
 				continue
 			}
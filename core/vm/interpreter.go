@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
@@ -44,6 +45,10 @@ type Config struct {
 	PrecompileOverrides PrecompileOverrides                   // Precompiles can be swapped / changed / wrapped as needed
 	NoMaxCodeSize       bool                                  // Ignore Max code size and max init code size limits
 	CallerOverride      func(v common.Address) common.Address // Swap the caller as needed, for VM prank functionality.
+
+	// TraceContext holds the telemetry span of the block or transaction being executed, if any.
+	// Spans of the Golem Base operations of the execution are started as its children.
+	TraceContext context.Context
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -39,6 +39,7 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/eth/gasprice"
 	"github.com/jeffcogswell/golembase-op-geth/eth/tracers"
 	"github.com/jeffcogswell/golembase-op-geth/ethdb"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/query"
 	"github.com/jeffcogswell/golembase-op-geth/event"
 	"github.com/jeffcogswell/golembase-op-geth/log"
 	"github.com/jeffcogswell/golembase-op-geth/params"
@@ -400,6 +401,11 @@ func (b *EthAPIBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
 
+// GolemBaseQueryLimits returns the limits of a single Golem Base query.
+func (b *EthAPIBackend) GolemBaseQueryLimits() query.Limits {
+	return b.eth.golemBaseQueryLimits
+}
+
 func (b *EthAPIBackend) EventMux() *event.TypeMux {
 	return b.eth.EventMux()
 }
@@ -1,20 +1,33 @@
 package eth
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/entityproof"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtype"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/history"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/query"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/annotationindex"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entitiesofowner"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/pendingupload"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var errHistoryNotEnabled = errors.New("entity history index is not enabled, start the node with --golembase.history")
+
 // golemBaseAPI offers helper utils
 type golemBaseAPI struct {
 	eth *Ethereum
@@ -26,27 +39,66 @@ func NewGolemBaseAPI(eth *Ethereum) *golemBaseAPI {
 	}
 }
 
-func (api *golemBaseAPI) GetStorageValue(key common.Hash) ([]byte, error) {
+// observeDuration starts timing a golembase_* method, the returned function records
+// the duration in the golembase/rpc/<method> timer.
+func observeDuration(method string) func() {
+	if !metrics.Enabled() {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		metrics.GetOrRegisterTimer("golembase/rpc/"+method, nil).UpdateSince(start)
+	}
+}
+
+// GetStorageValue returns the payload of the entity as stored.
+// If decode is true, a payload with a content encoding is decompressed first.
+func (api *golemBaseAPI) GetStorageValue(key common.Hash, decode *bool) ([]byte, error) {
+	defer observeDuration("getStorageValue")()
+
 	header := api.eth.blockchain.CurrentBlock()
 	stateDb, err := api.eth.BlockChain().StateAt(header.Root)
 	if err != nil {
 		return nil, err
 	}
 
-	return entity.GetPayload(stateDb, key), nil
+	if entity.IsExpired(stateDb, key) {
+		return []byte{}, nil
+	}
+
+	payload := entity.GetPayload(stateDb, key)
+
+	if decode == nil || !*decode || !allentities.Contains(stateDb, key) {
+		return payload, nil
+	}
+
+	md, err := entity.GetEntityMetaData(stateDb, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity meta data: %w", err)
+	}
+
+	return entity.DecodePayload(md.ContentEncoding, payload)
 }
 
 func (api *golemBaseAPI) GetEntityMetaData(key common.Hash) (*entity.EntityMetaData, error) {
+	defer observeDuration("getEntityMetaData")()
+
 	header := api.eth.blockchain.CurrentBlock()
 	stateDb, err := api.eth.BlockChain().StateAt(header.Root)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get state: %w", err)
 	}
 
+	if entity.IsExpired(stateDb, key) {
+		return nil, fmt.Errorf("entity %s not found", key.Hex())
+	}
+
 	return entity.GetEntityMetaData(stateDb, key)
 }
 
 func (api *golemBaseAPI) GetEntitiesToExpireAtBlock(blockNumber uint64) ([]common.Hash, error) {
+	defer observeDuration("getEntitiesToExpireAtBlock")()
+
 	header := api.eth.blockchain.CurrentBlock()
 	stateDb, err := api.eth.BlockChain().StateAt(header.Root)
 	if err != nil {
@@ -54,13 +106,118 @@ func (api *golemBaseAPI) GetEntitiesToExpireAtBlock(blockNumber uint64) ([]commo
 	}
 
 	out := slices.Collect(entityexpiration.IteratorOfEntitiesToExpireAtBlock(stateDb, blockNumber))
-	if out == nil {
-		out = make([]common.Hash, 0)
+	return entity.WithoutExpired(stateDb, out), nil
+}
+
+const (
+	defaultExpiringEntitiesPageSize = 100
+	maxExpiringEntitiesPageSize     = 1000
+
+	// maxExpiringEntitiesBlocksScanned bounds the number of blocks scanned by a single call
+	maxExpiringEntitiesBlocksScanned = 100_000
+
+	// maxExpiringEntitiesExamined bounds the number of entities whose metadata a single call
+	// reads, including the ones of other owners that are filtered out
+	maxExpiringEntitiesExamined = 10_000
+)
+
+// GetEntitiesExpiringBetween returns the entities that expire in the blocks from..to (inclusive),
+// ordered by their expiration block, optionally only the ones of owner.
+// At most pageSize entities are returned, at most 100000 blocks are scanned and at most 10000 entities
+// are examined; if there are more, the returned cursor can be passed to the next call to continue from where the page ended.
+func (api *golemBaseAPI) GetEntitiesExpiringBetween(
+	from, to uint64,
+	owner *common.Address,
+	cursor *golemtype.ExpiringEntitiesCursor,
+	pageSize *uint64,
+) (*golemtype.ExpiringEntitiesPage, error) {
+	defer observeDuration("getEntitiesExpiringBetween")()
+
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: from %d is after to %d", from, to)
 	}
-	return out, nil
+
+	limit := uint64(defaultExpiringEntitiesPageSize)
+	if pageSize != nil {
+		limit = min(*pageSize, maxExpiringEntitiesPageSize)
+	}
+	if limit == 0 {
+		return nil, errors.New("page size must be positive")
+	}
+
+	header := api.eth.blockchain.CurrentBlock()
+	stateDb, err := api.eth.BlockChain().StateAt(header.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	// entities expiring up to the current block have already expired
+	blockNumber := max(from, header.Number.Uint64()+1)
+	offset := uint64(0)
+	if cursor != nil {
+		if cursor.BlockNumber < from || cursor.BlockNumber > to {
+			return nil, fmt.Errorf("cursor block %d is outside of the range %d..%d", cursor.BlockNumber, from, to)
+		}
+		blockNumber = max(blockNumber, cursor.BlockNumber)
+		if blockNumber == cursor.BlockNumber {
+			offset = cursor.Offset
+		}
+	}
+
+	page := &golemtype.ExpiringEntitiesPage{
+		Entities: []golemtype.ExpiringEntity{},
+	}
+
+	examined := uint64(0)
+	for scanned := uint64(0); blockNumber <= to; scanned++ {
+		if scanned == maxExpiringEntitiesBlocksScanned {
+			// let the caller continue, so that a call over a long, mostly empty range stays bounded
+			page.Next = &golemtype.ExpiringEntitiesCursor{BlockNumber: blockNumber}
+			return page, nil
+		}
+
+		position := uint64(0)
+		for key := range entityexpiration.IteratorOfEntitiesToExpireAtBlock(stateDb, blockNumber) {
+			position++
+			if position <= offset {
+				continue
+			}
+
+			if uint64(len(page.Entities)) == limit || examined == maxExpiringEntitiesExamined {
+				// a page of an owner with few entities among many expiring ones can end up short
+				page.Next = &golemtype.ExpiringEntitiesCursor{BlockNumber: blockNumber, Offset: position - 1}
+				return page, nil
+			}
+			examined++
+
+			md, err := entity.GetEntityMetaData(stateDb, key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get entity meta data for %s: %w", key.Hex(), err)
+			}
+
+			if owner != nil && md.Owner != *owner {
+				continue
+			}
+
+			page.Entities = append(page.Entities, golemtype.ExpiringEntity{
+				Key:            key,
+				ExpiresAtBlock: blockNumber,
+				Owner:          md.Owner,
+			})
+		}
+
+		if blockNumber == to {
+			break
+		}
+		blockNumber, offset = blockNumber+1, 0
+	}
+
+	return page, nil
 }
 
 func (api *golemBaseAPI) GetEntitiesForStringAnnotationValue(key, value string) ([]common.Hash, error) {
+	defer observeDuration("getEntitiesForStringAnnotationValue")()
+
 	header := api.eth.blockchain.CurrentBlock()
 	stateDb, err := api.eth.BlockChain().StateAt(header.Root)
 	if err != nil {
@@ -70,13 +227,12 @@ func (api *golemBaseAPI) GetEntitiesForStringAnnotationValue(key, value string)
 	entitySetKey := annotationindex.StringAnnotationIndexKey(key, value)
 
 	out := slices.Collect(keyset.Iterate(stateDb, entitySetKey))
-	if out == nil {
-		out = make([]common.Hash, 0)
-	}
-	return out, nil
+	return entity.WithoutExpired(stateDb, out), nil
 }
 
 func (api *golemBaseAPI) GetEntitiesForNumericAnnotationValue(key string, value uint64) ([]common.Hash, error) {
+	defer observeDuration("getEntitiesForNumericAnnotationValue")()
+
 	header := api.eth.blockchain.CurrentBlock()
 	stateDb, err := api.eth.BlockChain().StateAt(header.Root)
 	if err != nil {
@@ -86,56 +242,77 @@ func (api *golemBaseAPI) GetEntitiesForNumericAnnotationValue(key string, value
 	entityKeys := annotationindex.NumericAnnotationIndexKey(key, value)
 
 	out := slices.Collect(keyset.Iterate(stateDb, entityKeys))
-	if out == nil {
-		out = make([]common.Hash, 0)
+	return entity.WithoutExpired(stateDb, out), nil
+}
+
+// GetEntitiesWithAnnotation returns the entities that have an annotation with the key, regardless of its type and value.
+func (api *golemBaseAPI) GetEntitiesWithAnnotation(key string) ([]common.Hash, error) {
+	defer observeDuration("getEntitiesWithAnnotation")()
+
+	header := api.eth.blockchain.CurrentBlock()
+	stateDb, err := api.eth.BlockChain().StateAt(header.Root)
+	if err != nil {
+		return nil, err
 	}
-	return out, nil
+
+	out := slices.Collect(keyset.Iterate(stateDb, annotationindex.AnnotationKeyIndexKey(key)))
+	return entity.WithoutExpired(stateDb, out), nil
 }
 
-func (api *golemBaseAPI) QueryEntities(req string) ([]golemtype.SearchResult, error) {
+// QueryEntities returns the entities matching the query, together with their payloads.
+// The query fails if it reads more storage slots or takes longer than the configured limits.
+func (api *golemBaseAPI) QueryEntities(ctx context.Context, req string) ([]golemtype.SearchResult, error) {
+	defer observeDuration("queryEntities")()
 
+	_, span := telemetry.StartSpan(ctx, "golembase.query.parse")
 	expr, err := query.Parse(req)
+	telemetry.EndSpan(span, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse query: %w", err)
 	}
 
-	ds := &golemBaseDataSource{api: api}
+	_, span = telemetry.StartSpan(ctx, "golembase.query.state")
+	stateDb, err := api.eth.BlockChain().StateAt(api.eth.BlockChain().CurrentHeader().Root)
+	telemetry.EndSpan(span, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	_, span = telemetry.StartSpan(ctx, "golembase.query.evaluate")
+	ds := query.NewStateDataSource(stateDb, api.eth.golemBaseQueryLimits)
 	entities, err := expr.Evaluate(ds)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("golembase.query.matches", len(entities)))
+	}
+	telemetry.EndSpan(span, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate query: %w", err)
 	}
 
+	_, span = telemetry.StartSpan(ctx, "golembase.query.payloads")
+	defer telemetry.EndSpan(span, nil)
+
 	searchResults := make([]golemtype.SearchResult, 0)
 
-	for _, key := range entities {
-		v, err := api.GetStorageValue(key)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get storage value for key %s: %w", key.Hex(), err)
-		}
+	for _, key := range entity.WithoutExpired(stateDb, entities) {
 		searchResults = append(searchResults, golemtype.SearchResult{
 			Key:   key,
-			Value: v,
+			Value: entity.GetPayload(stateDb, key),
 		})
 	}
 
-	return searchResults, nil
-
-}
-
-type golemBaseDataSource struct {
-	api *golemBaseAPI
-}
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("golembase.query.results", len(searchResults)))
+	}
 
-func (ds *golemBaseDataSource) GetKeysForStringAnnotation(key, value string) ([]common.Hash, error) {
-	return ds.api.GetEntitiesForStringAnnotationValue(key, value)
-}
+	return searchResults, nil
 
-func (ds *golemBaseDataSource) GetKeysForNumericAnnotation(key string, value uint64) ([]common.Hash, error) {
-	return ds.api.GetEntitiesForNumericAnnotationValue(key, value)
 }
 
 // GetEntityCount returns the total number of entities in the storage.
 func (api *golemBaseAPI) GetEntityCount() (uint64, error) {
+	defer observeDuration("getEntityCount")()
+
 	stateDb, err := api.eth.BlockChain().StateAt(api.eth.BlockChain().CurrentHeader().Root)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get state: %w", err)
@@ -144,11 +321,13 @@ func (api *golemBaseAPI) GetEntityCount() (uint64, error) {
 	// Use keyset.Size to get the count of entities from the global registry
 	count := keyset.Size(stateDb, allentities.AllEntitiesKey)
 
-	return count.Uint64(), nil
+	return count.Uint64() - entityexpiration.BacklogSize(stateDb), nil
 }
 
 // GetAllEntityKeys returns all entity keys in the storage.
 func (api *golemBaseAPI) GetAllEntityKeys() ([]common.Hash, error) {
+	defer observeDuration("getAllEntityKeys")()
+
 	stateDb, err := api.eth.BlockChain().StateAt(api.eth.BlockChain().CurrentHeader().Root)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get state: %w", err)
@@ -161,13 +340,12 @@ func (api *golemBaseAPI) GetAllEntityKeys() ([]common.Hash, error) {
 		entityKeys = append(entityKeys, hash)
 	}
 
-	if entityKeys == nil {
-		entityKeys = make([]common.Hash, 0)
-	}
-	return entityKeys, nil
+	return entity.WithoutExpired(stateDb, entityKeys), nil
 }
 
 func (api *golemBaseAPI) GetEntitiesOfOwner(owner common.Address) ([]common.Hash, error) {
+	defer observeDuration("getEntitiesOfOwner")()
+
 	stateDb, err := api.eth.BlockChain().StateAt(api.eth.BlockChain().CurrentHeader().Root)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get state: %w", err)
@@ -175,8 +353,100 @@ func (api *golemBaseAPI) GetEntitiesOfOwner(owner common.Address) ([]common.Hash
 
 	entityKeys := slices.Collect(entitiesofowner.Iterate(stateDb, owner))
 
-	if entityKeys == nil {
-		entityKeys = make([]common.Hash, 0)
+	return entity.WithoutExpired(stateDb, entityKeys), nil
+}
+
+// GetOwnerUsage returns the number of entities, payload bytes and annotations stored by the owner.
+// These are the counters that the quotas are checked against, so they include expired entities
+// that housekeeping has not deleted yet because of the per-block expiration limit, and pending
+// uploads as the entities they become.
+func (api *golemBaseAPI) GetOwnerUsage(owner common.Address) (*ownerusage.Usage, error) {
+	defer observeDuration("getOwnerUsage")()
+
+	stateDb, err := api.eth.BlockChain().StateAt(api.eth.BlockChain().CurrentHeader().Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %w", err)
 	}
-	return entityKeys, nil
+
+	usage := ownerusage.Get(stateDb, owner)
+
+	return &usage, nil
+}
+
+// GetEntityKeyForName returns the key that an entity created by owner with the given namespace and name has.
+// The key is derived deterministically, so it can be computed before the entity is created.
+func (api *golemBaseAPI) GetEntityKeyForName(owner common.Address, namespace, name string) common.Hash {
+	defer observeDuration("getEntityKeyForName")()
+
+	return entity.NamedEntityKey(owner, namespace, name)
+}
+
+// ResolveEntityName resolves the namespace and name of an entity created by owner to its key.
+// It returns an error if no such entity currently exists.
+func (api *golemBaseAPI) ResolveEntityName(owner common.Address, namespace, name string) (common.Hash, error) {
+	defer observeDuration("resolveEntityName")()
+
+	stateDb, err := api.eth.BlockChain().StateAt(api.eth.BlockChain().CurrentHeader().Root)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	key := entity.NamedEntityKey(owner, namespace, name)
+	if !allentities.Contains(stateDb, key) || entity.IsExpired(stateDb, key) {
+		return common.Hash{}, fmt.Errorf("entity %q in namespace %q of owner %s not found", name, namespace, owner.Hex())
+	}
+
+	return key, nil
+}
+
+// GetEntityHistory returns all recorded revisions of the entity, oldest first.
+// It requires the node to run with the entity history index enabled.
+func (api *golemBaseAPI) GetEntityHistory(key common.Hash) ([]history.Revision, error) {
+	defer observeDuration("getEntityHistory")()
+
+	if !api.eth.golemBaseHistory {
+		return nil, errHistoryNotEnabled
+	}
+
+	return history.GetEntityHistory(api.eth.ChainDb(), key)
+}
+
+// GetEntityAt returns the payload and annotations of the entity as they were after the given revision.
+// It requires the node to run with the entity history index enabled.
+func (api *golemBaseAPI) GetEntityAt(key common.Hash, revision uint64) (*history.EntityState, error) {
+	defer observeDuration("getEntityAt")()
+
+	if !api.eth.golemBaseHistory {
+		return nil, errHistoryNotEnabled
+	}
+
+	return history.GetEntityAt(api.eth.ChainDb(), key, revision)
+}
+
+// GetPendingUpload returns the state of a chunked upload that is not finalized yet,
+// e.g. to resume an interrupted upload at the offset of the bytes received so far.
+func (api *golemBaseAPI) GetPendingUpload(key common.Hash) (*pendingupload.PendingUpload, error) {
+	defer observeDuration("getPendingUpload")()
+
+	stateDb, err := api.eth.BlockChain().StateAt(api.eth.BlockChain().CurrentHeader().Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	return pendingupload.Get(stateDb, key)
+}
+
+// GetEntityProof returns the account proof of the storage processor and the storage proofs of
+// all slots that make up the entity in the block, i.e. its metadata, its payload and its
+// membership in the set of all entities. A client can check it against the state root of
+// the block with entityproof.Verify. For an entity that does not exist, it proves its absence.
+func (api *golemBaseAPI) GetEntityProof(ctx context.Context, key common.Hash, blockNrOrHash rpc.BlockNumberOrHash) (*entityproof.EntityProof, error) {
+	defer observeDuration("getEntityProof")()
+
+	statedb, header, err := api.eth.APIBackend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+
+	return entityproof.Prove(statedb, header, key)
 }
@@ -49,12 +49,16 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/eth/tracers"
 	"github.com/jeffcogswell/golembase-op-geth/ethdb"
 	"github.com/jeffcogswell/golembase-op-geth/event"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/blockmetrics"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/history"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/query"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/wal"
 	"github.com/jeffcogswell/golembase-op-geth/internal/ethapi"
 	"github.com/jeffcogswell/golembase-op-geth/internal/sequencerapi"
 	"github.com/jeffcogswell/golembase-op-geth/internal/shutdowncheck"
 	"github.com/jeffcogswell/golembase-op-geth/internal/version"
 	"github.com/jeffcogswell/golembase-op-geth/log"
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
 	"github.com/jeffcogswell/golembase-op-geth/miner"
 	"github.com/jeffcogswell/golembase-op-geth/node"
 	"github.com/jeffcogswell/golembase-op-geth/p2p"
@@ -113,6 +117,9 @@ type Ethereum struct {
 	shutdownTracker *shutdowncheck.ShutdownTracker // Tracks if and when the node has shutdown ungracefully
 
 	nodeCloser func() error
+
+	golemBaseHistory     bool         // Whether the Golem Base entity history index is maintained
+	golemBaseQueryLimits query.Limits // Limits of a single Golem Base query
 }
 
 // New creates a new Ethereum object (including the initialisation of the common Ethereum object),
@@ -257,13 +264,55 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.OverrideOptimismInterop != nil {
 		overrides.OverrideOptimismInterop = config.OverrideOptimismInterop
 	}
+	if config.OverrideGolemBaseKaolin != nil {
+		overrides.OverrideGolemBaseKaolin = config.OverrideGolemBaseKaolin
+	}
 	overrides.ApplySuperchainUpgrades = config.ApplySuperchainUpgrades
 
+	onNewBlock := []func(block *types.Block, receipts []*types.Receipt) error{}
+
 	walDir := stack.Config().GolemBaseWriteAheadLogDir
 
 	if walDir != "" {
+		onNewBlock = append(onNewBlock, func(block *types.Block, receipts []*types.Receipt) error {
+			return wal.WriteLogForBlock(walDir, block, chainConfig.ChainID, receipts, wal.ParentStateOf(eth.blockchain, block))
+		})
+	}
+
+	eth.golemBaseHistory = stack.Config().GolemBaseHistory
+	eth.golemBaseQueryLimits = query.Limits{
+		MaxSlotsRead: stack.Config().GolemBaseQueryMaxSlots,
+		Timeout:      stack.Config().GolemBaseQueryTimeout,
+	}
+
+	if eth.golemBaseHistory {
+		onNewBlock = append(onNewBlock, func(block *types.Block, receipts []*types.Receipt) error {
+			return history.IndexBlock(chainDb, block, chainConfig.ChainID, receipts, wal.ParentStateOf(eth.blockchain, block))
+		})
+	}
+
+	if metrics.Enabled() {
+		onNewBlock = append(onNewBlock, func(block *types.Block, receipts []*types.Receipt) error {
+			stateDb, err := eth.blockchain.StateAt(block.Root())
+			if err != nil {
+				// metrics are best effort, the state of the block may not be available (e.g. during snap sync)
+				log.Debug("failed to get state for golem base metrics", "block", block.NumberU64(), "error", err)
+				return nil
+			}
+			blockmetrics.UpdateForBlock(block, receipts, stateDb)
+			return nil
+		})
+	}
+
+	if len(onNewBlock) > 0 {
 		eth.blockchain, err = core.NewBlockChainWithOnNewBlock(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, &config.TransactionHistory, func(block *types.Block, receipts []*types.Receipt) error {
-			return wal.WriteLogForBlock(walDir, block, chainConfig.ChainID, receipts)
+			for _, fn := range onNewBlock {
+				err := fn(block, receipts)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
 		})
 	} else {
 		eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, &config.TransactionHistory)
@@ -43,6 +43,7 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/params/forks"
 	"github.com/jeffcogswell/golembase-op-geth/rlp"
 	"github.com/jeffcogswell/golembase-op-geth/rpc"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Register adds the engine API to the full node.
@@ -310,7 +311,10 @@ func (api *ConsensusAPI) ForkchoiceUpdatedWithWitnessV3(update engine.Forkchoice
 	return api.forkchoiceUpdated(update, params, engine.PayloadV3, true)
 }
 
-func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes, payloadVersion engine.PayloadVersion, payloadWitness bool) (engine.ForkChoiceResponse, error) {
+func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes, payloadVersion engine.PayloadVersion, payloadWitness bool) (resp engine.ForkChoiceResponse, err error) {
+	span := startEngineSpan("engine.forkchoiceUpdated", attribute.String("block.hash", update.HeadBlockHash.Hex()))
+	defer func() { endEngineSpan(span, resp.PayloadStatus.Status, err) }()
+
 	api.forkchoiceLock.Lock()
 	defer api.forkchoiceLock.Unlock()
 
@@ -847,7 +851,14 @@ func (api *ConsensusAPI) ExecuteStatelessPayloadV4(params engine.ExecutableData,
 	return api.executeStatelessPayload(params, versionedHashes, beaconRoot, requests, opaqueWitness)
 }
 
-func (api *ConsensusAPI) newPayload(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash, requests [][]byte, witness bool) (engine.PayloadStatusV1, error) {
+func (api *ConsensusAPI) newPayload(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash, requests [][]byte, witness bool) (status engine.PayloadStatusV1, err error) {
+	span := startEngineSpan("engine.newPayload",
+		attribute.Int64("block.number", int64(params.Number)),
+		attribute.String("block.hash", params.BlockHash.Hex()),
+		attribute.Int("block.transactions", len(params.Transactions)),
+	)
+	defer func() { endEngineSpan(span, status.Status, err) }()
+
 	// The locking here is, strictly, not required. Without these locks, this can happen:
 	//
 	// 1. NewPayload( execdata-N ) is invoked from the CL. It goes all the way down to
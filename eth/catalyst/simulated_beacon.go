@@ -92,6 +92,9 @@ type SimulatedBeacon struct {
 	feeRecipient     common.Address
 	feeRecipientLock sync.Mutex // lock gates concurrent access to the feeRecipient
 
+	deposits     []*types.Transaction // deposits force-included in the next block
+	depositsLock sync.Mutex
+
 	engineAPI          *ConsensusAPI
 	curForkchoiceState engine.ForkchoiceStateV1
 	lastBlockTime      uint64
@@ -186,6 +189,20 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 
 	version := payloadVersion(c.eth.BlockChain().Config(), timestamp)
 
+	c.depositsLock.Lock()
+	deposits := c.deposits
+	c.deposits = nil
+	c.depositsLock.Unlock()
+
+	var transactions [][]byte
+	for _, deposit := range deposits {
+		enc, err := deposit.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("failed to encode deposit: %w", err)
+		}
+		transactions = append(transactions, enc)
+	}
+
 	var random [32]byte
 	rand.Read(random[:])
 	fcResponse, err := c.engineAPI.forkchoiceUpdated(c.curForkchoiceState, &engine.PayloadAttributes{
@@ -194,6 +211,7 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 		Withdrawals:           withdrawals,
 		Random:                random,
 		BeaconRoot:            &common.Hash{},
+		Transactions:          transactions,
 	}, version, false)
 	if err != nil {
 		return err
@@ -313,6 +331,19 @@ func (c *SimulatedBeacon) Commit() common.Hash {
 	return c.eth.BlockChain().CurrentBlock().Hash()
 }
 
+// AddDeposit queues a deposit transaction that is force-included at the start of the
+// next block, like the deposits a rollup node derives from L1. It returns the hash of
+// the deposit transaction.
+func (c *SimulatedBeacon) AddDeposit(deposit *types.DepositTx) common.Hash {
+	tx := types.NewTx(deposit)
+
+	c.depositsLock.Lock()
+	c.deposits = append(c.deposits, tx)
+	c.depositsLock.Unlock()
+
+	return tx.Hash()
+}
+
 // Rollback un-sends previously added transactions.
 func (c *SimulatedBeacon) Rollback() {
 	c.eth.TxPool().Clear()
@@ -0,0 +1,27 @@
+package catalyst
+
+import (
+	"context"
+
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startEngineSpan starts the span of an engine API call. The calls of the consensus client
+// do not carry a trace context, so every call is the root of its own trace.
+func startEngineSpan(name string, attrs ...attribute.KeyValue) trace.Span {
+	_, span := telemetry.StartServerSpan(context.Background(), name)
+	if span.IsRecording() {
+		span.SetAttributes(attrs...)
+	}
+	return span
+}
+
+// endEngineSpan records the payload status of the response and ends the span.
+func endEngineSpan(span trace.Span, status string, err error) {
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("engine.status", status))
+	}
+	telemetry.EndSpan(span, err)
+}
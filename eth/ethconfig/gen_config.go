@@ -58,6 +58,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		OverrideOptimismIsthmus                   *uint64 `toml:",omitempty"`
 		OverrideOptimismJovian                    *uint64 `toml:",omitempty"`
 		OverrideOptimismInterop                   *uint64 `toml:",omitempty"`
+		OverrideGolemBaseKaolin                   *uint64 `toml:",omitempty"`
 		ApplySuperchainUpgrades                   bool    `toml:",omitempty"`
 		RollupSequencerHTTP                       string
 		RollupSequencerTxConditionalEnabled       bool
@@ -113,6 +114,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.OverrideOptimismIsthmus = c.OverrideOptimismIsthmus
 	enc.OverrideOptimismJovian = c.OverrideOptimismJovian
 	enc.OverrideOptimismInterop = c.OverrideOptimismInterop
+	enc.OverrideGolemBaseKaolin = c.OverrideGolemBaseKaolin
 	enc.ApplySuperchainUpgrades = c.ApplySuperchainUpgrades
 	enc.RollupSequencerHTTP = c.RollupSequencerHTTP
 	enc.RollupSequencerTxConditionalEnabled = c.RollupSequencerTxConditionalEnabled
@@ -172,6 +174,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		OverrideOptimismIsthmus                   *uint64 `toml:",omitempty"`
 		OverrideOptimismJovian                    *uint64 `toml:",omitempty"`
 		OverrideOptimismInterop                   *uint64 `toml:",omitempty"`
+		OverrideGolemBaseKaolin                   *uint64 `toml:",omitempty"`
 		ApplySuperchainUpgrades                   *bool   `toml:",omitempty"`
 		RollupSequencerHTTP                       *string
 		RollupSequencerTxConditionalEnabled       *bool
@@ -314,6 +317,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.OverrideOptimismInterop != nil {
 		c.OverrideOptimismInterop = dec.OverrideOptimismInterop
 	}
+	if dec.OverrideGolemBaseKaolin != nil {
+		c.OverrideGolemBaseKaolin = dec.OverrideGolemBaseKaolin
+	}
 	if dec.ApplySuperchainUpgrades != nil {
 		c.ApplySuperchainUpgrades = *dec.ApplySuperchainUpgrades
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/common/hexutil"
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
 	"github.com/jeffcogswell/golembase-op-geth/internal/ethapi"
+	"github.com/jeffcogswell/golembase-op-geth/log"
 	"github.com/jeffcogswell/golembase-op-geth/rpc"
 )
 
@@ -228,23 +229,64 @@ func (api *FilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
+//
+// Notifications carry the block number as cursor. A client that resumes the subscription
+// after a cursor first receives the headers it missed, up to maxResumeBlocks back.
 func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
 
+	var (
+		cursor hexutil.Uint64
+		missed []*types.Header
+	)
+	resume, err := notifier.ResumeFrom(&cursor)
+	if err != nil {
+		return nil, err
+	}
+	if resume {
+		if missed, err = api.missedHeads(ctx, uint64(cursor)); err != nil {
+			return nil, err
+		}
+		if len(missed) > 0 {
+			cursor = headCursor(missed[len(missed)-1])
+		}
+	}
+
 	rpcSub := notifier.CreateSubscription()
+	for _, h := range missed {
+		notifier.NotifyWithCursor(rpcSub.ID, h, headCursor(h))
+	}
 
 	go func() {
 		headers := make(chan *types.Header)
 		headersSub := api.events.SubscribeNewHeads(headers)
 		defer headersSub.Unsubscribe()
 
+		// Blocks can be added between the replay and the subscription to the event
+		// system. They are replayed too, and skipped if they are delivered again.
+		replayed := make(map[common.Hash]bool)
+		if resume {
+			missed, err := api.missedHeads(context.Background(), uint64(cursor))
+			if err != nil {
+				log.Warn("Failed to replay missed heads", "from", uint64(cursor), "err", err)
+			}
+			for _, h := range missed {
+				replayed[h.Hash()] = true
+				notifier.NotifyWithCursor(rpcSub.ID, h, headCursor(h))
+			}
+		}
+
 		for {
 			select {
 			case h := <-headers:
-				notifier.Notify(rpcSub.ID, h)
+				if replayed[h.Hash()] {
+					delete(replayed, h.Hash())
+					continue
+				}
+				notifier.NotifyWithCursor(rpcSub.ID, h, headCursor(h))
 			case <-rpcSub.Err():
 				return
 			}
@@ -255,16 +297,38 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
+//
+// Notifications carry the position of the log in the chain as cursor. A client that
+// resumes the subscription after a cursor first receives the matching logs it missed, up
+// to maxResumeBlocks back. Removed logs have no cursor.
 func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
 
+	var (
+		cursor logCursor
+		missed []*types.Log
+		head   uint64
+	)
+	resume, err := notifier.ResumeFrom(&cursor)
+	if err != nil {
+		return nil, err
+	}
+	if resume {
+		if missed, head, err = api.missedLogs(ctx, crit, cursor, uint64(cursor.BlockNumber)); err != nil {
+			return nil, err
+		}
+	}
+
 	var (
 		rpcSub      = notifier.CreateSubscription()
 		matchedLogs = make(chan []*types.Log)
 	)
+	for _, l := range missed {
+		notifier.NotifyWithCursor(rpcSub.ID, l, cursorOfLog(l))
+	}
 
 	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), matchedLogs)
 	if err != nil {
@@ -273,11 +337,33 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 
 	go func() {
 		defer logsSub.Unsubscribe()
+
+		// Blocks can be added between the replay and the subscription to the event
+		// system. Their logs are replayed too, and skipped if they are delivered again.
+		replayed := make(map[logKey]bool)
+		if resume {
+			missed, _, err := api.missedLogs(context.Background(), crit, logCursor{BlockNumber: hexutil.Uint64(head)}, head+1)
+			if err != nil {
+				log.Warn("Failed to replay missed logs", "from", head+1, "err", err)
+			}
+			for _, l := range missed {
+				replayed[logKey{l.BlockHash, l.Index}] = true
+				notifier.NotifyWithCursor(rpcSub.ID, l, cursorOfLog(l))
+			}
+		}
+
 		for {
 			select {
 			case logs := <-matchedLogs:
-				for _, log := range logs {
-					notifier.Notify(rpcSub.ID, &log)
+				for _, l := range logs {
+					switch key := (logKey{l.BlockHash, l.Index}); {
+					case l.Removed:
+						notifier.Notify(rpcSub.ID, l)
+					case replayed[key]:
+						delete(replayed, key)
+					default:
+						notifier.NotifyWithCursor(rpcSub.ID, l, cursorOfLog(l))
+					}
 				}
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				return
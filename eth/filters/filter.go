@@ -307,7 +307,12 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*typ
 	for i, log := range logs {
 		// Copy log not to modify cache elements
 		logcopy := *log
-		logcopy.TxHash = body.Transactions[logcopy.TxIndex].Hash()
+		if int(logcopy.TxIndex) < len(body.Transactions) {
+			logcopy.TxHash = body.Transactions[logcopy.TxIndex].Hash()
+		} else {
+			// the logs of the Golem Base housekeeping receipt follow the ones of the transactions
+			logcopy.TxHash = types.GolemBaseHousekeepingTxHash(header.Number.Uint64())
+		}
 		logs[i] = &logcopy
 	}
 	return logs, nil
@@ -351,6 +351,92 @@ func TestPendingTxFilterFullTx(t *testing.T) {
 	}
 }
 
+// TestResumeSubscriptions tests that resumed newHeads and logs subscriptions replay the
+// notifications after the cursor, and that resuming too far back fails.
+func TestResumeSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{})
+		api    = NewFilterAPI(sys)
+		addr   = common.BytesToAddress([]byte("resume"))
+		gspec  = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, chain, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 6, func(i int, gen *core.BlockGen) {
+		for j := 0; j < 2; j++ {
+			gen.AddUncheckedReceipt(makeReceipt(addr))
+			gen.AddUncheckedTx(types.NewTransaction(uint64(j), common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+		}
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+
+	srv := rpc.NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(srv)
+	defer client.Close()
+
+	// newHeads replays the blocks after block 3.
+	heads := make(chan *types.Header)
+	headsSub, err := client.Subscribe(context.Background(), "eth", heads, "newHeads", map[string]any{"resumeFrom": "0x3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer headsSub.Unsubscribe()
+	for _, block := range chain[3:] {
+		select {
+		case head := <-heads:
+			if head.Hash() != block.Hash() {
+				t.Fatalf("wrong replayed head %d, want %d", head.Number, block.Number())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for head %d", block.Number())
+		}
+	}
+
+	// logs replays the logs after the first log of block 5.
+	logs := make(chan types.Log)
+	crit := map[string]any{"address": addr}
+	cursor := logCursor{BlockNumber: 5, LogIndex: 0}
+	logsSub, err := client.Subscribe(context.Background(), "eth", logs, "logs", crit, map[string]any{"resumeFrom": cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logsSub.Unsubscribe()
+	want := []logCursor{{5, 1}, {6, 0}, {6, 1}}
+	for _, c := range want {
+		select {
+		case l := <-logs:
+			if cursorOfLog(&l) != c || l.BlockHash != chain[c.BlockNumber-1].Hash() {
+				t.Fatalf("wrong replayed log %+v, want %+v", cursorOfLog(&l), c)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for log %+v", c)
+		}
+	}
+
+	// Resuming more than maxResumeBlocks back fails.
+	head := &types.Header{Number: big.NewInt(maxResumeBlocks + 10), Difficulty: common.Big0}
+	rawdb.WriteHeader(db, head)
+	rawdb.WriteCanonicalHash(db, head.Hash(), head.Number.Uint64())
+	rawdb.WriteHeadBlockHash(db, head.Hash())
+	_, err = client.Subscribe(context.Background(), "eth", heads, "newHeads", map[string]any{"resumeFrom": "0x3"})
+	if err == nil || err.Error() != errResumeWindowExceeded.Error() {
+		t.Fatalf("expected resume window error, got %v", err)
+	}
+}
+
 // TestLogFilterCreation test whether a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
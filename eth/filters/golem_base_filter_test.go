@@ -0,0 +1,81 @@
+package filters
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/consensus/ethash"
+	"github.com/jeffcogswell/golembase-op-geth/core"
+	"github.com/jeffcogswell/golembase-op-geth/core/rawdb"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/core/vm"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+)
+
+// TestGolemBaseHousekeepingLogs checks that the logs of the housekeeping receipt of a block
+// are found by filters and attributed to the housekeeping of the block.
+func TestGolemBaseHousekeepingLogs(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.GolemBase = &params.GolemBaseConfig{KaolinTime: new(uint64)}
+
+	var (
+		db        = rawdb.NewMemoryDatabase()
+		_, sys    = newTestFilterSystem(t, db, Config{})
+		key, _    = crypto.GenerateKey()
+		addr      = crypto.PubkeyToAddress(key.PublicKey)
+		processor = address.GolemBaseStorageProcessorAddress
+		gspec     = &core.Genesis{
+			Config:  &config,
+			Alloc:   types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+
+	data, err := rlp.EncodeToBytes(&storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 1, Payload: []byte("hello")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the entity expires in block 2, which has no transactions
+	_, chain, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, gen *core.BlockGen) {
+		if i == 0 {
+			tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: gen.TxNonce(addr), To: &processor, Gas: 1000000, GasPrice: gen.BaseFee(), Data: data})
+			gen.AddTx(tx)
+		}
+	})
+	bc, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+
+	logs, err := sys.NewRangeFilter(0, int64(rpc.LatestBlockNumber), []common.Address{processor}, [][]common.Hash{{storagetx.GolemBaseStorageEntityDeleted}}).Logs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("have %d deletion logs, want 1", len(logs))
+	}
+	l := logs[0]
+	if l.BlockNumber != 2 || l.BlockHash != chain[1].Hash() {
+		t.Errorf("deletion log in block %d (%s), want block 2 (%s)", l.BlockNumber, l.BlockHash.Hex(), chain[1].Hash().Hex())
+	}
+	if l.TxHash != types.GolemBaseHousekeepingTxHash(2) || l.TxIndex != 0 || l.Index != 0 {
+		t.Errorf("deletion log not attributed to housekeeping: tx %s, tx index %d, log index %d", l.TxHash.Hex(), l.TxIndex, l.Index)
+	}
+}
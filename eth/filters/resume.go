@@ -0,0 +1,90 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/common/hexutil"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+)
+
+// maxResumeBlocks is the number of blocks that a resumed subscription can replay.
+const maxResumeBlocks = 1024
+
+var errResumeWindowExceeded = fmt.Errorf("cannot resume a subscription more than %d blocks back", maxResumeBlocks)
+
+// headCursor is the cursor of a newHeads notification, the number of the block.
+func headCursor(header *types.Header) hexutil.Uint64 {
+	return hexutil.Uint64(header.Number.Uint64())
+}
+
+// logCursor is the cursor of a logs notification, the position of the log in the chain.
+type logCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	LogIndex    hexutil.Uint   `json:"logIndex"`
+}
+
+func cursorOfLog(log *types.Log) logCursor {
+	return logCursor{BlockNumber: hexutil.Uint64(log.BlockNumber), LogIndex: hexutil.Uint(log.Index)}
+}
+
+// before reports whether the cursor is before the log.
+func (c logCursor) before(log *types.Log) bool {
+	return log.BlockNumber > uint64(c.BlockNumber) ||
+		log.BlockNumber == uint64(c.BlockNumber) && log.Index > uint(c.LogIndex)
+}
+
+// logKey identifies a log that was replayed.
+type logKey struct {
+	block common.Hash
+	index uint
+}
+
+// missedHeads returns the canonical headers after block from, up to the current head.
+func (api *FilterAPI) missedHeads(ctx context.Context, from uint64) ([]*types.Header, error) {
+	head := api.sys.backend.CurrentHeader().Number.Uint64()
+	if head <= from {
+		return nil, nil
+	}
+	if head-from > maxResumeBlocks {
+		return nil, errResumeWindowExceeded
+	}
+	headers := make([]*types.Header, 0, head-from)
+	for number := from + 1; number <= head; number++ {
+		header, err := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("header %d not found", number)
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// missedLogs returns the logs matching crit that come after the cursor, from block
+// start up to the current head. It also returns the head.
+func (api *FilterAPI) missedLogs(ctx context.Context, crit FilterCriteria, cursor logCursor, start uint64) ([]*types.Log, uint64, error) {
+	head := api.sys.backend.CurrentHeader().Number.Uint64()
+	if head < start {
+		return nil, head, nil
+	}
+	if head-uint64(cursor.BlockNumber) > maxResumeBlocks {
+		return nil, head, errResumeWindowExceeded
+	}
+	filter := api.sys.NewRangeFilter(int64(start), int64(head), crit.Addresses, crit.Topics)
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, head, err
+	}
+	missed := logs[:0]
+	for _, log := range logs {
+		if cursor.before(log) {
+			missed = append(missed, log)
+		}
+	}
+	return missed, head, nil
+}
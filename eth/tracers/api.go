@@ -388,6 +388,10 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 			if api.backend.ChainConfig().IsPrague(next.Number(), next.Time()) {
 				core.ProcessParentBlockHash(next.ParentHash(), evm)
 			}
+			if _, err := core.ProcessGolemBaseHousekeeping(evm); err != nil {
+				failed = err
+				break
+			}
 			// Clean out any pending release functions of trace state. Note this
 			// step must be done after constructing tracing state, because the
 			// tracing state of block next depends on the parent state and construction
@@ -572,6 +576,9 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 	if chainConfig.IsPrague(block.Number(), block.Time()) {
 		core.ProcessParentBlockHash(block.ParentHash(), evm)
 	}
+	if _, err := core.ProcessGolemBaseHousekeeping(evm); err != nil {
+		return nil, err
+	}
 	for i, tx := range block.Transactions() {
 		if err := ctx.Err(); err != nil {
 			return nil, err
@@ -636,6 +643,9 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if api.backend.ChainConfig().IsPrague(block.Number(), block.Time()) {
 		core.ProcessParentBlockHash(block.ParentHash(), evm)
 	}
+	if _, err := core.ProcessGolemBaseHousekeeping(evm); err != nil {
+		return nil, err
+	}
 
 	// JS tracers have high overhead. In this case run a parallel
 	// process that generates states in one thread and traces txes
@@ -813,6 +823,9 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 	if chainConfig.IsPrague(block.Number(), block.Time()) {
 		core.ProcessParentBlockHash(block.ParentHash(), evm)
 	}
+	if _, err := core.ProcessGolemBaseHousekeeping(evm); err != nil {
+		return nil, err
+	}
 	for i, tx := range block.Transactions() {
 		// Prepare the transaction for un-traced execution
 		var (
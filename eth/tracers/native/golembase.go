@@ -0,0 +1,208 @@
+package native
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/tracing"
+	"github.com/jeffcogswell/golembase-op-geth/eth/tracers"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtracing"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("golemBaseTracer", newGolemBaseTracer, false)
+}
+
+// golemBaseTracer reports the operations of Golem Base storage transactions.
+// For every operation it reports the entity key, the failure reason and the storage slots
+// read and written, grouped by the index they belong to.
+// Slots accessed outside of an operation (e.g. the quota checks) are not reported.
+//
+// Example:
+//
+//	> debug.traceTransaction("0x...", {tracer: "golemBaseTracer"})
+//	{
+//	  "txHash": "0x...",
+//	  "sender": "0x...",
+//	  "operations": [{
+//	    "type": "create",
+//	    "index": 0,
+//	    "entityKey": "0x...",
+//	    "indexes": [{
+//	      "index": "allEntities",
+//	      "setKey": "0x...",
+//	      "reads": [{"slot": "0x...", "value": "0x..."}],
+//	      "writes": [{"slot": "0x...", "prev": "0x...", "value": "0x..."}]
+//	    }]
+//	  }]
+//	}
+type golemBaseTracer struct {
+	result    *golemBaseTxTrace
+	current   *golemBaseOperationTrace
+	indexes   []*golemBaseIndexTrace // stack of the indexes entered by the current operation
+	interrupt atomic.Bool
+	reason    error
+}
+
+type golemBaseTxTrace struct {
+	TxHash     common.Hash                `json:"txHash"`
+	Sender     common.Address             `json:"sender"`
+	Error      string                     `json:"error,omitempty"`
+	Operations []*golemBaseOperationTrace `json:"operations"`
+}
+
+type golemBaseOperationTrace struct {
+	Type      string                 `json:"type"`
+	Index     int                    `json:"index"`
+	EntityKey common.Hash            `json:"entityKey"`
+	Error     string                 `json:"error,omitempty"`
+	Indexes   []*golemBaseIndexTrace `json:"indexes"`
+}
+
+type golemBaseIndexTrace struct {
+	Index  string               `json:"index"`
+	SetKey common.Hash          `json:"setKey"`
+	Reads  []golemBaseSlotRead  `json:"reads,omitempty"`
+	Writes []golemBaseSlotWrite `json:"writes,omitempty"`
+}
+
+type golemBaseSlotRead struct {
+	Slot  common.Hash `json:"slot"`
+	Value common.Hash `json:"value"`
+}
+
+type golemBaseSlotWrite struct {
+	Slot  common.Hash `json:"slot"`
+	Prev  common.Hash `json:"prev"`
+	Value common.Hash `json:"value"`
+}
+
+// newGolemBaseTracer returns a native go tracer which reports the
+// operations of Golem Base storage transactions.
+func newGolemBaseTracer(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params.ChainConfig) (*tracers.Tracer, error) {
+	t := &golemBaseTracer{}
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			GolemBase: &golemtracing.Hooks{
+				OnStorageTxStart: t.OnStorageTxStart,
+				OnStorageTxEnd:   t.OnStorageTxEnd,
+				OnOperationStart: t.OnOperationStart,
+				OnOperationEnd:   t.OnOperationEnd,
+				OnIndexEnter:     t.OnIndexEnter,
+				OnIndexExit:      t.OnIndexExit,
+				OnSlotRead:       t.OnSlotRead,
+				OnSlotWrite:      t.OnSlotWrite,
+			},
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func (t *golemBaseTracer) OnStorageTxStart(txHash common.Hash, sender common.Address, data []byte) {
+	if t.interrupt.Load() {
+		return
+	}
+	t.result = &golemBaseTxTrace{
+		TxHash:     txHash,
+		Sender:     sender,
+		Operations: []*golemBaseOperationTrace{},
+	}
+}
+
+func (t *golemBaseTracer) OnStorageTxEnd(err error) {
+	if t.result == nil || err == nil {
+		return
+	}
+	t.result.Error = err.Error()
+}
+
+func (t *golemBaseTracer) OnOperationStart(op golemtracing.Operation) {
+	if t.interrupt.Load() || t.result == nil {
+		return
+	}
+	t.current = &golemBaseOperationTrace{
+		Type:    op.Type,
+		Index:   op.Index,
+		Indexes: []*golemBaseIndexTrace{},
+	}
+	t.indexes = t.indexes[:0]
+	t.result.Operations = append(t.result.Operations, t.current)
+}
+
+func (t *golemBaseTracer) OnOperationEnd(op golemtracing.Operation, err error) {
+	if t.current == nil {
+		return
+	}
+	// the key of a create operation is only known at the end
+	t.current.EntityKey = op.EntityKey
+	if err != nil {
+		t.current.Error = err.Error()
+	}
+	t.current = nil
+	t.indexes = t.indexes[:0]
+}
+
+func (t *golemBaseTracer) OnIndexEnter(index string, setKey common.Hash) {
+	if t.current == nil {
+		return
+	}
+	it := &golemBaseIndexTrace{
+		Index:  index,
+		SetKey: setKey,
+	}
+	t.current.Indexes = append(t.current.Indexes, it)
+	t.indexes = append(t.indexes, it)
+}
+
+func (t *golemBaseTracer) OnIndexExit() {
+	if len(t.indexes) > 0 {
+		t.indexes = t.indexes[:len(t.indexes)-1]
+	}
+}
+
+// index returns the index the slots accessed now belong to.
+// Slots accessed by an operation outside of any index are reported under an empty index name.
+func (t *golemBaseTracer) index() *golemBaseIndexTrace {
+	if len(t.indexes) > 0 {
+		return t.indexes[len(t.indexes)-1]
+	}
+	it := &golemBaseIndexTrace{}
+	t.current.Indexes = append(t.current.Indexes, it)
+	t.indexes = append(t.indexes, it)
+	return it
+}
+
+func (t *golemBaseTracer) OnSlotRead(slot common.Hash, value common.Hash) {
+	if t.current == nil {
+		return
+	}
+	it := t.index()
+	it.Reads = append(it.Reads, golemBaseSlotRead{Slot: slot, Value: value})
+}
+
+func (t *golemBaseTracer) OnSlotWrite(slot common.Hash, prev common.Hash, value common.Hash) {
+	if t.current == nil {
+		return
+	}
+	it := t.index()
+	it.Writes = append(it.Writes, golemBaseSlotWrite{Slot: slot, Prev: prev, Value: value})
+}
+
+// GetResult returns the json-encoded trace of the storage transaction, or null if
+// the transaction is not a storage transaction.
+func (t *golemBaseTracer) GetResult() (json.RawMessage, error) {
+	res, err := json.Marshal(t.result)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *golemBaseTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
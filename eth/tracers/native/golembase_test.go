@@ -0,0 +1,98 @@
+package native_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/eth/tracers"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+type golemBaseTrace struct {
+	Sender     common.Address `json:"sender"`
+	Error      string         `json:"error"`
+	Operations []struct {
+		Type      string      `json:"type"`
+		Index     int         `json:"index"`
+		EntityKey common.Hash `json:"entityKey"`
+		Error     string      `json:"error"`
+		Indexes   []struct {
+			Index  string `json:"index"`
+			Writes []struct {
+				Slot common.Hash `json:"slot"`
+			} `json:"writes"`
+		} `json:"indexes"`
+	} `json:"operations"`
+}
+
+func traceStorageTx(t *testing.T, db *state.StateDB, sender common.Address, txHash common.Hash, tx *storagetx.StorageTransaction) ([]*types.Log, golemBaseTrace) {
+	tracer, err := tracers.DefaultDirectory.New("golemBaseTracer", &tracers.Context{}, nil, params.MainnetChainConfig)
+	require.NoError(t, err)
+
+	d, err := rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+
+	logs, _ := storagetx.ExecuteTransaction(d, params.GolemBaseRules{IsKaolin: true}, 1, txHash, sender, db, ownerusage.Quota{}, tracer.Hooks.GolemBase)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+
+	trace := golemBaseTrace{}
+	require.NoError(t, json.Unmarshal(res, &trace))
+	return logs, trace
+}
+
+func TestGolemBaseTracer(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	sender := common.HexToAddress("0x1234")
+
+	logs, trace := traceStorageTx(t, db, sender, common.HexToHash("0x1"), &storagetx.StorageTransaction{
+		Create: []storagetx.Create{{
+			TTL:               100,
+			Payload:           []byte("hello"),
+			StringAnnotations: []entity.StringAnnotation{{Key: "k", Value: "v"}},
+		}},
+	})
+
+	require.Empty(t, trace.Error)
+	require.Equal(t, sender, trace.Sender)
+	require.Len(t, trace.Operations, 1)
+
+	op := trace.Operations[0]
+	require.Equal(t, "create", op.Type)
+	require.Equal(t, logs[0].Topics[1], op.EntityKey)
+	require.Empty(t, op.Error)
+
+	indexes := map[string]int{}
+	for _, it := range op.Indexes {
+		indexes[it.Index] += len(it.Writes)
+	}
+	require.Positive(t, indexes["allEntities"])
+	require.Positive(t, indexes["stringAnnotation:k=v"])
+	require.Positive(t, indexes["payload"])
+	require.Positive(t, indexes["metadata"])
+
+	// the second operation fails, the transaction is reverted
+	_, trace = traceStorageTx(t, db, sender, common.HexToHash("0x2"), &storagetx.StorageTransaction{
+		Extend: []storagetx.ExtendTTL{
+			{EntityKey: logs[0].Topics[1], NumberOfBlocks: 10},
+			{EntityKey: common.HexToHash("0xdead"), NumberOfBlocks: 10},
+		},
+	})
+
+	require.NotEmpty(t, trace.Error)
+	require.Len(t, trace.Operations, 2)
+	require.Empty(t, trace.Operations[0].Error)
+	require.NotEmpty(t, trace.Operations[1].Error)
+	require.Equal(t, common.HexToHash("0xdead"), trace.Operations[1].EntityKey)
+}
@@ -0,0 +1,32 @@
+// Package blockmetrics updates the Golem Base metrics after every new head block.
+//
+// The metrics are registered in the default registry of the metrics package,
+// so they are served on the --metrics endpoint of the node.
+package blockmetrics
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
+)
+
+func newBlockHistogram(name string) metrics.Histogram {
+	return metrics.NewRegisteredHistogram(name, nil, metrics.NewExpDecaySample(1028, 0.015))
+}
+
+var (
+	entitiesGauge     = metrics.NewRegisteredGauge("golembase/entities", nil)
+	payloadBytesGauge = metrics.NewRegisteredGauge("golembase/payload/bytes", nil)
+	annotationsGauge  = metrics.NewRegisteredGauge("golembase/annotations", nil)
+	backlogGauge      = metrics.NewRegisteredGauge("golembase/housekeeping/backlog", nil)
+
+	createsCounter     = metrics.NewRegisteredCounter("golembase/creates", nil)
+	updatesCounter     = metrics.NewRegisteredCounter("golembase/updates", nil)
+	deletesCounter     = metrics.NewRegisteredCounter("golembase/deletes", nil)
+	extendsCounter     = metrics.NewRegisteredCounter("golembase/extends", nil)
+	expirationsCounter = metrics.NewRegisteredCounter("golembase/housekeeping/expirations", nil)
+
+	blockCreatesHist     = newBlockHistogram("golembase/block/creates")
+	blockUpdatesHist     = newBlockHistogram("golembase/block/updates")
+	blockDeletesHist     = newBlockHistogram("golembase/block/deletes")
+	blockExtendsHist     = newBlockHistogram("golembase/block/extends")
+	blockExpirationsHist = newBlockHistogram("golembase/block/expirations")
+)
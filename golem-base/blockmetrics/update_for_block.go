@@ -0,0 +1,107 @@
+package blockmetrics
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/housekeepingtx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
+)
+
+// operationCounts is the number of operations applied in a block.
+type operationCounts struct {
+	creates     int64
+	updates     int64
+	deletes     int64
+	extends     int64
+	expirations int64
+}
+
+// countOperations counts the operations of the block from the logs of its receipts.
+// Entities deleted by housekeeping are counted as expirations, whether they are logged in the
+// housekeeping receipt of the block or, before Kaolin, in deposit transactions, see housekeepingtx.SplitLogs.
+// The operations of failed transactions are not counted.
+func countOperations(block *types.Block, receipts []*types.Receipt) operationCounts {
+	counts := operationCounts{}
+
+	txs := block.Transactions()
+
+	countExpirations := func(logs []*types.Log) {
+		for _, l := range logs {
+			if len(l.Topics) != 0 && l.Topics[0] == storagetx.GolemBaseStorageEntityDeleted {
+				counts.expirations++
+			}
+		}
+	}
+
+	if housekeeping := types.GolemBaseHousekeepingReceipt(txs, receipts); housekeeping != nil {
+		countExpirations(housekeeping.Logs)
+	}
+
+	for i, receipt := range receipts {
+		if i >= len(txs) {
+			break
+		}
+
+		housekeeping, ownLogs := housekeepingtx.SplitLogs(txs[i], receipt)
+		countExpirations(housekeeping)
+
+		if receipt.Status == types.ReceiptStatusFailed {
+			continue
+		}
+
+		for _, l := range ownLogs {
+			if l.Address != address.GolemBaseStorageProcessorAddress || len(l.Topics) == 0 {
+				continue
+			}
+
+			switch l.Topics[0] {
+			case storagetx.GolemBaseStorageEntityCreated:
+				counts.creates++
+			case storagetx.GolemBaseStorageEntityUpdated:
+				counts.updates++
+			case storagetx.GolemBaseStorageEntityDeleted:
+				counts.deletes++
+			case storagetx.GolemBaseStorageEntityTTLExtended:
+				counts.extends++
+			}
+		}
+	}
+
+	return counts
+}
+
+// UpdateForBlock updates the metrics after the block became the new head.
+// access is the state after the block.
+func UpdateForBlock(block *types.Block, receipts []*types.Receipt, access storageutil.StateAccess) {
+	if !metrics.Enabled() {
+		return
+	}
+
+	counts := countOperations(block, receipts)
+
+	createsCounter.Inc(counts.creates)
+	updatesCounter.Inc(counts.updates)
+	deletesCounter.Inc(counts.deletes)
+	extendsCounter.Inc(counts.extends)
+	expirationsCounter.Inc(counts.expirations)
+
+	blockCreatesHist.Update(counts.creates)
+	blockUpdatesHist.Update(counts.updates)
+	blockDeletesHist.Update(counts.deletes)
+	blockExtendsHist.Update(counts.extends)
+	blockExpirationsHist.Update(counts.expirations)
+
+	backlog := entityexpiration.BacklogSize(access)
+	total := ownerusage.Total(access)
+
+	entitiesGauge.Update(int64(keyset.Size(access, allentities.AllEntitiesKey).Uint64() - backlog))
+	payloadBytesGauge.Update(int64(total.PayloadBytes))
+	annotationsGauge.Update(int64(total.Annotations))
+	backlogGauge.Update(int64(backlog))
+}
@@ -0,0 +1,81 @@
+package blockmetrics
+
+import (
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountOperations(t *testing.T) {
+	storageLog := func(topic common.Hash) *types.Log {
+		return &types.Log{
+			Address: address.GolemBaseStorageProcessorAddress,
+			Topics:  []common.Hash{topic, common.HexToHash("0x1")},
+		}
+	}
+
+	processor := address.GolemBaseStorageProcessorAddress
+	other := common.HexToAddress("0x1234")
+
+	storageTx := func(stx *storagetx.StorageTransaction) *types.Transaction {
+		data, err := rlp.EncodeToBytes(stx)
+		require.NoError(t, err)
+		return types.NewTx(&types.DynamicFeeTx{To: &processor, Data: data})
+	}
+
+	block := types.NewBlockWithHeader(&types.Header{}).WithBody(types.Body{
+		Transactions: []*types.Transaction{
+			storageTx(&storagetx.StorageTransaction{
+				Create: []storagetx.Create{{}},
+				Update: []storagetx.Update{{}},
+				Delete: []common.Hash{{}},
+				Extend: []storagetx.ExtendTTL{{}},
+			}),
+			types.NewTx(&types.DynamicFeeTx{To: &other}),
+			storageTx(&storagetx.StorageTransaction{Create: []storagetx.Create{{}}}),
+		},
+	})
+
+	receipts := []*types.Receipt{
+		{
+			Status: types.ReceiptStatusSuccessful,
+			Logs: []*types.Log{
+				storageLog(storagetx.GolemBaseStorageEntityCreated),
+				storageLog(storagetx.GolemBaseStorageEntityUpdated),
+				storageLog(storagetx.GolemBaseStorageEntityDeleted),
+				storageLog(storagetx.GolemBaseStorageEntityTTLExtended),
+			},
+		},
+		{
+			// before Kaolin, housekeeping runs in deposit transactions
+			Status: types.ReceiptStatusSuccessful,
+			Logs: []*types.Log{
+				storageLog(storagetx.GolemBaseStorageEntityDeleted),
+				{Address: other, Topics: []common.Hash{storagetx.GolemBaseStorageEntityCreated}},
+			},
+		},
+		{
+			Status: types.ReceiptStatusFailed,
+		},
+		// the housekeeping receipt of the block follows the ones of the transactions
+		types.NewGolemBaseHousekeepingReceipt([]*types.Log{
+			storageLog(storagetx.GolemBaseStorageEntityDeleted),
+			storageLog(storagetx.GolemBaseStorageEntityDeleted),
+			storageLog(storagetx.GolemBaseStorageUploadAbandoned),
+		}),
+	}
+
+	counts := countOperations(block, receipts)
+	require.Equal(t, operationCounts{
+		creates:     1,
+		updates:     1,
+		deletes:     1,
+		extends:     1,
+		expirations: 3,
+	}, counts)
+}
@@ -2,14 +2,13 @@ package golembase_test
 
 import (
 	"bytes"
+	"errors"
 	"context"
 	"fmt"
-	"log"
 	"math/big"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -18,13 +17,18 @@ import (
 	"github.com/alecthomas/repr"
 	"github.com/cucumber/godog"
 	"github.com/cucumber/godog/colors"
+	"github.com/jeffcogswell/golembase-op-geth"
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/entityproof"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtype"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/history"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/testutil"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/wal"
-	"github.com/jeffcogswell/golembase-op-geth/rpc"
 	"github.com/holiman/uint256"
 	"github.com/spf13/pflag" // godog v0.11.0 and later
 	"github.com/warpfork/go-wish/difflib"
@@ -49,44 +53,17 @@ func init() {
 	}
 }
 
-func compileGeth() (string, func(), error) {
-	td, err := os.MkdirTemp("", "golem-base")
-	if err != nil {
-		panic(fmt.Errorf("failed to create temp dir: %w", err))
-	}
-
-	gethBinaryPath := filepath.Join(td, "geth")
-
-	cmd := exec.Command("go", "build", "-o", gethBinaryPath, "../cmd/geth")
-	out := &bytes.Buffer{}
-	cmd.Stdout = out
-	cmd.Stderr = out
-	err = cmd.Run()
-	if err != nil {
-		return "", func() {}, fmt.Errorf("failed to compile geth: %w\n%s", err, out.String())
-	}
-
-	return gethBinaryPath, func() {
-		os.RemoveAll(td)
-	}, nil
-}
-
 func TestMain(m *testing.M) {
 	pflag.Parse()
 	opts.Paths = pflag.Args()
 
-	gethPath, cleanupCompiled, err := compileGeth()
-	if err != nil {
-		log.Fatal(fmt.Errorf("failed to compile geth: %w", err))
-	}
-
 	suite := godog.TestSuite{
 		Name: "cucumber",
 		ScenarioInitializer: func(sctx *godog.ScenarioContext) {
 			InitializeScenario(sctx)
 			sctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
 
-				world, err := testutil.NewWorld(ctx, gethPath)
+				world, err := testutil.NewWorld(ctx)
 				if err != nil {
 					return ctx, fmt.Errorf("failed to start geth instance: %w", err)
 				}
@@ -115,7 +92,6 @@ func TestMain(m *testing.M) {
 	// 	status = st
 	// }
 
-	cleanupCompiled()
 
 	os.Exit(status)
 }
@@ -133,6 +109,7 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^I search for entities with the string annotation "([^"]*)" equal to "([^"]*)"$`, iSearchForEntitiesWithTheStringAnnotationEqualTo)
 	ctx.Step(`^I should find (\d+) entit(y|ies)$`, iShouldFindEntity)
 	ctx.Step(`^I have an entity "([^"]*)" with numeric annotations:$`, iHaveAnEntityWithNumericAnnotations)
+	ctx.Step(`^I have an entity "([^"]*)" with tags "([^"]*)":$`, iHaveAnEntityWithTags)
 	ctx.Step(`^I search for entities with the numeric annotation "([^"]*)" equal to "([^"]*)"$`, iSearchForEntitiesWithTheNumericAnnotationEqualTo)
 	ctx.Step(`^I have created an entity$`, iHaveCreatedAnEntity)
 	ctx.Step(`^I submit a transaction to delete the entity$`, iSubmitATransactionToDeleteTheEntity)
@@ -145,9 +122,11 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^the ttl of the entity should be changed$`, theTtlOfTheEntityShouldBeChanged)
 	ctx.Step(`^submit a transaction to create an entity of (\d+)K$`, submitATransactionToCreateAnEntityOfK)
 	ctx.Step(`^the entity creation should not fail$`, theEntityCreationShouldNotFail)
+	ctx.Step(`^I upload an entity of (\d+)K in chunks of (\d+)K$`, iUploadAnEntityOfKInChunksOfK)
+	ctx.Step(`^the payload of the uploaded entity should be (\d+)K$`, thePayloadOfTheUploadedEntityShouldBeK)
+	ctx.Step(`^the write-ahead log should contain the uploaded entity of (\d+)K$`, theWriteaheadLogShouldContainTheUploadedEntity)
 	ctx.Step(`^I search for entities with the query$`, iSearchForEntitiesWithTheQuery)
-	ctx.Step(`^the housekeeping transaction should be submitted$`, theHousekeepingTransactionShouldBeSubmitted)
-	ctx.Step(`^the housekeeping transaction should be successful$`, theHousekeepingTransactionShouldBeSuccessful)
+	ctx.Step(`^the deletion of the expired entity should be returned by eth_getLogs$`, theDeletionOfTheExpiredEntityShouldBeReturnedByGetLogs)
 	ctx.Step(`^there is a new block$`, thereIsANewBlock)
 	ctx.Step(`^the expired entity should be deleted$`, theExpiredEntityShouldBeDeleted)
 	ctx.Step(`^there is an entity that will expire in the next block$`, thereIsAnEntityThatWillExpireInTheNextBlock)
@@ -164,6 +143,24 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^the owner should not have any entities$`, theOwnerShouldNotHaveAnyEntities)
 	ctx.Step(`^I submit a transaction to extend TTL of the entity by (\d+) blocks$`, iSubmitATransactionToExtendTTLOfTheEntityByBlocks)
 	ctx.Step(`^the entity\'s TTL should be extended by (\d+) blocks$`, theEntitysTTLShouldBeExtendedByBlocks)
+	ctx.Step(`^I create an entity named "([^"]*)" in the namespace "([^"]*)"$`, iCreateAnEntityNamedInTheNamespace)
+	ctx.Step(`^I have created an entity named "([^"]*)" in the namespace "([^"]*)"$`, iHaveCreatedAnEntityNamedInTheNamespace)
+	ctx.Step(`^the entity should have the key derived from the name "([^"]*)" in the namespace "([^"]*)"$`, theEntityShouldHaveTheKeyDerivedFromTheNameInTheNamespace)
+	ctx.Step(`^I retrieve the history of the entity$`, iRetrieveTheHistoryOfTheEntity)
+	ctx.Step(`^the history should contain the operations "([^"]*)"$`, theHistoryShouldContainTheOperations)
+	ctx.Step(`^the payload of revision (\d+) should be "([^"]*)"$`, thePayloadOfRevisionShouldBe)
+	ctx.Step(`^I should be able to resolve the name "([^"]*)" in the namespace "([^"]*)" to the entity$`, iShouldBeAbleToResolveTheNameInTheNamespaceToTheEntity)
+	ctx.Step(`^I list the entities expiring in the next (\d+) blocks with a page size of (\d+)$`, iListTheEntitiesExpiringInTheNextBlocksWithAPageSizeOf)
+	ctx.Step(`^I list the entities of another owner expiring in the next (\d+) blocks$`, iListTheEntitiesOfAnotherOwnerExpiringInTheNextBlocks)
+	ctx.Step(`^I should get (\d+) expiring entities in (\d+) pages$`, iShouldGetExpiringEntitiesInPages)
+	ctx.Step(`^the usage of the owner should be (\d+) entities, (\d+) payload bytes and (\d+) annotations$`, theUsageOfTheOwnerShouldBeEntitiesPayloadBytesAndAnnotations)
+	ctx.Step(`^I deposit a transaction from L1 to create an entity$`, iDepositATransactionFromL1ToCreateAnEntity)
+	ctx.Step(`^I deposit a transaction from L1 to create an entity named "([^"]*)" in the namespace "([^"]*)"$`, iDepositATransactionFromL1ToCreateAnEntityNamedInTheNamespace)
+	ctx.Step(`^I deposit a transaction from L1 to update the entity, changing the payload$`, iDepositATransactionFromL1ToUpdateTheEntityChangingThePayload)
+	ctx.Step(`^I deposit a transaction from L1 to delete the entity$`, iDepositATransactionFromL1ToDeleteTheEntity)
+	ctx.Step(`^I request a proof of the entity$`, iRequestAProofOfTheEntity)
+	ctx.Step(`^the proof should verify against the state root of the block$`, theProofShouldVerifyAgainstTheStateRootOfTheBlock)
+	ctx.Step(`^the proof should show that the entity does not exist$`, theProofShouldShowThatTheEntityDoesNotExist)
 
 }
 
@@ -440,6 +437,31 @@ func iHaveAnEntityWithStringAnnotations(ctx context.Context, payload string, ann
 	return nil
 }
 
+func iHaveAnEntityWithTags(ctx context.Context, payload, key string, tagsTable *godog.Table) error {
+	w := testutil.GetWorld(ctx)
+
+	tag := entity.TagAnnotation{
+		Key: key,
+	}
+
+	for _, row := range tagsTable.Rows {
+		tag.Values = append(tag.Values, row.Cells[0].Value)
+	}
+
+	_, err := w.CreateTaggedEntity(
+		ctx,
+		100,
+		[]byte(payload),
+		[]entity.TagAnnotation{tag},
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create entity: %w", err)
+	}
+
+	return nil
+}
+
 func iSearchForEntitiesWithTheStringAnnotationEqualTo(ctx context.Context, key, value string) error {
 	w := testutil.GetWorld(ctx)
 
@@ -809,6 +831,70 @@ func theEntityCreationShouldNotFail(ctx context.Context) error {
 	return nil
 }
 
+func uploadPayload(kilobytes int) []byte {
+	payload := make([]byte, 1024*kilobytes)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	return payload
+}
+
+func iUploadAnEntityOfKInChunksOfK(ctx context.Context, kilobytes, chunkKilobytes int) error {
+	w := testutil.GetWorld(ctx)
+
+	_, err := w.UploadEntity(ctx, 200, uploadPayload(kilobytes), 1024*chunkKilobytes)
+	if err != nil {
+		return fmt.Errorf("failed to upload entity: %w", err)
+	}
+
+	return nil
+}
+
+func thePayloadOfTheUploadedEntityShouldBeK(ctx context.Context, kilobytes int) error {
+	w := testutil.GetWorld(ctx)
+
+	var v []byte
+	err := w.GethInstance.RPCClient.CallContext(
+		ctx,
+		&v,
+		"golembase_getStorageValue",
+		w.CreatedEntityKey.Hex(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get storage value: %w", err)
+	}
+
+	if !bytes.Equal(v, uploadPayload(kilobytes)) {
+		return fmt.Errorf("unexpected payload of %d bytes", len(v))
+	}
+
+	return nil
+}
+
+func theWriteaheadLogShouldContainTheUploadedEntity(ctx context.Context, kilobytes int) error {
+	w := testutil.GetWorld(ctx)
+
+	wl, err := w.ReadWAL(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read write-ahead log: %w", err)
+	}
+
+	if len(wl) != 1 || wl[0].Create == nil {
+		return fmt.Errorf("expected a single create in the write-ahead log, got %d operations", len(wl))
+	}
+
+	create := wl[0].Create
+	if create.EntityKey != w.CreatedEntityKey || create.Owner != w.FundedAccount.Address {
+		return fmt.Errorf("unexpected create of entity %s by %s", create.EntityKey.Hex(), create.Owner.Hex())
+	}
+
+	if !bytes.Equal(create.Payload, uploadPayload(kilobytes)) {
+		return fmt.Errorf("unexpected payload of %d bytes in the write-ahead log", len(create.Payload))
+	}
+
+	return nil
+}
+
 func iSearchForEntitiesWithTheQuery(ctx context.Context, queryDoc *godog.DocString) error {
 	w := testutil.GetWorld(ctx)
 
@@ -831,27 +917,38 @@ func iSearchForEntitiesWithTheQuery(ctx context.Context, queryDoc *godog.DocStri
 	return nil
 }
 
-func theHousekeepingTransactionShouldBeSubmitted(ctx context.Context) error {
+// latestHousekeepingLogs returns the logs of the housekeeping of the latest block,
+// which make up the housekeeping receipt of the block, after the receipts of its transactions.
+func latestHousekeepingLogs(ctx context.Context) ([]*types.Log, error) {
 	w := testutil.GetWorld(ctx)
 	ec := w.GethInstance.ETHClient
 
 	lastBlock, err := ec.BlockByNumber(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get last block: %w", err)
+		return nil, fmt.Errorf("failed to get last block: %w", err)
 	}
 
-	firstTx := lastBlock.Transactions()[0]
+	logs, err := ec.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: lastBlock.Number(),
+		ToBlock:   lastBlock.Number(),
+		Addresses: []common.Address{address.GolemBaseStorageProcessorAddress},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
 
-	if firstTx.Type() != types.DepositTxType {
-		return fmt.Errorf("expected deposit transaction but got %d", firstTx.Type())
+	housekeeping := []*types.Log{}
+	for i := range logs {
+		if logs[i].TxHash == types.GolemBaseHousekeepingTxHash(lastBlock.NumberU64()) {
+			housekeeping = append(housekeeping, &logs[i])
+		}
 	}
 
-	return nil
+	return housekeeping, nil
 }
 
-func theHousekeepingTransactionShouldBeSuccessful(ctx context.Context) error {
+func theDeletionOfTheExpiredEntityShouldBeReturnedByGetLogs(ctx context.Context) error {
 	w := testutil.GetWorld(ctx)
-
 	ec := w.GethInstance.ETHClient
 
 	lastBlock, err := ec.BlockByNumber(ctx, nil)
@@ -859,19 +956,22 @@ func theHousekeepingTransactionShouldBeSuccessful(ctx context.Context) error {
 		return fmt.Errorf("failed to get last block: %w", err)
 	}
 
-	h := lastBlock.Hash()
-
-	receipts, err := ec.BlockReceipts(ctx, rpc.BlockNumberOrHash{
-		BlockHash: &h,
+	logs, err := ec.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: lastBlock.Number(),
+		ToBlock:   lastBlock.Number(),
+		Addresses: []common.Address{address.GolemBaseStorageProcessorAddress},
+		Topics:    [][]common.Hash{{storagetx.GolemBaseStorageEntityDeleted}, {w.CreatedEntityKey}},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to get receipts: %w", err)
+		return fmt.Errorf("failed to get logs: %w", err)
 	}
 
-	firstTx := receipts[0]
+	if len(logs) != 1 {
+		return fmt.Errorf("expected 1 deletion log but got %d", len(logs))
+	}
 
-	if firstTx.Status == types.ReceiptStatusFailed {
-		return fmt.Errorf("tx has failed")
+	if logs[0].TxHash != types.GolemBaseHousekeepingTxHash(lastBlock.NumberU64()) || logs[0].TxIndex != uint(len(lastBlock.Transactions())) {
+		return fmt.Errorf("expected the deletion to be part of the housekeeping receipt, got a log of transaction %d (%s)", logs[0].TxIndex, logs[0].TxHash.Hex())
 	}
 
 	return nil
@@ -932,33 +1032,16 @@ func thereIsAnEntityThatWillExpireInTheNextBlock(ctx context.Context) error {
 func theExpiredEntityShouldBeDeleted(ctx context.Context) error {
 	w := testutil.GetWorld(ctx)
 
-	ec := w.GethInstance.ETHClient
-
-	lastBlock, err := ec.BlockByNumber(ctx, nil)
+	logs, err := latestHousekeepingLogs(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get last block: %w", err)
+		return err
 	}
 
-	h := lastBlock.Hash()
-
-	receipts, err := ec.BlockReceipts(ctx, rpc.BlockNumberOrHash{
-		BlockHash: &h,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to get receipts: %w", err)
+	if len(logs) == 0 {
+		return fmt.Errorf("no housekeeping logs found in the block")
 	}
 
-	firstTx := receipts[0]
-
-	if firstTx.Status == types.ReceiptStatusFailed {
-		return fmt.Errorf("housekeeping tx has failed")
-	}
-
-	if len(firstTx.Logs) == 0 {
-		return fmt.Errorf("no logs found in housekeeping tx")
-	}
-
-	key := firstTx.Logs[0].Topics[1]
+	key := logs[0].Topics[1]
 
 	if key != w.CreatedEntityKey {
 		return fmt.Errorf("expected entity to be deleted but got %s", key.Hex())
@@ -1244,3 +1327,402 @@ func theEntitysTTLShouldBeExtendedByBlocks(ctx context.Context, numberOfBlocks i
 
 	return nil
 }
+
+func iCreateAnEntityNamedInTheNamespace(ctx context.Context, name, namespace string) error {
+	w := testutil.GetWorld(ctx)
+
+	receipt, err := w.CreateNamedEntity(
+		ctx,
+		namespace,
+		name,
+		100,
+		[]byte("test payload"),
+		[]entity.StringAnnotation{},
+		[]entity.NumericAnnotation{},
+	)
+
+	w.LastError = err
+
+	if err != nil {
+		return nil
+	}
+
+	w.CreatedEntityKey = receipt.Logs[0].Topics[1]
+
+	return nil
+}
+
+func iHaveCreatedAnEntityNamedInTheNamespace(ctx context.Context, name, namespace string) error {
+	w := testutil.GetWorld(ctx)
+
+	err := iCreateAnEntityNamedInTheNamespace(ctx, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	if w.LastError != nil {
+		return fmt.Errorf("failed to create named entity: %w", w.LastError)
+	}
+
+	return nil
+}
+
+func theEntityShouldHaveTheKeyDerivedFromTheNameInTheNamespace(ctx context.Context, name, namespace string) error {
+	w := testutil.GetWorld(ctx)
+
+	if w.LastError != nil {
+		return fmt.Errorf("failed to create named entity: %w", w.LastError)
+	}
+
+	var expected common.Hash
+	err := w.GethInstance.RPCClient.CallContext(
+		ctx,
+		&expected,
+		"golembase_getEntityKeyForName",
+		w.FundedAccount.Address,
+		namespace,
+		name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get entity key for name: %w", err)
+	}
+
+	if expected != entity.NamedEntityKey(w.FundedAccount.Address, namespace, name) {
+		return fmt.Errorf("unexpected key derived by the node: %s", expected.Hex())
+	}
+
+	if w.CreatedEntityKey != expected {
+		return fmt.Errorf("expected entity key to be %s, but got %s", expected.Hex(), w.CreatedEntityKey.Hex())
+	}
+
+	return nil
+}
+
+func iShouldBeAbleToResolveTheNameInTheNamespaceToTheEntity(ctx context.Context, name, namespace string) error {
+	w := testutil.GetWorld(ctx)
+
+	var key common.Hash
+	err := w.GethInstance.RPCClient.CallContext(
+		ctx,
+		&key,
+		"golembase_resolveEntityName",
+		w.FundedAccount.Address,
+		namespace,
+		name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to resolve entity name: %w", err)
+	}
+
+	if key != w.CreatedEntityKey {
+		return fmt.Errorf("expected entity key to be %s, but got %s", w.CreatedEntityKey.Hex(), key.Hex())
+	}
+
+	return nil
+}
+
+func iRetrieveTheHistoryOfTheEntity(ctx context.Context) error {
+	w := testutil.GetWorld(ctx)
+
+	revisions := []history.Revision{}
+	err := w.GethInstance.RPCClient.CallContext(
+		ctx,
+		&revisions,
+		"golembase_getEntityHistory",
+		w.CreatedEntityKey,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get entity history: %w", err)
+	}
+
+	w.EntityHistory = revisions
+
+	return nil
+}
+
+func theHistoryShouldContainTheOperations(ctx context.Context, operations string) error {
+	w := testutil.GetWorld(ctx)
+
+	actual := []string{}
+	for _, r := range w.EntityHistory {
+		actual = append(actual, r.Operation)
+	}
+
+	expected := strings.Split(operations, ", ")
+	if !slices.Equal(actual, expected) {
+		return fmt.Errorf("unexpected operations in history: %v (expected %v)", actual, expected)
+	}
+
+	return nil
+}
+
+func thePayloadOfRevisionShouldBe(ctx context.Context, revision int, payload string) error {
+	w := testutil.GetWorld(ctx)
+
+	state := history.EntityState{}
+	err := w.GethInstance.RPCClient.CallContext(
+		ctx,
+		&state,
+		"golembase_getEntityAt",
+		w.CreatedEntityKey,
+		revision,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get entity at revision %d: %w", revision, err)
+	}
+
+	if string(state.Payload) != payload {
+		return fmt.Errorf("unexpected payload at revision %d: %s (expected %s)", revision, string(state.Payload), payload)
+	}
+
+	return nil
+}
+
+func theUsageOfTheOwnerShouldBeEntitiesPayloadBytesAndAnnotations(ctx context.Context, entities, payloadBytes, annotations int) error {
+	w := testutil.GetWorld(ctx)
+
+	usage := ownerusage.Usage{}
+	err := w.GethInstance.RPCClient.CallContext(
+		ctx,
+		&usage,
+		"golembase_getOwnerUsage",
+		w.FundedAccount.Address,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get owner usage: %w", err)
+	}
+
+	expected := ownerusage.Usage{
+		Entities:     uint64(entities),
+		PayloadBytes: uint64(payloadBytes),
+		Annotations:  uint64(annotations),
+	}
+
+	if usage != expected {
+		return fmt.Errorf("expected usage %+v, but got %+v", expected, usage)
+	}
+
+	return nil
+}
+
+func listExpiringEntities(ctx context.Context, blocks uint64, owner common.Address, pageSize uint64) error {
+	w := testutil.GetWorld(ctx)
+
+	head, err := w.GethInstance.ETHClient.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	w.ExpiringEntities = nil
+	w.ExpiringPages = 0
+
+	var cursor *golemtype.ExpiringEntitiesCursor
+	for {
+		page := &golemtype.ExpiringEntitiesPage{}
+		err = w.GethInstance.RPCClient.CallContext(
+			ctx,
+			page,
+			"golembase_getEntitiesExpiringBetween",
+			head,
+			head+blocks,
+			owner,
+			cursor,
+			pageSize,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get expiring entities: %w", err)
+		}
+
+		w.ExpiringPages++
+		w.ExpiringEntities = append(w.ExpiringEntities, page.Entities...)
+
+		if page.Next == nil {
+			return nil
+		}
+		cursor = page.Next
+	}
+}
+
+func iListTheEntitiesExpiringInTheNextBlocksWithAPageSizeOf(ctx context.Context, blocks, pageSize int) error {
+	w := testutil.GetWorld(ctx)
+	return listExpiringEntities(ctx, uint64(blocks), w.FundedAccount.Address, uint64(pageSize))
+}
+
+func iListTheEntitiesOfAnotherOwnerExpiringInTheNextBlocks(ctx context.Context, blocks int) error {
+	return listExpiringEntities(ctx, uint64(blocks), common.HexToAddress("0x1234"), 100)
+}
+
+func iShouldGetExpiringEntitiesInPages(ctx context.Context, count, pages int) error {
+	w := testutil.GetWorld(ctx)
+
+	if len(w.ExpiringEntities) != count {
+		return fmt.Errorf("expected %d expiring entities, but got %d", count, len(w.ExpiringEntities))
+	}
+
+	if w.ExpiringPages != pages {
+		return fmt.Errorf("expected %d pages, but got %d", pages, w.ExpiringPages)
+	}
+
+	for i, e := range w.ExpiringEntities {
+		if i > 0 && e.ExpiresAtBlock < w.ExpiringEntities[i-1].ExpiresAtBlock {
+			return fmt.Errorf("expiring entities are not ordered by expiration block")
+		}
+	}
+
+	return nil
+}
+
+func iDepositATransactionFromL1ToCreateAnEntity(ctx context.Context) error {
+	w := testutil.GetWorld(ctx)
+
+	receipt, err := w.DepositStorageTransaction(ctx, &storagetx.StorageTransaction{
+		Create: []storagetx.Create{
+			{
+				TTL:     100,
+				Payload: []byte("test payload"),
+				StringAnnotations: []entity.StringAnnotation{
+					{Key: "test_key", Value: "test_value"},
+				},
+				NumericAnnotations: []entity.NumericAnnotation{
+					{Key: "test_number", Value: 42},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to deposit create: %w", err)
+	}
+
+	w.CreatedEntityKey = receipt.Logs[0].Topics[1]
+
+	return nil
+}
+
+func iDepositATransactionFromL1ToCreateAnEntityNamedInTheNamespace(ctx context.Context, name, namespace string) error {
+	w := testutil.GetWorld(ctx)
+
+	receipt, err := w.DepositStorageTransaction(ctx, &storagetx.StorageTransaction{
+		Create: []storagetx.Create{
+			{
+				TTL:       100,
+				Payload:   []byte("test payload"),
+				Namespace: namespace,
+				Name:      name,
+			},
+		},
+	})
+
+	w.LastError = err
+
+	if err != nil {
+		return nil
+	}
+
+	w.CreatedEntityKey = receipt.Logs[0].Topics[1]
+
+	return nil
+}
+
+func iDepositATransactionFromL1ToUpdateTheEntityChangingThePayload(ctx context.Context) error {
+	w := testutil.GetWorld(ctx)
+
+	_, err := w.DepositStorageTransaction(ctx, &storagetx.StorageTransaction{
+		Update: []storagetx.Update{
+			{
+				EntityKey: w.CreatedEntityKey,
+				TTL:       100,
+				Payload:   []byte("new payload"),
+				StringAnnotations: []entity.StringAnnotation{
+					{Key: "test_key", Value: "test_value"},
+				},
+				NumericAnnotations: []entity.NumericAnnotation{
+					{Key: "test_number", Value: 42},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to deposit update: %w", err)
+	}
+
+	return nil
+}
+
+func iDepositATransactionFromL1ToDeleteTheEntity(ctx context.Context) error {
+	w := testutil.GetWorld(ctx)
+
+	_, err := w.DepositStorageTransaction(ctx, &storagetx.StorageTransaction{
+		Delete: []common.Hash{w.CreatedEntityKey},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to deposit delete: %w", err)
+	}
+
+	return nil
+}
+
+func iRequestAProofOfTheEntity(ctx context.Context) error {
+	w := testutil.GetWorld(ctx)
+
+	var proof *entityproof.EntityProof
+	err := w.GethInstance.RPCClient.CallContext(
+		ctx,
+		&proof,
+		"golembase_getEntityProof",
+		w.CreatedEntityKey,
+		"latest",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get entity proof: %w", err)
+	}
+
+	w.EntityProof = proof
+
+	return nil
+}
+
+// verifyEntityProof verifies the proof against the state root of the header of its block.
+func verifyEntityProof(ctx context.Context) (*entityproof.Entity, error) {
+	w := testutil.GetWorld(ctx)
+
+	header, err := w.GethInstance.ETHClient.HeaderByHash(ctx, w.EntityProof.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header of block %s: %w", w.EntityProof.BlockHash.Hex(), err)
+	}
+
+	return entityproof.Verify(header.Root, w.CreatedEntityKey, w.EntityProof)
+}
+
+func theProofShouldVerifyAgainstTheStateRootOfTheBlock(ctx context.Context) error {
+	w := testutil.GetWorld(ctx)
+
+	e, err := verifyEntityProof(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify entity proof: %w", err)
+	}
+
+	var payload []byte
+	err = w.GethInstance.RPCClient.CallContext(ctx, &payload, "golembase_getStorageValue", w.CreatedEntityKey)
+	if err != nil {
+		return fmt.Errorf("failed to get storage value: %w", err)
+	}
+
+	if !bytes.Equal(e.Payload, payload) {
+		return fmt.Errorf("proven payload %q does not match the payload %q", e.Payload, payload)
+	}
+
+	if e.MetaData.Owner != w.FundedAccount.Address {
+		return fmt.Errorf("proven owner %s does not match the sender %s", e.MetaData.Owner.Hex(), w.FundedAccount.Address.Hex())
+	}
+
+	return nil
+}
+
+func theProofShouldShowThatTheEntityDoesNotExist(ctx context.Context) error {
+	_, err := verifyEntityProof(ctx)
+	if !errors.Is(err, entityproof.ErrEntityNotFound) {
+		return fmt.Errorf("expected the proof to show that the entity does not exist, got %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package entityproof_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/tracing"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/entityproof"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/stretchr/testify/require"
+)
+
+// newState stores the entities and returns the committed state and its header.
+func newState(t *testing.T, entities map[common.Hash][]byte) (*state.StateDB, *types.Header) {
+	t.Helper()
+
+	db := state.NewDatabaseForTesting()
+	statedb, err := state.New(types.EmptyRootHash, db)
+	require.NoError(t, err)
+
+	// like the chain, give the storage processor a nonce so that it is not an empty account
+	statedb.SetNonce(address.GolemBaseStorageProcessorAddress, 1, tracing.NonceChangeNewContract)
+
+	owner := common.HexToAddress("0x1234")
+	for key, payload := range entities {
+		emd := entity.EntityMetaData{
+			ExpiresAtBlock:    100,
+			StringAnnotations: []entity.StringAnnotation{{Key: "kind", Value: "greeting"}},
+			Owner:             owner,
+		}
+		require.NoError(t, entity.Store(params.GolemBaseRules{IsKaolin: true}, statedb, key, owner, emd, payload))
+	}
+
+	root, err := statedb.Commit(1, true, false)
+	require.NoError(t, err)
+
+	statedb, err = state.New(root, db)
+	require.NoError(t, err)
+
+	return statedb, &types.Header{Number: big.NewInt(1), Root: root}
+}
+
+func TestProveAndVerify(t *testing.T) {
+	small := common.HexToHash("0x01")
+	large := common.HexToHash("0x02")
+	largePayload := bytes.Repeat([]byte("golem"), 100)
+
+	statedb, header := newState(t, map[common.Hash][]byte{
+		small: []byte("hello"),
+		large: largePayload,
+	})
+
+	t.Run("entity with a small payload", func(t *testing.T) {
+		proof, err := entityproof.Prove(statedb, header, small)
+		require.NoError(t, err)
+
+		e, err := entityproof.Verify(header.Root, small, proof)
+		require.NoError(t, err)
+		require.Equal(t, []byte("hello"), e.Payload)
+
+		md, err := entity.GetEntityMetaData(statedb, small)
+		require.NoError(t, err)
+		require.Equal(t, *md, e.MetaData)
+	})
+
+	t.Run("entity with a payload spanning many slots", func(t *testing.T) {
+		proof, err := entityproof.Prove(statedb, header, large)
+		require.NoError(t, err)
+		require.Greater(t, len(proof.StorageProof), len(largePayload)/32)
+
+		e, err := entityproof.Verify(header.Root, large, proof)
+		require.NoError(t, err)
+		require.Equal(t, largePayload, e.Payload)
+	})
+
+	t.Run("missing entity", func(t *testing.T) {
+		missing := common.HexToHash("0x03")
+		proof, err := entityproof.Prove(statedb, header, missing)
+		require.NoError(t, err)
+		require.Len(t, proof.StorageProof, 1)
+
+		_, err = entityproof.Verify(header.Root, missing, proof)
+		require.ErrorIs(t, err, entityproof.ErrEntityNotFound)
+	})
+
+	t.Run("tampered value", func(t *testing.T) {
+		proof, err := entityproof.Prove(statedb, header, small)
+		require.NoError(t, err)
+
+		last := len(proof.StorageProof) - 1
+		proof.StorageProof[last].Value[0] ^= 0xff
+
+		_, err = entityproof.Verify(header.Root, small, proof)
+		require.ErrorContains(t, err, "proves value")
+	})
+
+	t.Run("incomplete proof", func(t *testing.T) {
+		proof, err := entityproof.Prove(statedb, header, large)
+		require.NoError(t, err)
+
+		proof.StorageProof = proof.StorageProof[:len(proof.StorageProof)-1]
+
+		_, err = entityproof.Verify(header.Root, large, proof)
+		require.ErrorContains(t, err, "proof does not include slot")
+	})
+
+	t.Run("wrong state root", func(t *testing.T) {
+		proof, err := entityproof.Prove(statedb, header, small)
+		require.NoError(t, err)
+
+		_, err = entityproof.Verify(common.HexToHash("0xdead"), small, proof)
+		require.ErrorContains(t, err, "invalid account proof")
+	})
+
+	t.Run("proof of another entity", func(t *testing.T) {
+		proof, err := entityproof.Prove(statedb, header, small)
+		require.NoError(t, err)
+
+		_, err = entityproof.Verify(header.Root, large, proof)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,70 @@
+// Package entityproof creates and verifies Merkle proofs of entities.
+//
+// An entity is stored in the storage of the Golem Base storage processor: its metadata
+// and payload are blobs spread over one or more slots, and its key is a member of the
+// set of all entities. A proof consists of the account proof of the storage processor
+// and the storage proofs of all of these slots, so a client that only trusts a state root,
+// e.g. a light client or a bridge, can reconstruct the entity without trusting the node.
+package entityproof
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/common/hexutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
+)
+
+// StorageProof is the Merkle proof of a storage slot of the storage processor.
+type StorageProof struct {
+	Slot  common.Hash     `json:"slot"`
+	Value common.Hash     `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// EntityProof is the proof of the state of an entity in the block.
+// If the entity does not exist, it proves its absence from the set of all entities.
+type EntityProof struct {
+	EntityKey    common.Hash     `json:"entityKey"`
+	BlockNumber  hexutil.Uint64  `json:"blockNumber"`
+	BlockHash    common.Hash     `json:"blockHash"`
+	StateRoot    common.Hash     `json:"stateRoot"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageProof []StorageProof  `json:"storageProof"`
+}
+
+// slotRecorder records the slots of the storage processor that are read through it.
+type slotRecorder struct {
+	access storageutil.StateAccess
+	seen   map[common.Hash]struct{}
+	slots  []common.Hash
+}
+
+func (r *slotRecorder) GetState(addr common.Address, slot common.Hash) common.Hash {
+	if addr == address.GolemBaseStorageProcessorAddress {
+		if _, ok := r.seen[slot]; !ok {
+			r.seen[slot] = struct{}{}
+			r.slots = append(r.slots, slot)
+		}
+	}
+	return r.access.GetState(addr, slot)
+}
+
+func (r *slotRecorder) SetState(common.Address, common.Hash, common.Hash) common.Hash {
+	panic("entityproof: state is read only")
+}
+
+// Slots returns the storage slots that make up the entity: its membership in the set
+// of all entities and, if it exists, its metadata and payload blobs.
+func Slots(access storageutil.StateAccess, key common.Hash) []common.Hash {
+	r := &slotRecorder{access: access, seen: map[common.Hash]struct{}{}}
+
+	if allentities.Contains(r, key) {
+		// an error decoding the metadata does not change the slots that were read
+		_, _ = entity.GetEntityMetaData(r, key)
+		entity.GetPayload(r, key)
+	}
+
+	return r.slots
+}
@@ -0,0 +1,78 @@
+package entityproof
+
+import (
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/common/hexutil"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/trie"
+)
+
+// proofList collects the nodes of a Merkle proof.
+type proofList []hexutil.Bytes
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, common.CopyBytes(value))
+	return nil
+}
+
+func (n *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// Prove creates the proof of the entity with the key in the state of the header.
+// The statedb has to be opened at the state root of the header.
+func Prove(statedb *state.StateDB, header *types.Header, key common.Hash) (*EntityProof, error) {
+	processor := address.GolemBaseStorageProcessorAddress
+	slots := Slots(statedb, key)
+
+	storageProof := make([]StorageProof, len(slots))
+	storageRoot := statedb.GetStorageRoot(processor)
+
+	var storageTrie state.Trie
+	if storageRoot != types.EmptyRootHash && storageRoot != (common.Hash{}) {
+		id := trie.StorageTrieID(header.Root, crypto.Keccak256Hash(processor.Bytes()), storageRoot)
+		st, err := trie.NewStateTrie(id, statedb.Database().TrieDB())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open storage trie: %w", err)
+		}
+		storageTrie = st
+	}
+
+	for i, slot := range slots {
+		proof := proofList{}
+		if storageTrie != nil {
+			if err := storageTrie.Prove(crypto.Keccak256(slot.Bytes()), &proof); err != nil {
+				return nil, fmt.Errorf("failed to prove slot %s: %w", slot.Hex(), err)
+			}
+		}
+		storageProof[i] = StorageProof{
+			Slot:  slot,
+			Value: statedb.GetState(processor, slot),
+			Proof: proof,
+		}
+	}
+
+	tr, err := trie.NewStateTrie(trie.StateTrieID(header.Root), statedb.Database().TrieDB())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state trie: %w", err)
+	}
+
+	accountProof := proofList{}
+	if err := tr.Prove(crypto.Keccak256(processor.Bytes()), &accountProof); err != nil {
+		return nil, fmt.Errorf("failed to prove storage processor account: %w", err)
+	}
+
+	return &EntityProof{
+		EntityKey:    key,
+		BlockNumber:  hexutil.Uint64(header.Number.Uint64()),
+		BlockHash:    header.Hash(),
+		StateRoot:    header.Root,
+		AccountProof: accountProof,
+		StorageProof: storageProof,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package entityproof
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/common/hexutil"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/ethdb/memorydb"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/jeffcogswell/golembase-op-geth/trie"
+)
+
+// ErrEntityNotFound is returned by Verify if the proof shows that the entity does not exist.
+var ErrEntityNotFound = errors.New("entity not found")
+
+// Entity is an entity reconstructed from a verified proof.
+type Entity struct {
+	Key      common.Hash           `json:"key"`
+	MetaData entity.EntityMetaData `json:"metaData"`
+	Payload  []byte                `json:"payload"`
+}
+
+// provenState serves the slots of the storage processor that were verified against the
+// state root. Reading any other slot is recorded as an error, so an entity can not be
+// reconstructed from an incomplete proof.
+type provenState struct {
+	slots   map[common.Hash]common.Hash
+	missing []common.Hash
+}
+
+func (s *provenState) GetState(addr common.Address, slot common.Hash) common.Hash {
+	value, ok := s.slots[slot]
+	if addr != address.GolemBaseStorageProcessorAddress || !ok {
+		s.missing = append(s.missing, slot)
+		return common.Hash{}
+	}
+	return value
+}
+
+func (s *provenState) SetState(common.Address, common.Hash, common.Hash) common.Hash {
+	panic("entityproof: state is read only")
+}
+
+// Verify checks the proof of the entity with the key against a trusted state root and
+// reconstructs the entity from the proven slots.
+// It returns ErrEntityNotFound if the proof shows that the entity does not exist at that state.
+// An entity that expires in the block of the proof is still part of its state, the caller
+// has to compare its ExpiresAtBlock with the block number if that matters.
+func Verify(stateRoot common.Hash, key common.Hash, proof *EntityProof) (*Entity, error) {
+	if proof.EntityKey != key {
+		return nil, fmt.Errorf("proof is for entity %s, expected %s", proof.EntityKey.Hex(), key.Hex())
+	}
+
+	processor := address.GolemBaseStorageProcessorAddress
+
+	value, err := trie.VerifyProof(stateRoot, crypto.Keccak256(processor.Bytes()), proofDB(proof.AccountProof))
+	if err != nil {
+		return nil, fmt.Errorf("invalid account proof: %w", err)
+	}
+
+	// a storage processor without an account has no storage
+	storageRoot := types.EmptyRootHash
+	if value != nil {
+		account := new(types.StateAccount)
+		if err := rlp.DecodeBytes(value, account); err != nil {
+			return nil, fmt.Errorf("invalid account in account proof: %w", err)
+		}
+		storageRoot = account.Root
+	}
+
+	state := &provenState{slots: make(map[common.Hash]common.Hash, len(proof.StorageProof))}
+	for _, sp := range proof.StorageProof {
+		proven, err := verifySlot(storageRoot, sp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid storage proof of slot %s: %w", sp.Slot.Hex(), err)
+		}
+		if proven != sp.Value {
+			return nil, fmt.Errorf("storage proof of slot %s proves value %s, not %s", sp.Slot.Hex(), proven.Hex(), sp.Value.Hex())
+		}
+		state.slots[sp.Slot] = proven
+	}
+
+	// the same reads as the prover, every slot they touch has to be proven
+	Slots(state, key)
+	if len(state.missing) > 0 {
+		return nil, fmt.Errorf("proof does not include slot %s", state.missing[0].Hex())
+	}
+
+	if !allentities.Contains(state, key) {
+		return nil, ErrEntityNotFound
+	}
+
+	md, err := entity.GetEntityMetaData(state, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode entity metadata: %w", err)
+	}
+
+	return &Entity{
+		Key:      key,
+		MetaData: *md,
+		Payload:  entity.GetPayload(state, key),
+	}, nil
+}
+
+// verifySlot returns the value of the slot proven by the storage proof.
+func verifySlot(storageRoot common.Hash, sp StorageProof) (common.Hash, error) {
+	if storageRoot == types.EmptyRootHash {
+		return common.Hash{}, nil
+	}
+
+	value, err := trie.VerifyProof(storageRoot, crypto.Keccak256(sp.Slot.Bytes()), proofDB(sp.Proof))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if value == nil {
+		return common.Hash{}, nil
+	}
+
+	// slots are stored as RLP encoded byte strings without leading zeroes
+	_, content, _, err := rlp.Split(value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return common.BytesToHash(content), nil
+}
+
+func proofDB(nodes []hexutil.Bytes) *memorydb.Database {
+	db := memorydb.New()
+	for _, node := range nodes {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	return db
+}
@@ -0,0 +1,82 @@
+// Package etlmetrics exposes how far an ETL is behind the chain head.
+package etlmetrics
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
+	"github.com/jeffcogswell/golembase-op-geth/metrics/exp"
+	"github.com/urfave/cli/v2"
+)
+
+// headPollInterval is how often the chain head is fetched to compute the lag.
+const headPollInterval = 5 * time.Second
+
+var (
+	processedBlockGauge = metrics.NewRegisteredGauge("golembase/etl/block", nil)
+	headBlockGauge      = metrics.NewRegisteredGauge("golembase/etl/head", nil)
+	lagGauge            = metrics.NewRegisteredGauge("golembase/etl/lag", nil)
+
+	processedBlock atomic.Uint64
+	headBlock      atomic.Uint64
+)
+
+// Flag returns the flag for the address of the metrics server, the server is not started if it is empty.
+func Flag(destination *string) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "metrics-addr",
+		Usage:       "address to serve metrics on (e.g. 127.0.0.1:6061), metrics are disabled if empty",
+		EnvVars:     []string{"METRICS_ADDR"},
+		Destination: destination,
+	}
+}
+
+// Start enables metrics, serves them on /debug/metrics/prometheus at addr and keeps
+// track of the chain head until ctx is done.
+func Start(ctx context.Context, log *slog.Logger, addr string, ec *ethclient.Client) {
+	metrics.Enable()
+	exp.Setup(addr)
+
+	go func() {
+		ticker := time.NewTicker(headPollInterval)
+		defer ticker.Stop()
+		for {
+			head, err := ec.BlockNumber(ctx)
+			if err != nil {
+				log.Warn("failed to get chain head for metrics", "error", err)
+			} else {
+				headBlock.Store(head)
+				update()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// BlockProcessed records that the ETL has processed the block.
+func BlockProcessed(blockNumber uint64) {
+	processedBlock.Store(blockNumber)
+	update()
+}
+
+func update() {
+	processed, head := processedBlock.Load(), headBlock.Load()
+
+	processedBlockGauge.Update(int64(processed))
+	headBlockGauge.Update(int64(head))
+
+	lag := uint64(0)
+	if head > processed {
+		lag = head - processed
+	}
+	lagGauge.Update(int64(lag))
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+)
+
+// payloadHashOf returns the payload hash as a hex string, or an empty string if it is not set.
+func payloadHashOf(content entity.ContentMetaData) string {
+	if content.PayloadHash == (common.Hash{}) {
+		return ""
+	}
+	return content.PayloadHash.Hex()
+}
@@ -42,28 +42,6 @@ func init() {
 	}
 }
 
-func compileGeth() (string, func(), error) {
-	td, err := os.MkdirTemp("", "golem-base")
-	if err != nil {
-		panic(fmt.Errorf("failed to create temp dir: %w", err))
-	}
-
-	gethBinaryPath := filepath.Join(td, "geth")
-
-	cmd := exec.Command("go", "build", "-o", gethBinaryPath, "../../../cmd/geth")
-	out := &bytes.Buffer{}
-	cmd.Stdout = out
-	cmd.Stderr = out
-	err = cmd.Run()
-	if err != nil {
-		return "", func() {}, fmt.Errorf("failed to compile geth: %w\n%s", err, out.String())
-	}
-
-	return gethBinaryPath, func() {
-		os.RemoveAll(td)
-	}, nil
-}
-
 func compileMongodbETL() (string, func(), error) {
 	td, err := os.MkdirTemp("", "mongodb-etl")
 	if err != nil {
@@ -90,11 +68,6 @@ func TestMain(m *testing.M) {
 	pflag.Parse()
 	opts.Paths = pflag.Args()
 
-	gethPath, cleanupCompiled, err := compileGeth()
-	if err != nil {
-		log.Fatal(fmt.Errorf("failed to compile geth: %w", err))
-	}
-
 	mongodbETLPath, cleanupCompiledMongodbETL, err := compileMongodbETL()
 	if err != nil {
 		log.Fatal(fmt.Errorf("failed to compile geth: %w", err))
@@ -106,7 +79,7 @@ func TestMain(m *testing.M) {
 			InitializeScenario(sctx)
 			sctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
 
-				world, err := etlworld.NewETLWorld(ctx, gethPath, mongodbETLPath)
+				world, err := etlworld.NewETLWorld(ctx, mongodbETLPath)
 				if err != nil {
 					return ctx, fmt.Errorf("failed to start geth instance: %w", err)
 				}
@@ -135,7 +108,6 @@ func TestMain(m *testing.M) {
 	// 	status = st
 	// }
 
-	cleanupCompiled()
 	cleanupCompiledMongodbETL()
 
 	os.Exit(status)
@@ -31,10 +31,9 @@ type ETLWorld struct {
 // NewETLWorld creates a new ETL world for testing with MongoDB.
 func NewETLWorld(
 	ctx context.Context,
-	gethPath string,
 	mongoETLPath string,
 ) (*ETLWorld, error) {
-	world, err := testutil.NewWorld(ctx, gethPath)
+	world, err := testutil.NewWorld(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/etl/etlmetrics"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/etl/mongodb/mongogolem"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/wal"
 	"github.com/urfave/cli/v2"
@@ -25,6 +26,7 @@ func main() {
 		dbName      string
 		walDir      string
 		rpcEndpoint string
+		metricsAddr string
 	}{}
 
 	app := &cli.App{
@@ -58,6 +60,7 @@ func main() {
 				Required:    true,
 				Destination: &cfg.rpcEndpoint,
 			},
+			etlmetrics.Flag(&cfg.metricsAddr),
 		},
 		Action: func(c *cli.Context) error {
 			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
@@ -98,6 +101,10 @@ func main() {
 				return fmt.Errorf("failed to dial rpc endpoint: %w", err)
 			}
 
+			if cfg.metricsAddr != "" {
+				etlmetrics.Start(ctx, log, cfg.metricsAddr, ec)
+			}
+
 			networkID, err := ec.NetworkID(ctx)
 			if err != nil {
 				return fmt.Errorf("failed to get network id: %w", err)
@@ -136,6 +143,8 @@ func main() {
 			blockNumber := processingStatus.LastProcessedBlockNumber
 			blockHash := processingStatus.LastProcessedBlockHash
 
+			etlmetrics.BlockProcessed(uint64(blockNumber))
+
 			for blockWal, err := range wal.NewIterator(ctx, cfg.walDir, uint64(blockNumber)+1, common.HexToHash(blockHash), true) {
 				if err != nil {
 					return fmt.Errorf("failed to iterate over wal: %w", err)
@@ -183,6 +192,9 @@ func main() {
 									StringAnnotations:  stringAnnotations,
 									NumericAnnotations: numericAnnotations,
 									OwnerAddress:       op.Create.Owner.Hex(),
+									ContentType:        op.Create.ContentType,
+									ContentEncoding:    op.Create.ContentEncoding,
+									PayloadHash:        payloadHashOf(op.Create.ContentMetaData),
 								})
 								if err != nil {
 									return nil, fmt.Errorf("failed to insert entity: %w", err)
@@ -222,6 +234,9 @@ func main() {
 									StringAnnotations:  stringAnnotations,
 									NumericAnnotations: numericAnnotations,
 									OwnerAddress:       existingEntity.OwnerAddress,
+									ContentType:        op.Update.ContentType,
+									ContentEncoding:    op.Update.ContentEncoding,
+									PayloadHash:        payloadHashOf(op.Update.ContentMetaData),
 								})
 								if err != nil {
 									return nil, fmt.Errorf("failed to insert updated entity: %w", err)
@@ -281,6 +296,8 @@ func main() {
 				if err != nil {
 					return fmt.Errorf("failed to process block: %w", err)
 				}
+
+				etlmetrics.BlockProcessed(blockWal.BlockInfo.Number)
 			}
 
 			return nil
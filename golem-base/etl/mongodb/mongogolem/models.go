@@ -23,6 +23,9 @@ type Entity struct {
 	CreatedAt          time.Time         `bson:"created_at"`
 	UpdatedAt          time.Time         `bson:"updated_at"`
 	OwnerAddress       string            `bson:"owner_address"`
+	ContentType        string            `bson:"content_type,omitempty"`
+	ContentEncoding    string            `bson:"content_encoding,omitempty"`
+	PayloadHash        string            `bson:"payload_hash,omitempty"`
 }
 
 // Annotation represents a key-value pair
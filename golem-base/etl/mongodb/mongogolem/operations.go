@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	golementity "github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -95,13 +96,7 @@ func (m *MongoGolem) InsertEntity(ctx context.Context, entity Entity) error {
 		entity.NumericAnnotations = make(map[string]int64)
 	}
 
-	// Try to deserialize the payload to JSON if it's not empty
-	if len(entity.Payload) > 0 {
-		var jsonData interface{}
-		if err := json.Unmarshal(entity.Payload, &jsonData); err == nil {
-			entity.PayloadAsJSON = jsonData
-		}
-	}
+	entity.PayloadAsJSON = payloadAsJSON(entity)
 
 	_, err := cols.Entities.InsertOne(ctx, entity)
 	if err != nil {
@@ -125,13 +120,7 @@ func (m *MongoGolem) UpdateEntity(ctx context.Context, entity Entity) error {
 		entity.NumericAnnotations = make(map[string]int64)
 	}
 
-	// Try to deserialize the payload to JSON if it's not empty
-	if len(entity.Payload) > 0 {
-		var jsonData interface{}
-		if err := json.Unmarshal(entity.Payload, &jsonData); err == nil {
-			entity.PayloadAsJSON = jsonData
-		}
-	}
+	entity.PayloadAsJSON = payloadAsJSON(entity)
 
 	_, err := cols.Entities.ReplaceOne(
 		ctx,
@@ -145,6 +134,26 @@ func (m *MongoGolem) UpdateEntity(ctx context.Context, entity Entity) error {
 	return nil
 }
 
+// payloadAsJSON deserializes the payload of the entity to JSON, after decompressing it
+// according to its content encoding. It returns nil if the payload is not JSON.
+func payloadAsJSON(entity Entity) interface{} {
+	if len(entity.Payload) == 0 {
+		return nil
+	}
+
+	payload, err := golementity.DecodePayload(entity.ContentEncoding, entity.Payload)
+	if err != nil {
+		return nil
+	}
+
+	var jsonData interface{}
+	if err := json.Unmarshal(payload, &jsonData); err != nil {
+		return nil
+	}
+
+	return jsonData
+}
+
 // DeleteEntity deletes an entity by key
 func (m *MongoGolem) DeleteEntity(ctx context.Context, key string) error {
 	cols := m.Collections()
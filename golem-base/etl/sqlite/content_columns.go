@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+)
+
+// addContentColumns adds the content metadata columns to an entities table
+// created before they were part of the schema.
+func addContentColumns(ctx context.Context, db *sql.DB) error {
+	var count int
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM pragma_table_info('entities')
+		WHERE name = 'content_type';
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to read columns of 'entities' table: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	for _, column := range []string{"content_type", "content_encoding", "payload_hash"} {
+		_, err = db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE entities ADD COLUMN %s TEXT NOT NULL DEFAULT ''`, column))
+		if err != nil {
+			return fmt.Errorf("failed to add column %s: %w", column, err)
+		}
+	}
+
+	return nil
+}
+
+// payloadHashOf returns the payload hash as a hex string, or an empty string if it is not set.
+func payloadHashOf(content entity.ContentMetaData) string {
+	if content.PayloadHash == (common.Hash{}) {
+		return ""
+	}
+	return content.PayloadHash.Hex()
+}
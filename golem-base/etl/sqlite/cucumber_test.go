@@ -41,28 +41,6 @@ func init() {
 	}
 }
 
-func compileGeth() (string, func(), error) {
-	td, err := os.MkdirTemp("", "golem-base")
-	if err != nil {
-		panic(fmt.Errorf("failed to create temp dir: %w", err))
-	}
-
-	gethBinaryPath := filepath.Join(td, "geth")
-
-	cmd := exec.Command("go", "build", "-o", gethBinaryPath, "../../../cmd/geth")
-	out := &bytes.Buffer{}
-	cmd.Stdout = out
-	cmd.Stderr = out
-	err = cmd.Run()
-	if err != nil {
-		return "", func() {}, fmt.Errorf("failed to compile geth: %w\n%s", err, out.String())
-	}
-
-	return gethBinaryPath, func() {
-		os.RemoveAll(td)
-	}, nil
-}
-
 func compileSqliteETL() (string, func(), error) {
 	td, err := os.MkdirTemp("", "sqlite-etl")
 	if err != nil {
@@ -89,11 +67,6 @@ func TestMain(m *testing.M) {
 	pflag.Parse()
 	opts.Paths = pflag.Args()
 
-	gethPath, cleanupCompiled, err := compileGeth()
-	if err != nil {
-		log.Fatal(fmt.Errorf("failed to compile geth: %w", err))
-	}
-
 	sqliteETLPath, cleanupCompiledSQLiteETL, err := compileSqliteETL()
 	if err != nil {
 		log.Fatal(fmt.Errorf("failed to compile geth: %w", err))
@@ -105,7 +78,7 @@ func TestMain(m *testing.M) {
 			InitializeScenario(sctx)
 			sctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
 
-				world, err := etlworld.NewETLWorld(ctx, gethPath, sqliteETLPath)
+				world, err := etlworld.NewETLWorld(ctx, sqliteETLPath)
 				if err != nil {
 					return ctx, fmt.Errorf("failed to start geth instance: %w", err)
 				}
@@ -134,7 +107,6 @@ func TestMain(m *testing.M) {
 	// 	status = st
 	// }
 
-	cleanupCompiled()
 	cleanupCompiledSQLiteETL()
 
 	os.Exit(status)
@@ -19,10 +19,9 @@ type ETLWorld struct {
 
 func NewETLWorld(
 	ctx context.Context,
-	gethPath string,
 	sqlliteETLPath string,
 ) (*ETLWorld, error) {
-	world, err := testutil.NewWorld(ctx, gethPath)
+	world, err := testutil.NewWorld(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/etl/etlmetrics"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/etl/sqlite/sqlitegolem"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/wal"
 	_ "github.com/mattn/go-sqlite3"
@@ -27,6 +28,7 @@ func main() {
 		dbFile      string
 		walDir      string
 		rpcEndpoint string
+		metricsAddr string
 	}{}
 	app := &cli.App{
 		Name: "sqlite-etl",
@@ -52,6 +54,7 @@ func main() {
 				Required:    true,
 				Destination: &cfg.rpcEndpoint,
 			},
+			etlmetrics.Flag(&cfg.metricsAddr),
 		},
 		Action: func(c *cli.Context) error {
 
@@ -78,6 +81,11 @@ func main() {
 				}
 			}
 
+			err = addContentColumns(ctx, db)
+			if err != nil {
+				return fmt.Errorf("failed to add content columns: %w", err)
+			}
+
 			autocommit := sqlitegolem.New(db)
 
 			ec, err := ethclient.Dial(cfg.rpcEndpoint)
@@ -85,6 +93,10 @@ func main() {
 				return fmt.Errorf("failed to dial rpc endpoint: %w", err)
 			}
 
+			if cfg.metricsAddr != "" {
+				etlmetrics.Start(ctx, log, cfg.metricsAddr, ec)
+			}
+
 			networkID, err := ec.NetworkID(ctx)
 			if err != nil {
 				return fmt.Errorf("failed to get network id: %w", err)
@@ -121,6 +133,8 @@ func main() {
 			blockNumber := processingStatus.LastProcessedBlockNumber
 			blockHash := processingStatus.LastProcessedBlockHash
 
+			etlmetrics.BlockProcessed(uint64(blockNumber))
+
 			for blockWal, err := range wal.NewIterator(ctx, cfg.walDir, uint64(blockNumber)+1, common.HexToHash(blockHash), true) {
 				if err != nil {
 					return fmt.Errorf("failed to iterate over wal: %w", err)
@@ -150,10 +164,13 @@ func main() {
 						case op.Create != nil:
 							log.Info("create", "entity", op.Create.EntityKey.Hex())
 							err = txDB.InsertEntity(ctx, sqlitegolem.InsertEntityParams{
-								Key:          op.Create.EntityKey.Hex(),
-								ExpiresAt:    int64(op.Create.ExpiresAtBlock),
-								Payload:      op.Create.Payload,
-								OwnerAddress: op.Create.Owner.Hex(),
+								Key:             op.Create.EntityKey.Hex(),
+								ExpiresAt:       int64(op.Create.ExpiresAtBlock),
+								Payload:         op.Create.Payload,
+								OwnerAddress:    op.Create.Owner.Hex(),
+								ContentType:     op.Create.ContentType,
+								ContentEncoding: op.Create.ContentEncoding,
+								PayloadHash:     payloadHashOf(op.Create.ContentMetaData),
 							})
 							if err != nil {
 								return fmt.Errorf("failed to insert entity: %w", err)
@@ -191,10 +208,13 @@ func main() {
 							txDB.DeleteStringAnnotations(ctx, op.Update.EntityKey.Hex())
 
 							txDB.InsertEntity(ctx, sqlitegolem.InsertEntityParams{
-								Key:          op.Update.EntityKey.Hex(),
-								ExpiresAt:    int64(op.Update.ExpiresAtBlock),
-								Payload:      op.Update.Payload,
-								OwnerAddress: existingEntity.OwnerAddress,
+								Key:             op.Update.EntityKey.Hex(),
+								ExpiresAt:       int64(op.Update.ExpiresAtBlock),
+								Payload:         op.Update.Payload,
+								OwnerAddress:    existingEntity.OwnerAddress,
+								ContentType:     op.Update.ContentType,
+								ContentEncoding: op.Update.ContentEncoding,
+								PayloadHash:     payloadHashOf(op.Update.ContentMetaData),
 							})
 
 							for _, annotation := range op.Update.NumericAnnotations {
@@ -257,6 +277,8 @@ func main() {
 					return fmt.Errorf("failed to process block: %w", err)
 				}
 
+				etlmetrics.BlockProcessed(blockWal.BlockInfo.Number)
+
 			}
 
 			return nil
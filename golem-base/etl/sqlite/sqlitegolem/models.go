@@ -5,10 +5,13 @@
 package sqlitegolem
 
 type Entity struct {
-	Key          string
-	ExpiresAt    int64
-	Payload      []byte
-	OwnerAddress string
+	Key             string
+	ExpiresAt       int64
+	Payload         []byte
+	OwnerAddress    string
+	ContentType     string
+	ContentEncoding string
+	PayloadHash     string
 }
 
 type NumericAnnotation struct {
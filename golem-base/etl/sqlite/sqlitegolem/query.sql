@@ -1,5 +1,5 @@
 -- name: InsertEntity :exec
-INSERT INTO entities (key, expires_at, payload, owner_address) VALUES (?, ?, ?, ?);
+INSERT INTO entities (key, expires_at, payload, owner_address, content_type, content_encoding, payload_hash) VALUES (?, ?, ?, ?, ?, ?, ?);
 
 -- name: InsertStringAnnotation :exec
 INSERT INTO string_annotations (entity_key, annotation_key, value) VALUES (?, ?, ?);
@@ -198,14 +198,17 @@ func (q *Queries) HasProcessingStatus(ctx context.Context, network string) (bool
 }
 
 const insertEntity = `-- name: InsertEntity :exec
-INSERT INTO entities (key, expires_at, payload, owner_address) VALUES (?, ?, ?, ?)
+INSERT INTO entities (key, expires_at, payload, owner_address, content_type, content_encoding, payload_hash) VALUES (?, ?, ?, ?, ?, ?, ?)
 `
 
 type InsertEntityParams struct {
-	Key          string
-	ExpiresAt    int64
-	Payload      []byte
-	OwnerAddress string
+	Key             string
+	ExpiresAt       int64
+	Payload         []byte
+	OwnerAddress    string
+	ContentType     string
+	ContentEncoding string
+	PayloadHash     string
 }
 
 func (q *Queries) InsertEntity(ctx context.Context, arg InsertEntityParams) error {
@@ -214,6 +217,9 @@ func (q *Queries) InsertEntity(ctx context.Context, arg InsertEntityParams) erro
 		arg.ExpiresAt,
 		arg.Payload,
 		arg.OwnerAddress,
+		arg.ContentType,
+		arg.ContentEncoding,
+		arg.PayloadHash,
 	)
 	return err
 }
@@ -8,7 +8,10 @@ CREATE TABLE entities (
   key TEXT NOT NULL PRIMARY KEY,
   expires_at INTEGER NOT NULL,
   payload BLOB NOT NULL,
-  owner_address TEXT NOT NULL
+  owner_address TEXT NOT NULL,
+  content_type TEXT NOT NULL DEFAULT '',
+  content_encoding TEXT NOT NULL DEFAULT '',
+  payload_hash TEXT NOT NULL DEFAULT ''
 );
 
 CREATE INDEX idx_entities_owner_address ON entities(owner_address);
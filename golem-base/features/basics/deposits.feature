@@ -0,0 +1,28 @@
+Feature: L1 deposits
+
+  Storage transactions can also be sent as deposits on L1. Deposits are
+  force-included by the rollup, so the owner can keep writing their entities
+  even if the sequencer does not include their transactions.
+
+  Scenario: creating an entity with a deposit
+    When I deposit a transaction from L1 to create an entity
+    Then the entity should be created
+    And the sender should be the owner of the entity
+    And the write-ahead log for the create should be created
+
+  Scenario: creating a named entity with a deposit
+    When I deposit a transaction from L1 to create an entity named "config" in the namespace "app"
+    Then the entity should have the key derived from the name "config" in the namespace "app"
+    And the sender should be the owner of the entity
+
+  Scenario: updating an entity with a deposit
+    Given I have created an entity
+    When I deposit a transaction from L1 to update the entity, changing the payload
+    Then the payload of the entity should be changed
+    And the write-ahead log for the update should be created
+
+  Scenario: deleting an entity with a deposit
+    Given I have created an entity
+    When I deposit a transaction from L1 to delete the entity
+    Then the entity should be deleted
+    And the write-ahead log for the delete should be created
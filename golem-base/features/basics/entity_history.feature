@@ -0,0 +1,16 @@
+Feature: entity history
+
+  Scenario: retrieving previous revisions of an entity
+    Given I have created an entity
+    And I submit a transaction to update the entity, changing the paylod
+    When I retrieve the history of the entity
+    Then the history should contain the operations "create, update"
+    And the payload of revision 0 should be "test payload"
+    And the payload of revision 1 should be "new payload"
+
+  Scenario: retrieving the history of a deleted entity
+    Given I have created an entity
+    And I submit a transaction to delete the entity
+    When I retrieve the history of the entity
+    Then the history should contain the operations "create, delete"
+    And the payload of revision 0 should be "test payload"
@@ -0,0 +1,14 @@
+Feature: entity proofs
+  A proof of an entity lets a client check it against the state root of a block,
+  without trusting the node that served it.
+
+  Scenario: proving an existing entity
+    Given I have created an entity
+    When I request a proof of the entity
+    Then the proof should verify against the state root of the block
+
+  Scenario: proving a deleted entity
+    Given I have created an entity
+    And I submit a transaction to delete the entity
+    When I request a proof of the entity
+    Then the proof should show that the entity does not exist
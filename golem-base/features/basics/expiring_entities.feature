@@ -0,0 +1,16 @@
+Feature: expiring entities
+
+  Scenario: listing the entities expiring in a range of blocks page by page
+    Given I have an entity "e1" with string annotations:
+      | foo | bar |
+    And I have an entity "e2" with string annotations:
+      | foo | bar |
+    And I have an entity "e3" with string annotations:
+      | foo | bar |
+    When I list the entities expiring in the next 200 blocks with a page size of 2
+    Then I should get 3 expiring entities in 2 pages
+
+  Scenario: listing the expiring entities of another owner
+    Given I have created an entity
+    When I list the entities of another owner expiring in the next 200 blocks
+    Then I should get 0 expiring entities in 1 pages
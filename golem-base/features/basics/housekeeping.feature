@@ -1,12 +1,12 @@
 Feature: housekeeping
-  Housekeeping transaction is automatically added as the first transaction in the block.
-  It deletes expired entities from the state.
+  Housekeeping runs once per block, before the transactions of the block, even when it has none.
+  It deletes expired entities from the state, its logs make up the housekeeping receipt of the block.
   
-  Scenario: housekeeping transaction is automatically added as the first transaction in the block
+  Scenario: expirations are reported like any other log
     Given I have enough funds to pay for the transaction
+    And there is an entity that will expire in the next block
     When there is a new block
-    Then the housekeeping transaction should be submitted
-    And the housekeeping transaction should be successful
+    Then the deletion of the expired entity should be returned by eth_getLogs
 
   Scenario: deleting expired entities
     Given I have enough funds to pay for the transaction
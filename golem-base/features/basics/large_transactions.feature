@@ -5,3 +5,9 @@ Feature: large transactions
   #   Given I have enough funds to pay for the transaction
   #   When submit a transaction to create an entity of 256K
   #   Then the entity creation should not fail
+
+  Scenario: uploading a large entity in chunks
+    Given I have enough funds to pay for the transaction
+    When I upload an entity of 256K in chunks of 64K
+    Then the payload of the uploaded entity should be 256K
+    And the write-ahead log should contain the uploaded entity of 256K
@@ -0,0 +1,19 @@
+Feature: named entities
+
+  Scenario: creating a named entity
+    When I create an entity named "config" in the namespace "app"
+    Then the entity should have the key derived from the name "config" in the namespace "app"
+    And I should be able to resolve the name "config" in the namespace "app" to the entity
+
+  Scenario: creating a named entity twice
+    Given I have created an entity named "config" in the namespace "app"
+    When I create an entity named "config" in the namespace "app"
+    Then I should see an error containing "transaction failed"
+    And the number of entities should be 1
+
+  Scenario: re-creating a deleted named entity
+    Given I have created an entity named "config" in the namespace "app"
+    And I submit a transaction to delete the entity
+    When I create an entity named "config" in the namespace "app"
+    Then the entity should have the key derived from the name "config" in the namespace "app"
+    And the number of entities should be 1
@@ -0,0 +1,10 @@
+Feature: owner usage
+
+  Scenario: usage of an owner after creating an entity
+    Given I have created an entity
+    Then the usage of the owner should be 1 entities, 12 payload bytes and 2 annotations
+
+  Scenario: usage of an owner after deleting an entity
+    Given I have created an entity
+    When I submit a transaction to delete the entity
+    Then the usage of the owner should be 0 entities, 0 payload bytes and 0 annotations
@@ -39,3 +39,41 @@ Feature: Search
       key = 8e
       """
     Then I should see an error containing "unexpected token"
+
+  Scenario: finding entities that have an annotation
+    Given I have an entity "e1" with string annotations:
+      | foo | bar |
+    And I have an entity "e2" with numeric annotations:
+      | foo | 42 |
+    And I have an entity "e3" with string annotations:
+      | baz | bar |
+    When I search for entities with the query
+      """
+      has(foo)
+      """
+    Then I should find 2 entities
+
+  Scenario: finding entities with one of several values
+    Given I have an entity "e1" with string annotations:
+      | foo | bar |
+    And I have an entity "e2" with string annotations:
+      | foo | baz |
+    And I have an entity "e3" with string annotations:
+      | foo | qux |
+    When I search for entities with the query
+      """
+      foo in ("bar", "qux")
+      """
+    Then I should find 2 entities
+
+  Scenario: finding entities by tag
+    Given I have an entity "e1" with tags "color":
+      | red  |
+      | blue |
+    And I have an entity "e2" with tags "color":
+      | green |
+    When I search for entities with the query
+      """
+      color = "blue" || color in ("green")
+      """
+    Then I should find 2 entities
@@ -0,0 +1,181 @@
+// Package golembasetest runs a Golem Base node in-process, for tests of code that talks to Golem Base.
+//
+// The node runs a developer chain driven by a simulated beacon, like `geth --dev`.
+// Housekeeping runs before the first transaction of every block, the golembase JSON-RPC API is
+// available and the write-ahead log is written to a temporary directory.
+// Blocks are only mined on Commit and AdvanceBlocks, unless WithAutoCommit is used.
+// Deposit simulates transactions sent from L1, which are force-included in the next block.
+package golembasetest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync/atomic"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/eth"
+	"github.com/jeffcogswell/golembase-op-geth/eth/catalyst"
+	"github.com/jeffcogswell/golembase-op-geth/eth/ethconfig"
+	"github.com/jeffcogswell/golembase-op-geth/eth/filters"
+	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/node"
+	"github.com/jeffcogswell/golembase-op-geth/p2p"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+)
+
+// Node is an in-process Golem Base node.
+type Node struct {
+	stack  *node.Node
+	eth    *eth.Ethereum
+	beacon *catalyst.SimulatedBeacon
+
+	// Client and RPCClient are connected to the node in-process.
+	Client    *ethclient.Client
+	RPCClient *rpc.Client
+
+	// WALDir is the directory of the write-ahead log, it is removed on Close.
+	WALDir string
+
+	deposits atomic.Uint64 // number of deposits, for unique source hashes
+}
+
+// New starts a node with a developer genesis that additionally allocates alloc.
+func New(alloc types.GenesisAlloc, options ...Option) (_ *Node, err error) {
+	walDir, err := os.MkdirTemp("", "golembase-wal")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create write-ahead log dir: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(walDir)
+		}
+	}()
+
+	genesis := core.DeveloperGenesisBlock(ethconfig.Defaults.Miner.GasCeil, nil)
+	for addr, account := range alloc {
+		genesis.Alloc[addr] = account
+	}
+
+	cfg := &config{
+		nodeConf: node.DefaultConfig,
+		ethConf:  ethconfig.Defaults,
+	}
+	cfg.nodeConf.DataDir = ""
+	cfg.nodeConf.P2P = p2p.Config{NoDiscovery: true}
+	cfg.nodeConf.IPCPath = ""
+	cfg.nodeConf.HTTPHost = ""
+	cfg.nodeConf.WSHost = ""
+	cfg.nodeConf.GolemBaseWriteAheadLogDir = walDir
+	cfg.nodeConf.GolemBaseHistory = true
+
+	cfg.ethConf.Genesis = genesis
+	cfg.ethConf.NetworkId = genesis.Config.ChainID.Uint64()
+	cfg.ethConf.SyncMode = ethconfig.FullSync
+	cfg.ethConf.TxPool.NoLocals = true
+
+	for _, option := range options {
+		option(cfg)
+	}
+
+	stack, err := node.New(&cfg.nodeConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create node: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			stack.Close()
+		}
+	}()
+
+	backend, err := eth.New(stack, &cfg.ethConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create eth backend: %w", err)
+	}
+
+	filterSystem := filters.NewFilterSystem(backend.APIBackend, filters.Config{})
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "eth",
+		Service:   filters.NewFilterAPI(filterSystem),
+	}})
+
+	beacon, err := catalyst.NewSimulatedBeacon(0, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create simulated beacon: %w", err)
+	}
+	if cfg.autoCommit {
+		// with a period of 0, the dev API mines a block as soon as a transaction is pending
+		catalyst.RegisterSimulatedBeaconAPIs(stack, beacon)
+	}
+	stack.RegisterLifecycle(beacon)
+
+	err = stack.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start node: %w", err)
+	}
+
+	rpcClient := stack.Attach()
+
+	return &Node{
+		stack:     stack,
+		eth:       backend,
+		beacon:    beacon,
+		Client:    ethclient.NewClient(rpcClient),
+		RPCClient: rpcClient,
+		WALDir:    walDir,
+	}, nil
+}
+
+// Ethereum returns the Ethereum service of the node.
+func (n *Node) Ethereum() *eth.Ethereum {
+	return n.eth
+}
+
+// HTTPEndpoint returns the URL of the JSON-RPC HTTP server, it requires WithHTTP.
+func (n *Node) HTTPEndpoint() string {
+	return n.stack.HTTPEndpoint()
+}
+
+// Commit mines a block with the pending transactions and returns its hash.
+func (n *Node) Commit() common.Hash {
+	return n.beacon.Commit()
+}
+
+// AdvanceBlocks mines count blocks, e.g. to let entities expire.
+// Pending transactions are included in the first block.
+func (n *Node) AdvanceBlocks(count uint64) common.Hash {
+	head := n.eth.BlockChain().CurrentBlock().Hash()
+	for range count {
+		head = n.beacon.Commit()
+	}
+	return head
+}
+
+// Deposit queues a deposit transaction from from to to, as if from had sent it to the
+// OptimismPortal on L1, and returns its hash. Like the deposits derived by a rollup node,
+// it is included at the start of the next block, regardless of the transaction pool;
+// it does not trigger a block with WithAutoCommit, use Commit to include it.
+func (n *Node) Deposit(from common.Address, to common.Address, gas uint64, data []byte) common.Hash {
+	sourceHash := crypto.Keccak256Hash([]byte("golembasetest.deposit"), binary.BigEndian.AppendUint64(nil, n.deposits.Add(1)))
+
+	return n.beacon.AddDeposit(&types.DepositTx{
+		SourceHash: sourceHash,
+		From:       from,
+		To:         &to,
+		Value:      new(big.Int),
+		Gas:        gas,
+		Data:       data,
+	})
+}
+
+// Close stops the node and removes the write-ahead log.
+func (n *Node) Close() error {
+	n.RPCClient.Close()
+	err := n.stack.Close()
+	return errors.Join(err, os.RemoveAll(n.WALDir))
+}
@@ -0,0 +1,171 @@
+package golembasetest_test
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/ethclient"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/golembasetest"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeExpiresEntities(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	n, err := golembasetest.New(types.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}})
+	require.NoError(t, err)
+	defer n.Close()
+
+	data, err := rlp.EncodeToBytes(&storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 2, Payload: []byte("hello")}},
+	})
+	require.NoError(t, err)
+
+	chainID, err := n.Client.ChainID(ctx)
+	require.NoError(t, err)
+
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(5e9),
+		Gas:       1_000_000,
+		To:        &address.GolemBaseStorageProcessorAddress,
+		Data:      data,
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Client.SendTransaction(ctx, tx))
+
+	n.Commit()
+
+	receipt, err := n.Client.TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	entityKey := receipt.Logs[0].Topics[1]
+
+	count := func() uint64 {
+		var count uint64
+		require.NoError(t, n.RPCClient.CallContext(ctx, &count, "golembase_getEntityCount"))
+		return count
+	}
+
+	require.Equal(t, uint64(1), count())
+
+	// the entity expires at the block it was created in plus its TTL
+	n.AdvanceBlocks(1)
+	require.Equal(t, uint64(1), count())
+	n.AdvanceBlocks(1)
+	require.Equal(t, uint64(0), count())
+
+	var keys []common.Hash
+	require.NoError(t, n.RPCClient.CallContext(ctx, &keys, "golembase_getEntitiesOfOwner", sender))
+	require.NotContains(t, keys, entityKey)
+
+	entries, err := os.ReadDir(n.WALDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+}
+
+func TestNodeDeposit(t *testing.T) {
+	ctx := context.Background()
+
+	// the L1 sender does not need any funds on L2
+	sender := common.HexToAddress("0x1234")
+
+	n, err := golembasetest.New(nil)
+	require.NoError(t, err)
+	defer n.Close()
+
+	data, err := rlp.EncodeToBytes(&storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 100, Payload: []byte("hello")}},
+	})
+	require.NoError(t, err)
+
+	txHash := n.Deposit(sender, address.GolemBaseStorageProcessorAddress, 1_000_000, data)
+	n.Commit()
+
+	receipt, err := n.Client.TransactionReceipt(ctx, txHash)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Len(t, receipt.Logs, 1)
+	require.Equal(t, storagetx.GolemBaseStorageEntityCreated, receipt.Logs[0].Topics[0])
+
+	var keys []common.Hash
+	require.NoError(t, n.RPCClient.CallContext(ctx, &keys, "golembase_getEntitiesOfOwner", sender))
+	require.Equal(t, []common.Hash{receipt.Logs[0].Topics[1]}, keys)
+}
+
+func TestNodeAutoCommit(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	n, err := golembasetest.New(types.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}}, golembasetest.WithAutoCommit(), golembasetest.WithHTTP())
+	require.NoError(t, err)
+	defer n.Close()
+
+	chainID, err := n.Client.ChainID(ctx)
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x1234")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(5e9),
+		Gas:       21_000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Client.SendTransaction(ctx, tx))
+
+	require.Eventually(t, func() bool {
+		_, err := n.Client.TransactionReceipt(ctx, tx.Hash())
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Contains(t, n.HTTPEndpoint(), "127.0.0.1")
+}
+
+func TestNewHeadsOverHTTP(t *testing.T) {
+	ctx := context.Background()
+
+	n, err := golembasetest.New(nil, golembasetest.WithHTTP())
+	require.NoError(t, err)
+	defer n.Close()
+
+	client, err := ethclient.DialContext(ctx, n.HTTPEndpoint())
+	require.NoError(t, err)
+	defer client.Close()
+
+	heads := make(chan *types.Header, 1)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	n.Commit()
+
+	select {
+	case head := <-heads:
+		require.Equal(t, uint64(1), head.Number.Uint64())
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for new head")
+	}
+}
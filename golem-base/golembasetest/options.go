@@ -0,0 +1,48 @@
+package golembasetest
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/eth/ethconfig"
+	"github.com/jeffcogswell/golembase-op-geth/node"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+)
+
+type config struct {
+	nodeConf   node.Config
+	ethConf    ethconfig.Config
+	autoCommit bool
+}
+
+// Option configures a Node.
+type Option func(cfg *config)
+
+// WithAutoCommit mines a block as soon as a transaction is pending, like `geth --dev --dev.period 0`.
+// Commit and AdvanceBlocks can still be used to mine additional blocks.
+func WithAutoCommit() Option {
+	return func(cfg *config) {
+		cfg.autoCommit = true
+	}
+}
+
+// WithHTTP serves the eth and golembase JSON-RPC APIs over HTTP on a random local port,
+// e.g. for tools started as separate processes.
+func WithHTTP() Option {
+	return func(cfg *config) {
+		cfg.nodeConf.HTTPHost = "127.0.0.1"
+		cfg.nodeConf.HTTPPort = 0
+		cfg.nodeConf.HTTPModules = []string{"eth", "net", "web3", "debug", "golembase"}
+	}
+}
+
+// WithChainConfig modifies the chain config of the genesis, e.g. to set the Golem Base quotas.
+func WithChainConfig(modify func(chainConfig *params.ChainConfig)) Option {
+	return func(cfg *config) {
+		modify(cfg.ethConf.Genesis.Config)
+	}
+}
+
+// WithConfig modifies the node and eth configs directly.
+func WithConfig(modify func(nodeConf *node.Config, ethConf *ethconfig.Config)) Option {
+	return func(cfg *config) {
+		modify(&cfg.nodeConf, &cfg.ethConf)
+	}
+}
@@ -0,0 +1,132 @@
+// Package golemtracing defines hooks into the execution of storage transactions and housekeeping.
+//
+// Storage operations are executed outside of the EVM, so the EVM tracing hooks only see
+// a call to the storage processor. These hooks report the individual operations, the indexes
+// they update and the storage slots they read and write. They are set on core/tracing.Hooks,
+// so both native and live tracers can consume them.
+package golemtracing
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+)
+
+// Operation types.
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+	Extend = "extend"
+	// Expire is a deletion of an expired entity by housekeeping.
+	Expire = "expire"
+
+	// Chunked uploads, see storagetx.BeginUpload.
+	BeginUpload    = "beginUpload"
+	AppendChunk    = "appendChunk"
+	FinalizeUpload = "finalizeUpload"
+	// AbandonUpload is a removal of an abandoned upload by housekeeping.
+	AbandonUpload = "abandonUpload"
+)
+
+// Operation identifies a storage operation.
+// Index is the position of the operation among the operations of the same type in the transaction.
+// The EntityKey of a create operation is derived while the operation runs, it is only set when the operation ends.
+type Operation struct {
+	Type      string
+	Index     int
+	EntityKey common.Hash
+}
+
+type (
+	// StorageTxStartHook is called before a storage transaction is executed.
+	// data is the RLP encoded storage transaction.
+	StorageTxStartHook = func(txHash common.Hash, sender common.Address, data []byte)
+
+	// StorageTxEndHook is called after a storage transaction is executed.
+	// If err is not nil, the transaction failed and all its state changes are reverted.
+	StorageTxEndHook = func(err error)
+
+	// HousekeepingStartHook is called before the housekeeping of a block is executed,
+	// once per block and before its transactions.
+	HousekeepingStartHook = func(blockNumber uint64)
+
+	// HousekeepingEndHook is called after the housekeeping of a block is executed.
+	// If err is not nil, housekeeping failed and the block is invalid.
+	HousekeepingEndHook = func(err error)
+
+	// OperationStartHook is called before an operation is applied.
+	OperationStartHook = func(op Operation)
+
+	// OperationEndHook is called after an operation is applied, err is the reason the operation failed.
+	OperationEndHook = func(op Operation, err error)
+
+	// IndexEnterHook is called when an operation starts updating an index (or the metadata, payload
+	// or usage counters of an entity). setKey identifies the index in the state.
+	IndexEnterHook = func(index string, setKey common.Hash)
+
+	// IndexExitHook is called when an operation is done updating the index entered last.
+	IndexExitHook = func()
+
+	// SlotReadHook is called when a storage slot of the storage processor is read.
+	SlotReadHook = func(slot common.Hash, value common.Hash)
+
+	// SlotWriteHook is called when a storage slot of the storage processor is written.
+	SlotWriteHook = func(slot common.Hash, prev common.Hash, value common.Hash)
+)
+
+type Hooks struct {
+	OnStorageTxStart StorageTxStartHook
+	OnStorageTxEnd   StorageTxEndHook
+	OnOperationStart OperationStartHook
+	OnOperationEnd   OperationEndHook
+	OnIndexEnter     IndexEnterHook
+	OnIndexExit      IndexExitHook
+	OnSlotRead       SlotReadHook
+	OnSlotWrite      SlotWriteHook
+
+	OnHousekeepingStart HousekeepingStartHook
+	OnHousekeepingEnd   HousekeepingEndHook
+}
+
+// StorageTxStart calls OnStorageTxStart if it is set, h may be nil.
+func (h *Hooks) StorageTxStart(txHash common.Hash, sender common.Address, data []byte) {
+	if h != nil && h.OnStorageTxStart != nil {
+		h.OnStorageTxStart(txHash, sender, data)
+	}
+}
+
+// StorageTxEnd calls OnStorageTxEnd if it is set, h may be nil.
+func (h *Hooks) StorageTxEnd(err error) {
+	if h != nil && h.OnStorageTxEnd != nil {
+		h.OnStorageTxEnd(err)
+	}
+}
+
+// HousekeepingStart calls OnHousekeepingStart if it is set, h may be nil.
+func (h *Hooks) HousekeepingStart(blockNumber uint64) {
+	if h != nil && h.OnHousekeepingStart != nil {
+		h.OnHousekeepingStart(blockNumber)
+	}
+}
+
+// HousekeepingEnd calls OnHousekeepingEnd if it is set, h may be nil.
+func (h *Hooks) HousekeepingEnd(err error) {
+	if h != nil && h.OnHousekeepingEnd != nil {
+		h.OnHousekeepingEnd(err)
+	}
+}
+
+// TraceOperation runs fn between the OnOperationStart and OnOperationEnd hooks.
+// fn can set the EntityKey of the operation once it is known. h may be nil.
+func (h *Hooks) TraceOperation(op Operation, fn func(op *Operation) error) error {
+	if h != nil && h.OnOperationStart != nil {
+		h.OnOperationStart(op)
+	}
+
+	err := fn(&op)
+
+	if h != nil && h.OnOperationEnd != nil {
+		h.OnOperationEnd(op, err)
+	}
+
+	return err
+}
@@ -0,0 +1,63 @@
+package golemtracing
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+)
+
+// tracingAccess reports the slots of the storage processor read and written through it.
+type tracingAccess struct {
+	storageutil.StateAccess
+	hooks *Hooks
+}
+
+// WrapAccess returns a state access reporting to the hooks.
+// If hooks is nil, access is returned as is.
+func WrapAccess(access storageutil.StateAccess, hooks *Hooks) storageutil.StateAccess {
+	if hooks == nil {
+		return access
+	}
+	return &tracingAccess{
+		StateAccess: access,
+		hooks:       hooks,
+	}
+}
+
+// HooksOf returns the hooks of an access returned by WrapAccess, or nil.
+func HooksOf(access storageutil.StateAccess) *Hooks {
+	ta, ok := access.(*tracingAccess)
+	if !ok {
+		return nil
+	}
+	return ta.hooks
+}
+
+func (a *tracingAccess) GetState(addr common.Address, slot common.Hash) common.Hash {
+	value := a.StateAccess.GetState(addr, slot)
+	if addr == storageutil.GolemDBAddress && a.hooks.OnSlotRead != nil {
+		a.hooks.OnSlotRead(slot, value)
+	}
+	return value
+}
+
+func (a *tracingAccess) SetState(addr common.Address, slot common.Hash, value common.Hash) common.Hash {
+	prev := a.StateAccess.SetState(addr, slot, value)
+	if addr == storageutil.GolemDBAddress && a.hooks.OnSlotWrite != nil {
+		a.hooks.OnSlotWrite(slot, prev, value)
+	}
+	return prev
+}
+
+// EnterIndex implements storageutil.IndexTracer.
+func (a *tracingAccess) EnterIndex(index string, setKey common.Hash) {
+	if a.hooks.OnIndexEnter != nil {
+		a.hooks.OnIndexEnter(index, setKey)
+	}
+}
+
+// ExitIndex implements storageutil.IndexTracer.
+func (a *tracingAccess) ExitIndex() {
+	if a.hooks.OnIndexExit != nil {
+		a.hooks.OnIndexExit()
+	}
+}
@@ -0,0 +1,24 @@
+package golemtype
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+)
+
+type ExpiringEntity struct {
+	Key            common.Hash    `json:"key"`
+	ExpiresAtBlock uint64         `json:"expiresAtBlock"`
+	Owner          common.Address `json:"owner"`
+}
+
+// ExpiringEntitiesCursor points at the position in the expiration index where the next page starts.
+type ExpiringEntitiesCursor struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	Offset      uint64 `json:"offset"`
+}
+
+// ExpiringEntitiesPage is a page of entities ordered by their expiration block.
+// Next is nil when there are no more entities in the requested range.
+type ExpiringEntitiesPage struct {
+	Entities []ExpiringEntity        `json:"entities"`
+	Next     *ExpiringEntitiesCursor `json:"next,omitempty"`
+}
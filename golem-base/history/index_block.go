@@ -0,0 +1,267 @@
+package history
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/ethdb"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/wal"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+// indexer keeps the revisions and latest states of the entities touched while indexing a block,
+// so that reads see the writes that are still pending in the batch.
+type indexer struct {
+	db        ethdb.KeyValueStore
+	batch     ethdb.Batch
+	revisions map[common.Hash][]Revision
+	states    map[common.Hash]*EntityState
+}
+
+func (ix *indexer) getRevisions(key common.Hash) ([]Revision, error) {
+	revisions, ok := ix.revisions[key]
+	if ok {
+		return revisions, nil
+	}
+
+	revisions, err := GetEntityHistory(ix.db, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ix.revisions[key] = revisions
+	return revisions, nil
+}
+
+// lastState returns the state of the entity after its latest revision,
+// or an empty state if the entity has no recorded revisions.
+func (ix *indexer) lastState(key common.Hash) (EntityState, error) {
+	state, ok := ix.states[key]
+	if ok {
+		return *state, nil
+	}
+
+	revisions, err := ix.getRevisions(key)
+	if err != nil {
+		return EntityState{}, err
+	}
+
+	if len(revisions) == 0 {
+		return EntityState{}, nil
+	}
+
+	state, err = GetEntityAt(ix.db, key, revisions[len(revisions)-1].Revision)
+	if err != nil {
+		return EntityState{}, err
+	}
+
+	return *state, nil
+}
+
+func (ix *indexer) addRevision(key common.Hash, block *types.Block, txHash common.Hash, operation string, state EntityState) error {
+	revisions, err := ix.getRevisions(key)
+	if err != nil {
+		return err
+	}
+
+	revision := uint64(len(revisions))
+	if len(revisions) > 0 {
+		revision = revisions[len(revisions)-1].Revision + 1
+	}
+
+	ix.revisions[key] = append(revisions, Revision{
+		Revision:    revision,
+		BlockNumber: block.NumberU64(),
+		BlockHash:   block.Hash(),
+		TxHash:      txHash,
+		Operation:   operation,
+	})
+
+	ix.states[key] = &state
+
+	d, err := rlp.EncodeToBytes(&state)
+	if err != nil {
+		return fmt.Errorf("failed to encode state of entity %s: %w", key.Hex(), err)
+	}
+
+	return ix.batch.Put(stateKey(key, revision), d)
+}
+
+// rollbackBlock drops all revisions that were recorded for the block.
+func (ix *indexer) rollbackBlock(blockNumber uint64) error {
+	has, err := ix.db.Has(blockEntitiesKey(blockNumber))
+	if err != nil {
+		return fmt.Errorf("failed to read entities of block %d: %w", blockNumber, err)
+	}
+	if !has {
+		// no entities were touched in this block
+		return nil
+	}
+
+	d, err := ix.db.Get(blockEntitiesKey(blockNumber))
+	if err != nil {
+		return fmt.Errorf("failed to read entities of block %d: %w", blockNumber, err)
+	}
+
+	keys := []common.Hash{}
+	err = rlp.DecodeBytes(d, &keys)
+	if err != nil {
+		return fmt.Errorf("failed to decode entities of block %d: %w", blockNumber, err)
+	}
+
+	for _, key := range keys {
+		revisions, err := ix.getRevisions(key)
+		if err != nil {
+			return err
+		}
+
+		kept := slices.DeleteFunc(slices.Clone(revisions), func(r Revision) bool {
+			return r.BlockNumber >= blockNumber
+		})
+
+		for _, r := range revisions[len(kept):] {
+			err = ix.batch.Delete(stateKey(key, r.Revision))
+			if err != nil {
+				return fmt.Errorf("failed to delete revision %d of entity %s: %w", r.Revision, key.Hex(), err)
+			}
+		}
+
+		ix.revisions[key] = kept
+		delete(ix.states, key)
+	}
+
+	return ix.batch.Delete(blockEntitiesKey(blockNumber))
+}
+
+// IndexBlock records the revisions caused by the storage operations of the block.
+// If the block replaces already indexed blocks (a chain reorganisation), the revisions
+// of the replaced blocks are dropped first.
+func IndexBlock(db ethdb.KeyValueStore, block *types.Block, chainID *big.Int, receipts []*types.Receipt, parentState wal.ParentState) error {
+
+	ix := &indexer{
+		db:        db,
+		batch:     db.NewBatch(),
+		revisions: map[common.Hash][]Revision{},
+		states:    map[common.Hash]*EntityState{},
+	}
+
+	head, indexed := IndexedHead(db)
+	for n := head; indexed && n >= block.NumberU64(); n-- {
+		err := ix.rollbackBlock(n)
+		if err != nil {
+			return fmt.Errorf("failed to roll back history of block %d: %w", n, err)
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	touched := []common.Hash{}
+
+	err := wal.ForEachOperation(block, chainID, receipts, parentState, func(txHash common.Hash, op wal.Operation) error {
+		var (
+			key       common.Hash
+			operation string
+			state     EntityState
+		)
+
+		switch {
+		case op.Create != nil:
+			key = op.Create.EntityKey
+			operation = OperationCreate
+			state = EntityState{
+				Owner:              op.Create.Owner,
+				ExpiresAtBlock:     op.Create.ExpiresAtBlock,
+				Payload:            op.Create.Payload,
+				StringAnnotations:  op.Create.StringAnnotations,
+				NumericAnnotations: op.Create.NumericAnnotations,
+				TagAnnotations:     op.Create.TagAnnotations,
+				ContentMetaData:    op.Create.ContentMetaData,
+			}
+		case op.Update != nil:
+			key = op.Update.EntityKey
+			operation = OperationUpdate
+			previous, err := ix.lastState(key)
+			if err != nil {
+				return err
+			}
+			state = EntityState{
+				Owner:              previous.Owner,
+				ExpiresAtBlock:     op.Update.ExpiresAtBlock,
+				Payload:            op.Update.Payload,
+				StringAnnotations:  op.Update.StringAnnotations,
+				NumericAnnotations: op.Update.NumericAnnotations,
+				TagAnnotations:     op.Update.TagAnnotations,
+				ContentMetaData:    op.Update.ContentMetaData,
+			}
+		case op.Extend != nil:
+			key = op.Extend.EntityKey
+			operation = OperationExtend
+			previous, err := ix.lastState(key)
+			if err != nil {
+				return err
+			}
+			state = previous
+			state.ExpiresAtBlock = op.Extend.NewExpiresAt
+		case op.Delete != nil:
+			key = *op.Delete
+			operation = OperationDelete
+			previous, err := ix.lastState(key)
+			if err != nil {
+				return err
+			}
+			state = EntityState{
+				Deleted: true,
+				Owner:   previous.Owner,
+			}
+		default:
+			return nil
+		}
+
+		if !slices.Contains(touched, key) {
+			touched = append(touched, key)
+		}
+
+		return ix.addRevision(key, block, txHash, operation, state)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index operations of block %d: %w", block.NumberU64(), err)
+	}
+
+	for key, revisions := range ix.revisions {
+		if len(revisions) == 0 {
+			err = ix.batch.Delete(revisionsKey(key))
+		} else {
+			var d []byte
+			d, err = rlp.EncodeToBytes(revisions)
+			if err == nil {
+				err = ix.batch.Put(revisionsKey(key), d)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write revisions of entity %s: %w", key.Hex(), err)
+		}
+	}
+
+	if len(touched) > 0 {
+		d, err := rlp.EncodeToBytes(touched)
+		if err != nil {
+			return fmt.Errorf("failed to encode entities of block %d: %w", block.NumberU64(), err)
+		}
+		err = ix.batch.Put(blockEntitiesKey(block.NumberU64()), d)
+		if err != nil {
+			return fmt.Errorf("failed to write entities of block %d: %w", block.NumberU64(), err)
+		}
+	}
+
+	err = ix.batch.Put(headKey, binary.BigEndian.AppendUint64(nil, block.NumberU64()))
+	if err != nil {
+		return fmt.Errorf("failed to write history head: %w", err)
+	}
+
+	return ix.batch.Write()
+}
@@ -0,0 +1,163 @@
+package history_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/rawdb"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/history"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+var chainID = big.NewInt(1337)
+
+var entityKey = common.HexToHash("0x1234")
+
+// storageBlock builds a block containing a single storage transaction
+// and a successful receipt carrying the given logs.
+func storageBlock(t *testing.T, number uint64, nonce uint64, stx *storagetx.StorageTransaction, logs ...*types.Log) (*types.Block, []*types.Receipt) {
+	t.Helper()
+
+	key, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	require.NoError(t, err)
+
+	data, err := rlp.EncodeToBytes(stx)
+	require.NoError(t, err)
+
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		Gas:       1_000_000,
+		GasFeeCap: big.NewInt(1),
+		To:        &address.GolemBaseStorageProcessorAddress,
+		Data:      data,
+	})
+	require.NoError(t, err)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(number)}).WithBody(types.Body{Transactions: []*types.Transaction{tx}})
+
+	return block, []*types.Receipt{{Status: types.ReceiptStatusSuccessful, Logs: logs}}
+}
+
+func expiresAtLog(topic common.Hash, expiresAt uint64) *types.Log {
+	data := make([]byte, 32)
+	uint256.NewInt(expiresAt).PutUint256(data)
+	return &types.Log{Topics: []common.Hash{topic, entityKey}, Data: data}
+}
+
+func TestIndexBlock(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	block1, receipts1 := storageBlock(t, 1, 0, &storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 100, Payload: []byte("v1"), StringAnnotations: []entity.StringAnnotation{{Key: "k", Value: "a"}}}},
+	}, expiresAtLog(storagetx.GolemBaseStorageEntityCreated, 101))
+	require.NoError(t, history.IndexBlock(db, block1, chainID, receipts1, nil))
+
+	block2, receipts2 := storageBlock(t, 2, 1, &storagetx.StorageTransaction{
+		Update: []storagetx.Update{{EntityKey: entityKey, TTL: 100, Payload: []byte("v2")}},
+	}, expiresAtLog(storagetx.GolemBaseStorageEntityUpdated, 102))
+	require.NoError(t, history.IndexBlock(db, block2, chainID, receipts2, nil))
+
+	block3, receipts3 := storageBlock(t, 3, 2, &storagetx.StorageTransaction{
+		Delete: []common.Hash{entityKey},
+	}, &types.Log{Topics: []common.Hash{storagetx.GolemBaseStorageEntityDeleted, entityKey}})
+	require.NoError(t, history.IndexBlock(db, block3, chainID, receipts3, nil))
+
+	revisions, err := history.GetEntityHistory(db, entityKey)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	require.Equal(t, history.OperationCreate, revisions[0].Operation)
+	require.Equal(t, history.OperationUpdate, revisions[1].Operation)
+	require.Equal(t, history.OperationDelete, revisions[2].Operation)
+	require.Equal(t, block2.Transactions()[0].Hash(), revisions[1].TxHash)
+	require.Equal(t, uint64(2), revisions[1].BlockNumber)
+
+	first, err := history.GetEntityAt(db, entityKey, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), first.Payload)
+	require.Equal(t, []entity.StringAnnotation{{Key: "k", Value: "a"}}, first.StringAnnotations)
+	require.Equal(t, uint64(101), first.ExpiresAtBlock)
+
+	second, err := history.GetEntityAt(db, entityKey, 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), second.Payload)
+	require.Equal(t, first.Owner, second.Owner)
+
+	deleted, err := history.GetEntityAt(db, entityKey, 2)
+	require.NoError(t, err)
+	require.True(t, deleted.Deleted)
+
+	_, err = history.GetEntityAt(db, entityKey, 3)
+	require.ErrorIs(t, err, history.ErrRevisionNotFound)
+}
+
+func TestIndexBlockReorg(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	block1, receipts1 := storageBlock(t, 1, 0, &storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 100, Payload: []byte("v1")}},
+	}, expiresAtLog(storagetx.GolemBaseStorageEntityCreated, 101))
+	require.NoError(t, history.IndexBlock(db, block1, chainID, receipts1, nil))
+
+	block2, receipts2 := storageBlock(t, 2, 1, &storagetx.StorageTransaction{
+		Update: []storagetx.Update{{EntityKey: entityKey, TTL: 100, Payload: []byte("v2")}},
+	}, expiresAtLog(storagetx.GolemBaseStorageEntityUpdated, 102))
+	require.NoError(t, history.IndexBlock(db, block2, chainID, receipts2, nil))
+
+	// a different block 2 replaces the indexed one
+	replacement, replacementReceipts := storageBlock(t, 2, 1, &storagetx.StorageTransaction{
+		Extend: []storagetx.ExtendTTL{{EntityKey: entityKey, NumberOfBlocks: 10}},
+	}, &types.Log{
+		Topics: []common.Hash{storagetx.GolemBaseStorageEntityTTLExtended, entityKey},
+		Data:   append(uint256.NewInt(101).PaddedBytes(32), uint256.NewInt(111).PaddedBytes(32)...),
+	})
+	require.NoError(t, history.IndexBlock(db, replacement, chainID, replacementReceipts, nil))
+
+	revisions, err := history.GetEntityHistory(db, entityKey)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, history.OperationExtend, revisions[1].Operation)
+	require.Equal(t, replacement.Hash(), revisions[1].BlockHash)
+
+	extended, err := history.GetEntityAt(db, entityKey, 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), extended.Payload)
+	require.Equal(t, uint64(111), extended.ExpiresAtBlock)
+
+	head, ok := history.IndexedHead(db)
+	require.True(t, ok)
+	require.Equal(t, uint64(2), head)
+}
+
+func TestIndexBlockExpiration(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	block1, receipts1 := storageBlock(t, 1, 0, &storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 1, Payload: []byte("v1")}},
+	}, expiresAtLog(storagetx.GolemBaseStorageEntityCreated, 2))
+	require.NoError(t, history.IndexBlock(db, block1, chainID, receipts1, nil))
+
+	// the entity is deleted by housekeeping in block 2, which has no transactions,
+	// the deletion is in the housekeeping receipt of the block
+	block2 := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2), ParentHash: block1.Hash()})
+	receipts2 := []*types.Receipt{types.NewGolemBaseHousekeepingReceipt([]*types.Log{{
+		Address: address.GolemBaseStorageProcessorAddress,
+		Topics:  []common.Hash{storagetx.GolemBaseStorageEntityDeleted, entityKey},
+	}})}
+	require.NoError(t, history.IndexBlock(db, block2, chainID, receipts2, nil))
+
+	revisions, err := history.GetEntityHistory(db, entityKey)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, history.OperationDelete, revisions[1].Operation)
+	require.Equal(t, types.GolemBaseHousekeepingTxHash(2), revisions[1].TxHash)
+	require.Equal(t, uint64(2), revisions[1].BlockNumber)
+}
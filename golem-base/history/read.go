@@ -0,0 +1,71 @@
+package history
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/ethdb"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// IndexedHead returns the number of the last block that was indexed.
+// The second return value is false if no block has been indexed yet.
+func IndexedHead(db ethdb.KeyValueReader) (uint64, bool) {
+	d, err := db.Get(headKey)
+	if err != nil || len(d) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(d), true
+}
+
+// GetEntityHistory returns all recorded revisions of the entity, oldest first.
+func GetEntityHistory(db ethdb.KeyValueReader, key common.Hash) ([]Revision, error) {
+	has, err := db.Has(revisionsKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revisions of entity %s: %w", key.Hex(), err)
+	}
+	if !has {
+		return []Revision{}, nil
+	}
+
+	d, err := db.Get(revisionsKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revisions of entity %s: %w", key.Hex(), err)
+	}
+
+	revisions := []Revision{}
+	err = rlp.DecodeBytes(d, &revisions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode revisions of entity %s: %w", key.Hex(), err)
+	}
+
+	return revisions, nil
+}
+
+// GetEntityAt returns the entity as it was after the given revision was applied.
+func GetEntityAt(db ethdb.KeyValueReader, key common.Hash, revision uint64) (*EntityState, error) {
+	has, err := db.Has(stateKey(key, revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revision %d of entity %s: %w", revision, key.Hex(), err)
+	}
+	if !has {
+		return nil, fmt.Errorf("%w: revision %d of entity %s", ErrRevisionNotFound, revision, key.Hex())
+	}
+
+	d, err := db.Get(stateKey(key, revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revision %d of entity %s: %w", revision, key.Hex(), err)
+	}
+
+	state := &EntityState{}
+	err = rlp.DecodeBytes(d, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode revision %d of entity %s: %w", revision, key.Hex(), err)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,70 @@
+// Package history maintains an off-chain index of entity revisions in the node's database.
+//
+// Once an entity is updated or deleted, its previous payload and annotations are gone
+// from the state. The history index records every operation applied to an entity,
+// together with the entity as it was after the operation, so that earlier revisions
+// can be retrieved without access to archive state.
+//
+// The index is built from the same operations that are written to the write-ahead log,
+// and only covers blocks that were processed while the index was enabled.
+package history
+
+import (
+	"encoding/binary"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+)
+
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+	OperationExtend = "extend"
+)
+
+// Revision describes a single operation applied to an entity.
+type Revision struct {
+	Revision    uint64      `json:"revision"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	TxHash      common.Hash `json:"txHash"`
+	Operation   string      `json:"operation"`
+}
+
+// EntityState is the entity as it was after a revision was applied.
+// Deleted entities have no payload or annotations.
+type EntityState struct {
+	Deleted            bool                       `json:"deleted"`
+	Owner              common.Address             `json:"owner"`
+	ExpiresAtBlock     uint64                     `json:"expiresAtBlock"`
+	Payload            []byte                     `json:"payload"`
+	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
+	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations,omitempty" rlp:"optional"`
+
+	entity.ContentMetaData `rlp:"optional"`
+}
+
+var (
+	headKey           = []byte("golembase-history-head")
+	revisionsPrefix   = []byte("golembase-history-revisions-")
+	statePrefix       = []byte("golembase-history-state-")
+	blockEntityPrefix = []byte("golembase-history-block-")
+)
+
+// revisionsKey = revisionsPrefix + entityKey
+func revisionsKey(key common.Hash) []byte {
+	return append(append([]byte{}, revisionsPrefix...), key[:]...)
+}
+
+// stateKey = statePrefix + entityKey + revision (uint64 big endian)
+func stateKey(key common.Hash, revision uint64) []byte {
+	k := append(append([]byte{}, statePrefix...), key[:]...)
+	return binary.BigEndian.AppendUint64(k, revision)
+}
+
+// blockEntitiesKey = blockEntityPrefix + blockNumber (uint64 big endian)
+func blockEntitiesKey(blockNumber uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, blockEntityPrefix...), blockNumber)
+}
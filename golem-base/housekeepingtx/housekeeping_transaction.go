@@ -2,32 +2,49 @@ package housekeepingtx
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
-	"github.com/jeffcogswell/golembase-op-geth/core/tracing"
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
-	"github.com/jeffcogswell/golembase-op-geth/core/vm"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtracing"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/pendingupload"
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
+	"github.com/jeffcogswell/golembase-op-geth/params"
 )
 
-func ExecuteTransaction(blockNumber uint64, txHash common.Hash, db vm.StateDB) ([]*types.Log, error) {
+var housekeepingTimer = metrics.NewRegisteredTimer("golembase/housekeeping/duration", nil)
 
-	// create the golem base storage processor address if it doesn't exist
-	// this is needed to be able to use the state access interface
-	if !db.Exist(address.GolemBaseStorageProcessorAddress) {
-		db.CreateAccount(address.GolemBaseStorageProcessorAddress)
-		db.CreateContract(address.GolemBaseStorageProcessorAddress)
-		db.SetNonce(address.GolemBaseStorageProcessorAddress, 1, tracing.NonceChangeNewContract)
-	}
+// Execute deletes the entities that expire at the block and removes the uploads that are
+// abandoned at the block. Under the Kaolin upgrade it runs once per block, as a system
+// operation before the transactions of the block, and its logs make up the housekeeping
+// receipt of the block, see types.GolemBaseHousekeepingReceipt.
+// If maxExpirations is not zero, at most maxExpirations entities, uploads and upload chunks
+// are deleted together; the remaining ones are added to the expiration backlog and deleted in
+// the following blocks, oldest block first, before the ones of those blocks.
+// If hooks is not nil, each deletion is reported to the hooks as an expire operation
+// and each removed upload as an abandon upload operation.
+func Execute(rules params.GolemBaseRules, blockNumber uint64, db storageutil.StateAccess, maxExpirations uint64, hooks *golemtracing.Hooks) (_ []*types.Log, err error) {
+
+	defer housekeepingTimer.UpdateSince(time.Now())
+
+	hooks.HousekeepingStart(blockNumber)
+	defer func() {
+		hooks.HousekeepingEnd(err)
+	}()
+
+	access := golemtracing.WrapAccess(db, hooks)
 
 	logs := []*types.Log{}
 
 	deleteEntity := func(toDelete common.Hash) error {
 
-		err := entity.Delete(db, toDelete)
+		err := entity.Delete(rules, access, toDelete)
 		if err != nil {
 			return fmt.Errorf("failed to delete entity: %w", err)
 		}
@@ -45,14 +62,122 @@ func ExecuteTransaction(blockNumber uint64, txHash common.Hash, db vm.StateDB) (
 		return nil
 	}
 
-	for key := range entityexpiration.IteratorOfEntitiesToExpireAtBlock(db, blockNumber) {
-		err := deleteEntity(key)
+	// deleted counts the entities, uploads and upload chunks deleted against maxExpirations
+	deleted := uint64(0)
+	underLimit := func() bool {
+		return maxExpirations == 0 || deleted < maxExpirations
+	}
+
+	expired := 0
+
+	// expireAtBlock deletes the entities expiring at the block until the limit is reached.
+	// Entities are taken one at a time, since deleting an entity removes it from the set.
+	// It returns true if no entities are left to expire at the block.
+	expireAtBlock := func(expiresAt uint64) (bool, error) {
+		for underLimit() {
+			key, found := entityexpiration.NextEntityToExpireAtBlock(access, expiresAt)
+			if !found {
+				return true, nil
+			}
+
+			op := golemtracing.Operation{Type: golemtracing.Expire, Index: expired, EntityKey: key}
+			err := hooks.TraceOperation(op, func(*golemtracing.Operation) error {
+				return deleteEntity(key)
+			})
+			if err != nil {
+				return false, fmt.Errorf("failed to delete entity %s: %w", key.Hex(), err)
+			}
+
+			expired++
+			deleted++
+		}
+
+		return entityexpiration.CountEntitiesToExpireAtBlock(access, expiresAt) == 0, nil
+	}
+
+	// removeAbandonedAtBlock removes the uploads that are abandoned at the block until the limit
+	// is reached, an upload with many chunks can be removed over several blocks.
+	// Uploads are taken one at a time, since deleting an upload removes it from the set.
+	// It returns true if no uploads are left to remove at the block.
+	removed := 0
+	removeAbandonedAtBlock := func(abandonedAt uint64) (bool, error) {
+		for underLimit() {
+			key, found := pendingupload.NextAbandonedAtBlock(access, abandonedAt)
+			if !found {
+				return true, nil
+			}
+
+			maxBlobs := uint64(0)
+			if maxExpirations != 0 {
+				maxBlobs = maxExpirations - deleted
+			}
+
+			gone := false
+			op := golemtracing.Operation{Type: golemtracing.AbandonUpload, Index: removed, EntityKey: key}
+			err := hooks.TraceOperation(op, func(*golemtracing.Operation) error {
+				n, done, err := pendingupload.DeleteAbandoned(access, key, maxBlobs)
+				deleted += n
+				gone = done
+				return err
+			})
+			if err != nil {
+				return false, fmt.Errorf("failed to delete abandoned upload %s: %w", key.Hex(), err)
+			}
+
+			if !gone {
+				return false, nil
+			}
+
+			logs = append(logs, &types.Log{
+				Address:     address.GolemBaseStorageProcessorAddress,
+				Topics:      []common.Hash{storagetx.GolemBaseStorageUploadAbandoned, key},
+				Data:        []byte{},
+				BlockNumber: blockNumber,
+			})
+			removed++
+		}
+
+		_, found := pendingupload.NextAbandonedAtBlock(access, abandonedAt)
+		return !found, nil
+	}
+
+	backlog := slices.Sorted(entityexpiration.IterateBacklog(access))
+
+	for _, expiresAt := range backlog {
+		expiredAll, err := expireAtBlock(expiresAt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to delete entity %s: %w", key.Hex(), err)
+			return nil, err
 		}
+
+		removedAll, err := removeAbandonedAtBlock(expiresAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if expiredAll && removedAll {
+			err = entityexpiration.RemoveFromBacklog(access, expiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to remove block %d from the expiration backlog: %w", expiresAt, err)
+			}
+		}
+	}
+
+	expiredAll, err := expireAtBlock(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	removedAll, err := removeAbandonedAtBlock(blockNumber)
+	if err != nil {
+		return nil, err
 	}
 
-	entityexpiration.ClearEntitiesToExpireAtBlock(db, blockNumber)
+	if !expiredAll || !removedAll {
+		err = entityexpiration.AddToBacklog(access, blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add block %d to the expiration backlog: %w", blockNumber, err)
+		}
+	}
 
 	return logs, nil
 }
@@ -0,0 +1,159 @@
+package housekeepingtx_test
+
+import (
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/housekeepingtx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/pendingupload"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/stretchr/testify/require"
+)
+
+var kaolin = params.GolemBaseRules{IsKaolin: true}
+
+func newStateWithEntities(t *testing.T, expiresAt map[common.Hash]uint64) *state.StateDB {
+	t.Helper()
+
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	for key, block := range expiresAt {
+		err = entity.Store(kaolin, db, key, common.HexToAddress("0x1"), entity.EntityMetaData{
+			ExpiresAtBlock:     block,
+			StringAnnotations:  []entity.StringAnnotation{{Key: "k", Value: "v"}},
+			NumericAnnotations: []entity.NumericAnnotation{},
+		}, []byte("payload"))
+		require.NoError(t, err)
+	}
+
+	return db
+}
+
+func TestExecuteUnbounded(t *testing.T) {
+	db := newStateWithEntities(t, map[common.Hash]uint64{
+		common.HexToHash("0x1"): 10,
+		common.HexToHash("0x2"): 10,
+		common.HexToHash("0x3"): 10,
+		common.HexToHash("0x4"): 11,
+	})
+
+	logs, err := housekeepingtx.Execute(kaolin, 10, db, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 3)
+
+	require.Equal(t, uint64(0), entityexpiration.CountEntitiesToExpireAtBlock(db, 10))
+	require.False(t, entityexpiration.HasBacklog(db))
+	require.Equal(t, []common.Hash{common.HexToHash("0x4")}, collect(allentities.Iterate(db)))
+}
+
+func TestExecuteCarriesOverBacklog(t *testing.T) {
+	db := newStateWithEntities(t, map[common.Hash]uint64{
+		common.HexToHash("0x1"): 10,
+		common.HexToHash("0x2"): 10,
+		common.HexToHash("0x3"): 10,
+		common.HexToHash("0x4"): 11,
+	})
+
+	logs, err := housekeepingtx.Execute(kaolin, 10, db, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.True(t, entityexpiration.HasBacklog(db))
+	require.Equal(t, uint64(1), entityexpiration.BacklogSize(db))
+
+	// the entity left over from block 10 is deleted before the one expiring at block 11
+	logs, err = housekeepingtx.Execute(kaolin, 11, db, 1, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, uint64(0), entityexpiration.CountEntitiesToExpireAtBlock(db, 10))
+	require.Equal(t, uint64(1), entityexpiration.BacklogSize(db))
+	require.Equal(t, []common.Hash{common.HexToHash("0x4")}, collect(allentities.Iterate(db)))
+
+	logs, err = housekeepingtx.Execute(kaolin, 12, db, 1, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, common.HexToHash("0x4"), logs[0].Topics[1])
+	require.False(t, entityexpiration.HasBacklog(db))
+	require.Empty(t, collect(allentities.Iterate(db)))
+}
+
+func TestExecuteRemovesAbandonedUploads(t *testing.T) {
+	db := newStateWithEntities(t, map[common.Hash]uint64{})
+
+	key := common.HexToHash("0x1")
+	err := pendingupload.Begin(db, key, 1, pendingupload.PendingUpload{Owner: common.HexToAddress("0x1"), Size: 10})
+	require.NoError(t, err)
+	_, err = pendingupload.AppendChunk(db, key, 5, 0, []byte("01234"))
+	require.NoError(t, err)
+
+	// appending the chunk postponed the abandonment
+	logs, err := housekeepingtx.Execute(kaolin, 1+pendingupload.Timeout, db, 0, nil)
+	require.NoError(t, err)
+	require.Empty(t, logs)
+	require.True(t, pendingupload.Contains(db, key))
+
+	logs, err = housekeepingtx.Execute(kaolin, 5+pendingupload.Timeout, db, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, []common.Hash{storagetx.GolemBaseStorageUploadAbandoned, key}, logs[0].Topics)
+	require.False(t, pendingupload.Contains(db, key))
+	require.Empty(t, collect(pendingupload.Iterate(db)))
+	require.Equal(t, ownerusage.Usage{}, ownerusage.Get(db, common.HexToAddress("0x1")))
+}
+
+func TestExecuteLimitsAbandonedUploadRemoval(t *testing.T) {
+	db := newStateWithEntities(t, map[common.Hash]uint64{
+		common.HexToHash("0x2"): 1 + pendingupload.Timeout,
+	})
+
+	key := common.HexToHash("0x1")
+	err := pendingupload.Begin(db, key, 1, pendingupload.PendingUpload{Owner: common.HexToAddress("0x1"), Size: 10})
+	require.NoError(t, err)
+	for _, chunk := range []string{"012", "345", "6789"} {
+		upload, err := pendingupload.Get(db, key)
+		require.NoError(t, err)
+		_, err = pendingupload.AppendChunk(db, key, 1, upload.Received, []byte(chunk))
+		require.NoError(t, err)
+	}
+
+	// the expiring entity and the last chunk use up the limit
+	logs, err := housekeepingtx.Execute(kaolin, 1+pendingupload.Timeout, db, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, common.HexToHash("0x2"), logs[0].Topics[1])
+	require.True(t, entityexpiration.HasBacklog(db))
+	upload, err := pendingupload.Get(db, key)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), upload.Chunks)
+	require.Equal(t, uint64(6), upload.Received)
+
+	// the remaining chunks use up the limit of the next block
+	logs, err = housekeepingtx.Execute(kaolin, 2+pendingupload.Timeout, db, 2, nil)
+	require.NoError(t, err)
+	require.Empty(t, logs)
+	require.True(t, entityexpiration.HasBacklog(db))
+	require.True(t, pendingupload.Contains(db, key))
+
+	logs, err = housekeepingtx.Execute(kaolin, 3+pendingupload.Timeout, db, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, []common.Hash{storagetx.GolemBaseStorageUploadAbandoned, key}, logs[0].Topics)
+	require.False(t, entityexpiration.HasBacklog(db))
+	require.False(t, pendingupload.Contains(db, key))
+	require.Empty(t, collect(pendingupload.Iterate(db)))
+}
+
+func collect(seq func(yield func(common.Hash) bool)) []common.Hash {
+	out := []common.Hash{}
+	for key := range seq {
+		out = append(out, key)
+	}
+	return out
+}
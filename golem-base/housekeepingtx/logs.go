@@ -0,0 +1,28 @@
+package housekeepingtx
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+)
+
+// SplitLogs splits the logs of the receipt of a transaction into the logs of housekeeping
+// and the logs of the transaction itself.
+//
+// Before the Kaolin upgrade, housekeeping runs in every deposit transaction, so the storage
+// processor logs of a transaction that is not a storage transaction are housekeeping logs.
+// Under Kaolin, housekeeping has a receipt of its own, see types.GolemBaseHousekeepingReceipt,
+// and the logs of a transaction are all its own.
+func SplitLogs(tx *types.Transaction, receipt *types.Receipt) (housekeeping, own []*types.Log) {
+	if tx.To() != nil && *tx.To() == address.GolemBaseStorageProcessorAddress {
+		return nil, receipt.Logs
+	}
+
+	for _, l := range receipt.Logs {
+		if l.Address == address.GolemBaseStorageProcessorAddress {
+			housekeeping = append(housekeeping, l)
+		} else {
+			own = append(own, l)
+		}
+	}
+	return housekeeping, own
+}
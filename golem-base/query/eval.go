@@ -1,10 +1,25 @@
 package query
 
-import "github.com/jeffcogswell/golembase-op-geth/common"
+import (
+	"iter"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+)
+
+// EntitySet is a set of entity keys that can be sized, probed and iterated
+// without materialising it.
+type EntitySet interface {
+	// Size returns the number of keys in the set. For sets composed of other sets,
+	// it returns an upper bound that is used to estimate the cost of iterating the set.
+	Size() (uint64, error)
+	Contains(key common.Hash) (bool, error)
+	Iterate() iter.Seq2[common.Hash, error]
+}
 
 type DataSource interface {
-	GetKeysForStringAnnotation(annotation string, value string) ([]common.Hash, error)
-	GetKeysForNumericAnnotation(annotation string, value uint64) ([]common.Hash, error)
+	StringAnnotationSet(annotation string, value string) EntitySet
+	NumericAnnotationSet(annotation string, value uint64) EntitySet
+	AnnotationKeySet(annotation string) EntitySet
 }
 
 type Evaluator interface {
@@ -1,6 +1,9 @@
 package query_test
 
 import (
+	"iter"
+	"math/big"
+	"slices"
 	"testing"
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
@@ -13,14 +16,53 @@ import (
 type fakeDataSource struct {
 	stringAnnotations  map[string]map[string][]common.Hash
 	numericAnnotations map[string]map[uint64][]common.Hash
+	iterated           int
 }
 
-func (f *fakeDataSource) GetKeysForStringAnnotation(key, value string) ([]common.Hash, error) {
-	return f.stringAnnotations[key][value], nil
+// sliceSet is an EntitySet backed by a slice, counting how many keys were iterated.
+type sliceSet struct {
+	keys     []common.Hash
+	iterated *int
 }
 
-func (f *fakeDataSource) GetKeysForNumericAnnotation(key string, value uint64) ([]common.Hash, error) {
-	return f.numericAnnotations[key][value], nil
+func (s sliceSet) Size() (uint64, error) {
+	return uint64(len(s.keys)), nil
+}
+
+func (s sliceSet) Contains(key common.Hash) (bool, error) {
+	return slices.Contains(s.keys, key), nil
+}
+
+func (s sliceSet) Iterate() iter.Seq2[common.Hash, error] {
+	return func(yield func(common.Hash, error) bool) {
+		for _, key := range s.keys {
+			if s.iterated != nil {
+				*s.iterated++
+			}
+			if !yield(key, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (f *fakeDataSource) StringAnnotationSet(key, value string) query.EntitySet {
+	return sliceSet{keys: f.stringAnnotations[key][value], iterated: &f.iterated}
+}
+
+func (f *fakeDataSource) NumericAnnotationSet(key string, value uint64) query.EntitySet {
+	return sliceSet{keys: f.numericAnnotations[key][value], iterated: &f.iterated}
+}
+
+func (f *fakeDataSource) AnnotationKeySet(key string) query.EntitySet {
+	keys := []common.Hash{}
+	for _, values := range f.stringAnnotations[key] {
+		keys = append(keys, values...)
+	}
+	for _, values := range f.numericAnnotations[key] {
+		keys = append(keys, values...)
+	}
+	return sliceSet{keys: keys, iterated: &f.iterated}
 }
 
 func TestEqualExpr(t *testing.T) {
@@ -140,3 +182,102 @@ func TestParenthesesExpr(t *testing.T) {
 		common.HexToHash("0x5"),
 	}, res)
 }
+
+func TestHasExpr(t *testing.T) {
+	ds := &fakeDataSource{
+		stringAnnotations: map[string]map[string][]common.Hash{
+			"name": {
+				"abc": []common.Hash{common.HexToHash("0x1")},
+				"def": []common.Hash{common.HexToHash("0x2")},
+			},
+		},
+		numericAnnotations: map[string]map[uint64][]common.Hash{
+			"age": {
+				123: []common.Hash{common.HexToHash("0x2"), common.HexToHash("0x3")},
+			},
+		},
+	}
+
+	expr, err := query.Parse(`has(name) && has(age)`)
+	require.NoError(t, err)
+
+	res, err := expr.Evaluate(ds)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{common.HexToHash("0x2")}, res)
+}
+
+func TestInExpr(t *testing.T) {
+	ds := &fakeDataSource{
+		stringAnnotations: map[string]map[string][]common.Hash{
+			"name": {
+				"abc": []common.Hash{common.HexToHash("0x1")},
+				"def": []common.Hash{common.HexToHash("0x2")},
+				"ghi": []common.Hash{common.HexToHash("0x3")},
+			},
+		},
+		numericAnnotations: map[string]map[uint64][]common.Hash{
+			"name": {
+				123: []common.Hash{common.HexToHash("0x4")},
+			},
+		},
+	}
+
+	expr, err := query.Parse(`name in ("abc", "ghi", 123)`)
+	require.NoError(t, err)
+
+	res, err := expr.Evaluate(ds)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []common.Hash{
+		common.HexToHash("0x1"),
+		common.HexToHash("0x3"),
+		common.HexToHash("0x4"),
+	}, res)
+}
+
+func TestAndExprIteratesSmallestOperand(t *testing.T) {
+	broad := []common.Hash{}
+	for i := range 100 {
+		broad = append(broad, common.BigToHash(big.NewInt(int64(i))))
+	}
+
+	ds := &fakeDataSource{
+		stringAnnotations: map[string]map[string][]common.Hash{
+			"type": {
+				"log": broad,
+			},
+			"name": {
+				"abc": []common.Hash{common.BigToHash(big.NewInt(42))},
+			},
+		},
+		numericAnnotations: map[string]map[uint64][]common.Hash{},
+	}
+
+	expr, err := query.Parse(`type = "log" && name = "abc"`)
+	require.NoError(t, err)
+
+	res, err := expr.Evaluate(ds)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{common.BigToHash(big.NewInt(42))}, res)
+	require.Equal(t, 1, ds.iterated)
+}
+
+func TestIterateStopsEarly(t *testing.T) {
+	ds := &fakeDataSource{
+		stringAnnotations: map[string]map[string][]common.Hash{
+			"type": {
+				"log": []common.Hash{common.HexToHash("0x1"), common.HexToHash("0x2"), common.HexToHash("0x3")},
+			},
+		},
+		numericAnnotations: map[string]map[uint64][]common.Hash{},
+	}
+
+	expr, err := query.Parse(`type = "log"`)
+	require.NoError(t, err)
+
+	for _, err := range expr.Iterate(ds) {
+		require.NoError(t, err)
+		break
+	}
+
+	require.Equal(t, 1, ds.iterated)
+}
@@ -2,6 +2,7 @@ package query
 
 import (
 	"errors"
+	"iter"
 
 	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
@@ -16,6 +17,7 @@ var lex = lexer.MustSimple([]lexer.SimpleRule{
 	{Name: "And", Pattern: `&&`},
 	{Name: "Or", Pattern: `\|\|`},
 	{Name: "Eq", Pattern: `=`},
+	{Name: "Comma", Pattern: `,`},
 	{Name: "String", Pattern: `"(?:[^"\\]|\\.)*"`},
 	{Name: "Number", Pattern: `[0-9]+`},
 	{Name: "Ident", Pattern: `[a-zA-Z_][a-zA-Z0-9_]*`},
@@ -26,54 +28,66 @@ type Expression struct {
 	Or *OrExpression `parser:"@@"`
 }
 
-func (e *Expression) Evaluate(ds DataSource) ([]common.Hash, error) {
-	return e.Or.Evaluate(ds)
-}
-
-// OrExpression handles expressions connected with ||.
-type OrExpression struct {
-	Left  *AndExpression `parser:"@@"`
-	Right []*OrRHS       `parser:"@@*"`
+// Plan turns the expression into a set of entities that is evaluated lazily.
+// Operands of && are reordered by their estimated size, so that the most selective
+// predicate is iterated and the others are only probed.
+func (e *Expression) Plan(ds DataSource) (EntitySet, error) {
+	return e.Or.Plan(ds)
 }
 
-func union(a, b []common.Hash) []common.Hash {
-	result := make([]common.Hash, 0, len(a)+len(b))
-	seen := make(map[common.Hash]bool)
+// Iterate lazily yields the keys of the entities matching the expression.
+// Iteration stops after the first error.
+func (e *Expression) Iterate(ds DataSource) iter.Seq2[common.Hash, error] {
+	return func(yield func(common.Hash, error) bool) {
+		set, err := e.Plan(ds)
+		if err != nil {
+			yield(common.Hash{}, err)
+			return
+		}
 
-	// Add all hashes from a
-	for _, hash := range a {
-		if !seen[hash] {
-			seen[hash] = true
-			result = append(result, hash)
+		for key, err := range set.Iterate() {
+			if !yield(key, err) || err != nil {
+				return
+			}
 		}
 	}
+}
 
-	// Add any new hashes from b
-	for _, hash := range b {
-		if !seen[hash] {
-			seen[hash] = true
-			result = append(result, hash)
+// Evaluate returns the keys of all entities matching the expression.
+func (e *Expression) Evaluate(ds DataSource) ([]common.Hash, error) {
+	res := []common.Hash{}
+	for key, err := range e.Iterate(ds) {
+		if err != nil {
+			return nil, err
 		}
+		res = append(res, key)
 	}
+	return res, nil
+}
 
-	return result
+// OrExpression handles expressions connected with ||.
+type OrExpression struct {
+	Left  *AndExpression `parser:"@@"`
+	Right []*OrRHS       `parser:"@@*"`
 }
 
-func (e *OrExpression) Evaluate(ds DataSource) ([]common.Hash, error) {
-	res, err := e.Left.Evaluate(ds)
+func (e *OrExpression) Plan(ds DataSource) (EntitySet, error) {
+	left, err := e.Left.Plan(ds)
 	if err != nil {
 		return nil, err
 	}
 
+	operands := []EntitySet{left}
+
 	for _, rhs := range e.Right {
-		rh, err := rhs.Evaluate(ds)
+		rh, err := rhs.Plan(ds)
 		if err != nil {
 			return nil, err
 		}
-		res = union(res, rh)
+		operands = append(operands, rh)
 	}
 
-	return res, nil
+	return newOrSet(operands), nil
 }
 
 // OrRHS represents the right-hand side of an OR.
@@ -82,8 +96,8 @@ type OrRHS struct {
 	Expr *AndExpression `parser:"@@"`
 }
 
-func (e *OrRHS) Evaluate(ds DataSource) ([]common.Hash, error) {
-	return e.Expr.Evaluate(ds)
+func (e *OrRHS) Plan(ds DataSource) (EntitySet, error) {
+	return e.Expr.Plan(ds)
 }
 
 // AndExpression handles expressions connected with &&.
@@ -92,42 +106,23 @@ type AndExpression struct {
 	Right []*AndRHS  `parser:"@@*"`
 }
 
-func intersect(a, b []common.Hash) []common.Hash {
-	result := make([]common.Hash, 0)
-	seen := make(map[common.Hash]bool)
-
-	// Build map of hashes in a
-	for _, hash := range a {
-		seen[hash] = true
-	}
-
-	// Check which hashes from b exist in map
-	for _, hash := range b {
-		if seen[hash] {
-			result = append(result, hash)
-		}
-	}
-
-	return result
-
-}
-
-func (e *AndExpression) Evaluate(ds DataSource) ([]common.Hash, error) {
-
-	res, err := e.Left.Evaluate(ds)
+func (e *AndExpression) Plan(ds DataSource) (EntitySet, error) {
+	left, err := e.Left.Plan(ds)
 	if err != nil {
 		return nil, err
 	}
 
+	operands := []EntitySet{left}
+
 	for _, rhs := range e.Right {
-		rh, err := rhs.Evaluate(ds)
+		rh, err := rhs.Plan(ds)
 		if err != nil {
 			return nil, err
 		}
-		res = intersect(res, rh)
+		operands = append(operands, rh)
 	}
 
-	return res, nil
+	return newAndSet(operands), nil
 }
 
 // AndRHS represents the right-hand side of an AND.
@@ -136,22 +131,60 @@ type AndRHS struct {
 	Expr *EqualExpr `parser:"@@"`
 }
 
-func (e *AndRHS) Evaluate(ds DataSource) ([]common.Hash, error) {
-	return e.Expr.Evaluate(ds)
+func (e *AndRHS) Plan(ds DataSource) (EntitySet, error) {
+	return e.Expr.Plan(ds)
 }
 
-// EqualExpr can be either an equality or a parenthesized expression.
+// EqualExpr can be either an equality, an existence or set membership test, or a parenthesized expression.
 type EqualExpr struct {
 	Paren  *Expression `parser:"  \"(\" @@ \")\""`
+	Has    *Has        `parser:"| @@"`
+	In     *In         `parser:"| @@"`
 	Assign *Equality   `parser:"| @@"`
 }
 
-func (e *EqualExpr) Evaluate(ds DataSource) ([]common.Hash, error) {
-	if e.Paren != nil {
-		return e.Paren.Evaluate(ds)
+func (e *EqualExpr) Plan(ds DataSource) (EntitySet, error) {
+	switch {
+	case e.Paren != nil:
+		return e.Paren.Plan(ds)
+	case e.Has != nil:
+		return e.Has.Plan(ds)
+	case e.In != nil:
+		return e.In.Plan(ds)
+	}
+
+	return e.Assign.Plan(ds)
+}
+
+// Has represents an existence test (e.g. has(name)).
+// It matches entities with any annotation with the key, regardless of its type and value.
+type Has struct {
+	Var string `parser:"\"has\" \"(\" @Ident \")\""`
+}
+
+func (e *Has) Plan(ds DataSource) (EntitySet, error) {
+	return ds.AnnotationKeySet(e.Var), nil
+}
+
+// In represents a set membership test (e.g. name in ("a", "b", 3)).
+// It matches entities whose annotation with the key equals any of the values.
+type In struct {
+	Var    string   `parser:"@Ident \"in\" \"(\""`
+	Values []*Value `parser:"@@ (\",\" @@)* \")\""`
+}
+
+func (e *In) Plan(ds DataSource) (EntitySet, error) {
+	operands := []EntitySet{}
+
+	for _, value := range e.Values {
+		set, err := (&Equality{Var: e.Var, Value: value}).Plan(ds)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, set)
 	}
 
-	return e.Assign.Evaluate(ds)
+	return newOrSet(operands), nil
 }
 
 // Equality represents a simple equality (e.g. name = 123).
@@ -160,14 +193,14 @@ type Equality struct {
 	Value *Value `parser:"@@"`
 }
 
-func (e *Equality) Evaluate(ds DataSource) ([]common.Hash, error) {
+func (e *Equality) Plan(ds DataSource) (EntitySet, error) {
 
 	if e.Value.String != nil {
-		return ds.GetKeysForStringAnnotation(e.Var, *e.Value.String)
+		return ds.StringAnnotationSet(e.Var, *e.Value.String), nil
 	}
 
 	if e.Value.Number != nil {
-		return ds.GetKeysForNumericAnnotation(e.Var, *e.Value.Number)
+		return ds.NumericAnnotationSet(e.Var, *e.Value.Number), nil
 	}
 
 	return nil, errors.New("unsupported value type")
@@ -183,6 +216,8 @@ var Parser = participle.MustBuild[Expression](
 	participle.Lexer(lex),
 	participle.Elide("Whitespace"),
 	participle.Unquote("String"),
+	// has(...) and in (...) share their first token with equalities
+	participle.UseLookahead(3),
 )
 
 func Parse(s string) (*Expression, error) {
@@ -213,6 +213,58 @@ func TestParse(t *testing.T) {
 		)
 	})
 
+	t.Run("has", func(t *testing.T) {
+		v, err := query.Parse(`has(name)`)
+		require.NoError(t, err)
+
+		require.Equal(
+			t,
+			&query.Expression{
+				Or: &query.OrExpression{
+					Left: &query.AndExpression{
+						Left: &query.EqualExpr{
+							Has: &query.Has{
+								Var: "name",
+							},
+						},
+					},
+				},
+			},
+			v,
+		)
+	})
+
+	t.Run("annotation named has", func(t *testing.T) {
+		v, err := query.Parse(`has = "x"`)
+		require.NoError(t, err)
+		require.Equal(t, "has", v.Or.Left.Left.Assign.Var)
+	})
+
+	t.Run("in", func(t *testing.T) {
+		v, err := query.Parse(`name in ("a", 2)`)
+		require.NoError(t, err)
+
+		require.Equal(
+			t,
+			&query.Expression{
+				Or: &query.OrExpression{
+					Left: &query.AndExpression{
+						Left: &query.EqualExpr{
+							In: &query.In{
+								Var: "name",
+								Values: []*query.Value{
+									{String: pointerOf("a")},
+									{Number: pointerOf(uint64(2))},
+								},
+							},
+						},
+					},
+				},
+			},
+			v,
+		)
+	})
+
 	t.Run("invalid expression", func(t *testing.T) {
 		_, err := query.Parse(`key = 8e`)
 		require.Error(t, err, `1:8: unexpected token "e"`)
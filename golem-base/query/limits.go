@@ -0,0 +1,55 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+)
+
+var ErrLimitExceeded = errors.New("query limit exceeded")
+
+// Limits bounds the work done to evaluate a single query.
+// A zero value means there is no limit.
+type Limits struct {
+	MaxSlotsRead uint64
+	Timeout      time.Duration
+}
+
+// budget tracks the work done by a query against its limits.
+type budget struct {
+	limits    Limits
+	deadline  time.Time
+	slotsRead uint64
+}
+
+func newBudget(limits Limits) *budget {
+	b := &budget{limits: limits}
+	if limits.Timeout != 0 {
+		b.deadline = time.Now().Add(limits.Timeout)
+	}
+	return b
+}
+
+func (b *budget) check() error {
+	if b.limits.MaxSlotsRead != 0 && b.slotsRead > b.limits.MaxSlotsRead {
+		return fmt.Errorf("%w: read more than %d storage slots", ErrLimitExceeded, b.limits.MaxSlotsRead)
+	}
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		return fmt.Errorf("%w: took longer than %s", ErrLimitExceeded, b.limits.Timeout)
+	}
+	return nil
+}
+
+// countingAccess counts the storage slots read through it.
+type countingAccess struct {
+	storageutil.StateAccess
+	budget *budget
+}
+
+func (a *countingAccess) GetState(addr common.Address, key common.Hash) common.Hash {
+	a.budget.slotsRead++
+	return a.StateAccess.GetState(addr, key)
+}
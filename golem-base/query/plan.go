@@ -0,0 +1,161 @@
+package query
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+)
+
+// andSet is the intersection of its operands.
+// It is iterated by walking the smallest operand and probing the others,
+// so the cost is bounded by the most selective predicate.
+type andSet struct {
+	operands []EntitySet
+}
+
+func (s *andSet) Size() (uint64, error) {
+	var size uint64
+	for i, operand := range s.operands {
+		operandSize, err := operand.Size()
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 || operandSize < size {
+			size = operandSize
+		}
+	}
+	return size, nil
+}
+
+func (s *andSet) Contains(key common.Hash) (bool, error) {
+	for _, operand := range s.operands {
+		contains, err := operand.Contains(key)
+		if err != nil || !contains {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// ordered returns the operands sorted by their size, smallest first.
+func (s *andSet) ordered() ([]EntitySet, error) {
+	type sized struct {
+		set  EntitySet
+		size uint64
+	}
+
+	operands := make([]sized, len(s.operands))
+	for i, operand := range s.operands {
+		size, err := operand.Size()
+		if err != nil {
+			return nil, err
+		}
+		operands[i] = sized{set: operand, size: size}
+	}
+
+	slices.SortStableFunc(operands, func(a, b sized) int {
+		return cmp.Compare(a.size, b.size)
+	})
+
+	ordered := make([]EntitySet, len(operands))
+	for i, operand := range operands {
+		ordered[i] = operand.set
+	}
+	return ordered, nil
+}
+
+func (s *andSet) Iterate() iter.Seq2[common.Hash, error] {
+	return func(yield func(common.Hash, error) bool) {
+		operands, err := s.ordered()
+		if err != nil {
+			yield(common.Hash{}, err)
+			return
+		}
+
+		rest := &andSet{operands: operands[1:]}
+
+		for key, err := range operands[0].Iterate() {
+			if err != nil {
+				yield(common.Hash{}, err)
+				return
+			}
+
+			contains, err := rest.Contains(key)
+			if err != nil {
+				yield(common.Hash{}, err)
+				return
+			}
+
+			if contains && !yield(key, nil) {
+				return
+			}
+		}
+	}
+}
+
+// orSet is the union of its operands.
+type orSet struct {
+	operands []EntitySet
+}
+
+func (s *orSet) Size() (uint64, error) {
+	var size uint64
+	for _, operand := range s.operands {
+		operandSize, err := operand.Size()
+		if err != nil {
+			return 0, err
+		}
+		size += operandSize
+	}
+	return size, nil
+}
+
+func (s *orSet) Contains(key common.Hash) (bool, error) {
+	for _, operand := range s.operands {
+		contains, err := operand.Contains(key)
+		if err != nil || contains {
+			return contains, err
+		}
+	}
+	return false, nil
+}
+
+func (s *orSet) Iterate() iter.Seq2[common.Hash, error] {
+	return func(yield func(common.Hash, error) bool) {
+		seen := map[common.Hash]struct{}{}
+
+		for _, operand := range s.operands {
+			for key, err := range operand.Iterate() {
+				if err != nil {
+					yield(common.Hash{}, err)
+					return
+				}
+
+				if _, found := seen[key]; found {
+					continue
+				}
+				seen[key] = struct{}{}
+
+				if !yield(key, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func newAndSet(operands []EntitySet) EntitySet {
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	return &andSet{operands: operands}
+}
+
+func newOrSet(operands []EntitySet) EntitySet {
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	return &orSet{operands: operands}
+}
@@ -0,0 +1,83 @@
+package query
+
+import (
+	"iter"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/annotationindex"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entitiesofowner"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+)
+
+// stateDataSource reads the annotation indexes from the state.
+type stateDataSource struct {
+	access *countingAccess
+}
+
+// NewStateDataSource returns a DataSource that reads the annotation indexes from the state.
+// All sets it returns fail with ErrLimitExceeded once the query exceeds the limits.
+func NewStateDataSource(access storageutil.StateAccess, limits Limits) DataSource {
+	return &stateDataSource{
+		access: &countingAccess{StateAccess: access, budget: newBudget(limits)},
+	}
+}
+
+// NewOwnerSet returns the set of the entities of the owner in the state. Like the sets of
+// a DataSource returned by NewStateDataSource, it fails once reading it exceeds the limits.
+func NewOwnerSet(access storageutil.StateAccess, owner common.Address, limits Limits) EntitySet {
+	return &keysetSet{
+		access: &countingAccess{StateAccess: access, budget: newBudget(limits)},
+		setKey: entitiesofowner.OwnerEntitiesKey(owner),
+	}
+}
+
+func (ds *stateDataSource) StringAnnotationSet(annotation string, value string) EntitySet {
+	return &keysetSet{access: ds.access, setKey: annotationindex.StringAnnotationIndexKey(annotation, value)}
+}
+
+func (ds *stateDataSource) NumericAnnotationSet(annotation string, value uint64) EntitySet {
+	return &keysetSet{access: ds.access, setKey: annotationindex.NumericAnnotationIndexKey(annotation, value)}
+}
+
+func (ds *stateDataSource) AnnotationKeySet(annotation string) EntitySet {
+	return &keysetSet{access: ds.access, setKey: annotationindex.AnnotationKeyIndexKey(annotation)}
+}
+
+// keysetSet is an EntitySet backed by a keyset in the state.
+type keysetSet struct {
+	access *countingAccess
+	setKey common.Hash
+}
+
+func (s *keysetSet) Size() (uint64, error) {
+	err := s.access.budget.check()
+	if err != nil {
+		return 0, err
+	}
+	return keyset.Size(s.access, s.setKey).Uint64(), nil
+}
+
+func (s *keysetSet) Contains(key common.Hash) (bool, error) {
+	err := s.access.budget.check()
+	if err != nil {
+		return false, err
+	}
+	return keyset.ContainsValue(s.access, s.setKey, key), nil
+}
+
+func (s *keysetSet) Iterate() iter.Seq2[common.Hash, error] {
+	return func(yield func(common.Hash, error) bool) {
+		for key := range keyset.Iterate(s.access, s.setKey) {
+			err := s.access.budget.check()
+			if err != nil {
+				yield(common.Hash{}, err)
+				return
+			}
+
+			if !yield(key, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package query_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/query"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/annotationindex"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateDataSourceLimits(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	for i := range 50 {
+		key := common.BigToHash(big.NewInt(int64(i)))
+		require.NoError(t, keyset.AddValue(db, annotationindex.StringAnnotationIndexKey("type", "log"), key))
+		if i%10 == 0 {
+			require.NoError(t, keyset.AddValue(db, annotationindex.NumericAnnotationIndexKey("rank", 1), key))
+		}
+	}
+
+	expr, err := query.Parse(`type = "log" && rank = 1`)
+	require.NoError(t, err)
+
+	// iterating the 5 entities with rank 1 and probing the others fits in the limit
+	res, err := expr.Evaluate(query.NewStateDataSource(db, query.Limits{MaxSlotsRead: 20}))
+	require.NoError(t, err)
+	require.Len(t, res, 5)
+
+	// iterating all entities of type log does not
+	expr, err = query.Parse(`type = "log"`)
+	require.NoError(t, err)
+
+	_, err = expr.Evaluate(query.NewStateDataSource(db, query.Limits{MaxSlotsRead: 20}))
+	require.ErrorIs(t, err, query.ErrLimitExceeded)
+}
@@ -0,0 +1,78 @@
+package storagetx_test
+
+import (
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStoresContentMetaData(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+	payload := []byte(`{"hello":"world"}`)
+	content := entity.ContentMetaData{
+		ContentType:     "application/json",
+		ContentEncoding: entity.ContentEncodingIdentity,
+		PayloadHash:     crypto.Keccak256Hash(payload),
+	}
+
+	tx := &storagetx.StorageTransaction{Create: []storagetx.Create{{TTL: 100, Payload: payload, ContentMetaData: content}}}
+
+	// the content metadata survives the encoding of the transaction
+	d, err := rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+	decoded := &storagetx.StorageTransaction{}
+	require.NoError(t, rlp.DecodeBytes(d, decoded))
+	require.Equal(t, content, decoded.Create[0].ContentMetaData)
+
+	logs, err := decoded.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+	key := logs[0].Topics[1]
+
+	md, err := entity.GetEntityMetaData(db, key)
+	require.NoError(t, err)
+	require.Equal(t, content, md.ContentMetaData)
+
+	// updates replace the content metadata
+	tx = &storagetx.StorageTransaction{Update: []storagetx.Update{{EntityKey: key, TTL: 100, Payload: []byte("plain")}}}
+	_, err = tx.Run(kaolin, 2, common.HexToHash("0x2"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+
+	md, err = entity.GetEntityMetaData(db, key)
+	require.NoError(t, err)
+	require.Equal(t, entity.ContentMetaData{}, md.ContentMetaData)
+}
+
+func TestRunValidatesContentMetaData(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+
+	tx := &storagetx.StorageTransaction{Create: []storagetx.Create{{
+		TTL:             100,
+		Payload:         []byte("hello"),
+		ContentMetaData: entity.ContentMetaData{PayloadHash: crypto.Keccak256Hash([]byte("world"))},
+	}}}
+	_, err = tx.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, ownerusage.Quota{})
+	require.ErrorContains(t, err, "invalid content metadata")
+
+	tx = &storagetx.StorageTransaction{BeginUpload: []storagetx.BeginUpload{{
+		TTL:             100,
+		Size:            5,
+		PayloadHash:     crypto.Keccak256Hash([]byte("hello")),
+		ContentEncoding: "br",
+	}}}
+	_, err = tx.Run(kaolin, 1, common.HexToHash("0x2"), owner, db, ownerusage.Quota{})
+	require.ErrorContains(t, err, "unsupported content encoding")
+}
@@ -0,0 +1,48 @@
+package storagetx_test
+
+import (
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRejectsExpiredEntities(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+
+	tx := &storagetx.StorageTransaction{Create: []storagetx.Create{
+		{TTL: 1, Payload: []byte("expired")},
+		{TTL: 10, Payload: []byte("alive")},
+	}}
+	logs, err := tx.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+	expired, alive := logs[0].Topics[1], logs[1].Topics[1]
+
+	// housekeeping of block 2 did not get to the entity
+	require.NoError(t, entityexpiration.AddToBacklog(db, 2))
+	require.True(t, entity.IsExpired(db, expired))
+	require.False(t, entity.IsExpired(db, alive))
+	require.Equal(t, []common.Hash{alive}, entity.WithoutExpired(db, []common.Hash{expired, alive}))
+
+	tx = &storagetx.StorageTransaction{Update: []storagetx.Update{{EntityKey: expired, TTL: 10, Payload: []byte("revived")}}}
+	_, err = tx.Run(kaolin, 3, common.HexToHash("0x2"), owner, db, ownerusage.Quota{})
+	require.ErrorContains(t, err, "has expired")
+
+	tx = &storagetx.StorageTransaction{Extend: []storagetx.ExtendTTL{{EntityKey: expired, NumberOfBlocks: 10}}}
+	_, err = tx.Run(kaolin, 3, common.HexToHash("0x3"), owner, db, ownerusage.Quota{})
+	require.ErrorContains(t, err, "has expired")
+
+	// an expired entity can still be deleted by its owner
+	tx = &storagetx.StorageTransaction{Delete: []common.Hash{expired}}
+	_, err = tx.Run(kaolin, 3, common.HexToHash("0x4"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+}
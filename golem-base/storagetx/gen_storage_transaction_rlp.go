@@ -2,6 +2,8 @@
 
 package storagetx
 
+import "github.com/jeffcogswell/golembase-op-geth/common"
+import "github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
 import "github.com/jeffcogswell/golembase-op-geth/rlp"
 import "io"
 
@@ -29,47 +31,189 @@ func (obj *StorageTransaction) EncodeRLP(_w io.Writer) error {
 			w.ListEnd(_tmp9)
 		}
 		w.ListEnd(_tmp7)
+		_tmp10 := _tmp2.Namespace != ""
+		_tmp11 := _tmp2.Name != ""
+		_tmp12 := len(_tmp2.TagAnnotations) > 0
+		_tmp13 := _tmp2.ContentMetaData != (entity.ContentMetaData{})
+		if _tmp10 || _tmp11 || _tmp12 || _tmp13 {
+			w.WriteString(_tmp2.Namespace)
+		}
+		if _tmp11 || _tmp12 || _tmp13 {
+			w.WriteString(_tmp2.Name)
+		}
+		if _tmp12 || _tmp13 {
+			_tmp14 := w.List()
+			for _, _tmp15 := range _tmp2.TagAnnotations {
+				_tmp16 := w.List()
+				w.WriteString(_tmp15.Key)
+				_tmp17 := w.List()
+				for _, _tmp18 := range _tmp15.Values {
+					w.WriteString(_tmp18)
+				}
+				w.ListEnd(_tmp17)
+				w.ListEnd(_tmp16)
+			}
+			w.ListEnd(_tmp14)
+		}
+		if _tmp13 {
+			_tmp19 := w.List()
+			_tmp20 := _tmp2.ContentMetaData.ContentType != ""
+			_tmp21 := _tmp2.ContentMetaData.ContentEncoding != ""
+			_tmp22 := _tmp2.ContentMetaData.PayloadHash != (common.Hash{})
+			if _tmp20 || _tmp21 || _tmp22 {
+				w.WriteString(_tmp2.ContentMetaData.ContentType)
+			}
+			if _tmp21 || _tmp22 {
+				w.WriteString(_tmp2.ContentMetaData.ContentEncoding)
+			}
+			if _tmp22 {
+				w.WriteBytes(_tmp2.ContentMetaData.PayloadHash[:])
+			}
+			w.ListEnd(_tmp19)
+		}
 		w.ListEnd(_tmp3)
 	}
 	w.ListEnd(_tmp1)
-	_tmp10 := w.List()
-	for _, _tmp11 := range obj.Update {
-		_tmp12 := w.List()
-		w.WriteBytes(_tmp11.EntityKey[:])
-		w.WriteUint64(_tmp11.TTL)
-		w.WriteBytes(_tmp11.Payload)
-		_tmp13 := w.List()
-		for _, _tmp14 := range _tmp11.StringAnnotations {
-			_tmp15 := w.List()
-			w.WriteString(_tmp14.Key)
-			w.WriteString(_tmp14.Value)
-			w.ListEnd(_tmp15)
+	_tmp23 := w.List()
+	for _, _tmp24 := range obj.Update {
+		_tmp25 := w.List()
+		w.WriteBytes(_tmp24.EntityKey[:])
+		w.WriteUint64(_tmp24.TTL)
+		w.WriteBytes(_tmp24.Payload)
+		_tmp26 := w.List()
+		for _, _tmp27 := range _tmp24.StringAnnotations {
+			_tmp28 := w.List()
+			w.WriteString(_tmp27.Key)
+			w.WriteString(_tmp27.Value)
+			w.ListEnd(_tmp28)
+		}
+		w.ListEnd(_tmp26)
+		_tmp29 := w.List()
+		for _, _tmp30 := range _tmp24.NumericAnnotations {
+			_tmp31 := w.List()
+			w.WriteString(_tmp30.Key)
+			w.WriteUint64(_tmp30.Value)
+			w.ListEnd(_tmp31)
+		}
+		w.ListEnd(_tmp29)
+		_tmp32 := len(_tmp24.TagAnnotations) > 0
+		_tmp33 := _tmp24.ContentMetaData != (entity.ContentMetaData{})
+		if _tmp32 || _tmp33 {
+			_tmp34 := w.List()
+			for _, _tmp35 := range _tmp24.TagAnnotations {
+				_tmp36 := w.List()
+				w.WriteString(_tmp35.Key)
+				_tmp37 := w.List()
+				for _, _tmp38 := range _tmp35.Values {
+					w.WriteString(_tmp38)
+				}
+				w.ListEnd(_tmp37)
+				w.ListEnd(_tmp36)
+			}
+			w.ListEnd(_tmp34)
 		}
-		w.ListEnd(_tmp13)
-		_tmp16 := w.List()
-		for _, _tmp17 := range _tmp11.NumericAnnotations {
-			_tmp18 := w.List()
-			w.WriteString(_tmp17.Key)
-			w.WriteUint64(_tmp17.Value)
-			w.ListEnd(_tmp18)
+		if _tmp33 {
+			_tmp39 := w.List()
+			_tmp40 := _tmp24.ContentMetaData.ContentType != ""
+			_tmp41 := _tmp24.ContentMetaData.ContentEncoding != ""
+			_tmp42 := _tmp24.ContentMetaData.PayloadHash != (common.Hash{})
+			if _tmp40 || _tmp41 || _tmp42 {
+				w.WriteString(_tmp24.ContentMetaData.ContentType)
+			}
+			if _tmp41 || _tmp42 {
+				w.WriteString(_tmp24.ContentMetaData.ContentEncoding)
+			}
+			if _tmp42 {
+				w.WriteBytes(_tmp24.ContentMetaData.PayloadHash[:])
+			}
+			w.ListEnd(_tmp39)
 		}
-		w.ListEnd(_tmp16)
-		w.ListEnd(_tmp12)
+		w.ListEnd(_tmp25)
 	}
-	w.ListEnd(_tmp10)
-	_tmp19 := w.List()
-	for _, _tmp20 := range obj.Delete {
-		w.WriteBytes(_tmp20[:])
+	w.ListEnd(_tmp23)
+	_tmp43 := w.List()
+	for _, _tmp44 := range obj.Delete {
+		w.WriteBytes(_tmp44[:])
 	}
-	w.ListEnd(_tmp19)
-	_tmp21 := w.List()
-	for _, _tmp22 := range obj.Extend {
-		_tmp23 := w.List()
-		w.WriteBytes(_tmp22.EntityKey[:])
-		w.WriteUint64(_tmp22.NumberOfBlocks)
-		w.ListEnd(_tmp23)
+	w.ListEnd(_tmp43)
+	_tmp45 := w.List()
+	for _, _tmp46 := range obj.Extend {
+		_tmp47 := w.List()
+		w.WriteBytes(_tmp46.EntityKey[:])
+		w.WriteUint64(_tmp46.NumberOfBlocks)
+		w.ListEnd(_tmp47)
+	}
+	w.ListEnd(_tmp45)
+	_tmp48 := len(obj.BeginUpload) > 0
+	_tmp49 := len(obj.AppendChunk) > 0
+	_tmp50 := len(obj.FinalizeUpload) > 0
+	if _tmp48 || _tmp49 || _tmp50 {
+		_tmp51 := w.List()
+		for _, _tmp52 := range obj.BeginUpload {
+			_tmp53 := w.List()
+			w.WriteUint64(_tmp52.TTL)
+			w.WriteUint64(_tmp52.Size)
+			w.WriteBytes(_tmp52.PayloadHash[:])
+			_tmp54 := w.List()
+			for _, _tmp55 := range _tmp52.StringAnnotations {
+				_tmp56 := w.List()
+				w.WriteString(_tmp55.Key)
+				w.WriteString(_tmp55.Value)
+				w.ListEnd(_tmp56)
+			}
+			w.ListEnd(_tmp54)
+			_tmp57 := w.List()
+			for _, _tmp58 := range _tmp52.NumericAnnotations {
+				_tmp59 := w.List()
+				w.WriteString(_tmp58.Key)
+				w.WriteUint64(_tmp58.Value)
+				w.ListEnd(_tmp59)
+			}
+			w.ListEnd(_tmp57)
+			_tmp60 := w.List()
+			for _, _tmp61 := range _tmp52.TagAnnotations {
+				_tmp62 := w.List()
+				w.WriteString(_tmp61.Key)
+				_tmp63 := w.List()
+				for _, _tmp64 := range _tmp61.Values {
+					w.WriteString(_tmp64)
+				}
+				w.ListEnd(_tmp63)
+				w.ListEnd(_tmp62)
+			}
+			w.ListEnd(_tmp60)
+			w.WriteString(_tmp52.Namespace)
+			w.WriteString(_tmp52.Name)
+			_tmp65 := _tmp52.ContentType != ""
+			_tmp66 := _tmp52.ContentEncoding != ""
+			if _tmp65 || _tmp66 {
+				w.WriteString(_tmp52.ContentType)
+			}
+			if _tmp66 {
+				w.WriteString(_tmp52.ContentEncoding)
+			}
+			w.ListEnd(_tmp53)
+		}
+		w.ListEnd(_tmp51)
+	}
+	if _tmp49 || _tmp50 {
+		_tmp67 := w.List()
+		for _, _tmp68 := range obj.AppendChunk {
+			_tmp69 := w.List()
+			w.WriteBytes(_tmp68.UploadKey[:])
+			w.WriteUint64(_tmp68.Offset)
+			w.WriteBytes(_tmp68.Data)
+			w.ListEnd(_tmp69)
+		}
+		w.ListEnd(_tmp67)
+	}
+	if _tmp50 {
+		_tmp70 := w.List()
+		for _, _tmp71 := range obj.FinalizeUpload {
+			w.WriteBytes(_tmp71[:])
+		}
+		w.ListEnd(_tmp70)
 	}
-	w.ListEnd(_tmp21)
 	w.ListEnd(_tmp0)
 	return w.Flush()
 }
@@ -0,0 +1,115 @@
+package storagetx_test
+
+import (
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunEnforcesOwnerQuota(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+	quota := ownerusage.Quota{MaxEntities: 2, MaxPayloadBytes: 10}
+
+	create := func(payload string) storagetx.Create {
+		return storagetx.Create{
+			TTL:               100,
+			Payload:           []byte(payload),
+			StringAnnotations: []entity.StringAnnotation{{Key: "k", Value: "v"}},
+		}
+	}
+
+	tx := &storagetx.StorageTransaction{Create: []storagetx.Create{create("abcd"), create("efgh")}}
+	_, err = tx.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, quota)
+	require.NoError(t, err)
+	require.Equal(t, ownerusage.Usage{Entities: 2, PayloadBytes: 8, Annotations: 2}, ownerusage.Get(db, owner))
+
+	tx = &storagetx.StorageTransaction{Create: []storagetx.Create{create("i")}}
+	_, err = tx.Run(kaolin, 2, common.HexToHash("0x2"), owner, db, quota)
+	require.ErrorContains(t, err, "entity quota exceeded")
+
+	// other owners have their own quota
+	_, err = tx.Run(kaolin, 2, common.HexToHash("0x3"), common.HexToAddress("0x5678"), db, quota)
+	require.NoError(t, err)
+}
+
+func TestRunAllowsShrinkingOverQuota(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+
+	tx := &storagetx.StorageTransaction{Create: []storagetx.Create{{TTL: 100, Payload: []byte("0123456789")}}}
+	logs, err := tx.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+	key := logs[0].Topics[1]
+
+	// the quota is lowered below the current usage
+	quota := ownerusage.Quota{MaxPayloadBytes: 5}
+
+	tx = &storagetx.StorageTransaction{Update: []storagetx.Update{{EntityKey: key, TTL: 100, Payload: []byte("0123456")}}}
+	_, err = tx.Run(kaolin, 2, common.HexToHash("0x2"), owner, db, quota)
+	require.NoError(t, err)
+
+	tx = &storagetx.StorageTransaction{Update: []storagetx.Update{{EntityKey: key, TTL: 100, Payload: []byte("01234567")}}}
+	_, err = tx.Run(kaolin, 3, common.HexToHash("0x3"), owner, db, quota)
+	require.ErrorContains(t, err, "payload quota exceeded")
+
+	tx = &storagetx.StorageTransaction{Delete: []common.Hash{key}}
+	_, err = tx.Run(kaolin, 4, common.HexToHash("0x4"), owner, db, quota)
+	require.NoError(t, err)
+	require.Equal(t, ownerusage.Usage{}, ownerusage.Get(db, owner))
+}
+
+func TestRunTracksTotalUsage(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	tx := &storagetx.StorageTransaction{Create: []storagetx.Create{{TTL: 100, Payload: []byte("abc")}}}
+	logs, err := tx.Run(kaolin, 1, common.HexToHash("0x1"), common.HexToAddress("0x1234"), db, ownerusage.Quota{})
+	require.NoError(t, err)
+	key := logs[0].Topics[1]
+
+	tx = &storagetx.StorageTransaction{Create: []storagetx.Create{{TTL: 100, Payload: []byte("defgh")}}}
+	_, err = tx.Run(kaolin, 1, common.HexToHash("0x2"), common.HexToAddress("0x5678"), db, ownerusage.Quota{})
+	require.NoError(t, err)
+	require.Equal(t, ownerusage.Usage{Entities: 2, PayloadBytes: 8}, ownerusage.Total(db))
+
+	tx = &storagetx.StorageTransaction{Delete: []common.Hash{key}}
+	_, err = tx.Run(kaolin, 2, common.HexToHash("0x3"), common.HexToAddress("0x1234"), db, ownerusage.Quota{})
+	require.NoError(t, err)
+	require.Equal(t, ownerusage.Usage{Entities: 1, PayloadBytes: 5}, ownerusage.Total(db))
+}
+
+func TestRunChargesPendingUploads(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+	quota := ownerusage.Quota{MaxPayloadBytes: 100}
+
+	// a failed transaction is reverted by the EVM
+	snapshot := db.Snapshot()
+	tx := &storagetx.StorageTransaction{BeginUpload: []storagetx.BeginUpload{{TTL: 100, Size: 101}}}
+	_, err = tx.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, quota)
+	require.ErrorContains(t, err, "payload quota exceeded")
+	db.RevertToSnapshot(snapshot)
+
+	tx = &storagetx.StorageTransaction{BeginUpload: []storagetx.BeginUpload{{TTL: 100, Size: 60}}}
+	_, err = tx.Run(kaolin, 1, common.HexToHash("0x2"), owner, db, quota)
+	require.NoError(t, err)
+	require.Equal(t, ownerusage.Usage{Entities: 1, PayloadBytes: 60}, ownerusage.Get(db, owner))
+
+	// the declared size counts until the upload is finalized or abandoned
+	tx = &storagetx.StorageTransaction{Create: []storagetx.Create{{TTL: 100, Payload: make([]byte, 50)}}}
+	_, err = tx.Run(kaolin, 2, common.HexToHash("0x3"), owner, db, quota)
+	require.ErrorContains(t, err, "payload quota exceeded")
+}
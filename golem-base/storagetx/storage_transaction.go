@@ -1,22 +1,33 @@
 package storagetx
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"math/big"
+	"slices"
 
+	"github.com/holiman/uint256"
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
 	"github.com/jeffcogswell/golembase-op-geth/crypto"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtracing"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/pendingupload"
 	"github.com/jeffcogswell/golembase-op-geth/log"
+	"github.com/jeffcogswell/golembase-op-geth/params"
 	"github.com/jeffcogswell/golembase-op-geth/rlp"
-	"github.com/holiman/uint256"
 )
 
 //go:generate go run ../../rlp/rlpgen -type StorageTransaction -out gen_storage_transaction_rlp.go
 
+// uploadKeySalt separates the keys of unnamed uploads from the keys of unnamed creates.
+var uploadKeySalt = []byte("golemBase.upload")
+
 // GolemBaseStorageEntityCreated is the event signature for entity creation logs.
 var GolemBaseStorageEntityCreated = crypto.Keccak256Hash([]byte("GolemBaseStorageEntityCreated(uint256,uint256)"))
 
@@ -29,24 +40,45 @@ var GolemBaseStorageEntityUpdated = crypto.Keccak256Hash([]byte("GolemBaseStorag
 // GolemBaseStorageEntityTTLExtended is the event signature for extending TTL of an entity.
 var GolemBaseStorageEntityTTLExtended = crypto.Keccak256Hash([]byte("GolemBaseStorageEntityTTLExptended(uint256,uint256)"))
 
+// GolemBaseStorageUploadBegun is the event signature for beginning a chunked upload.
+// The data is the block in which the upload is abandoned unless a chunk is appended before.
+var GolemBaseStorageUploadBegun = crypto.Keccak256Hash([]byte("GolemBaseStorageUploadBegun(uint256,uint256)"))
+
+// GolemBaseStorageUploadFinalized is the event signature for finalizing a chunked upload.
+// The data is the RLP encoded Create operation equivalent to the upload without its payload,
+// which can be large and is read from the appended chunks instead (see wal.ForEachOperation).
+// It follows the GolemBaseStorageEntityCreated log of the entity.
+var GolemBaseStorageUploadFinalized = crypto.Keccak256Hash([]byte("GolemBaseStorageUploadFinalized(uint256,bytes)"))
+
+// GolemBaseStorageUploadAbandoned is the event signature for removing an abandoned upload by housekeeping.
+var GolemBaseStorageUploadAbandoned = crypto.Keccak256Hash([]byte("GolemBaseStorageUploadAbandoned(uint256)"))
+
 // StorageTransaction represents a transaction that can be applied to the storage layer.
 // It contains a list of Create operations, a list of Update operations and a list of Delete operations.
 //
 // Semantics of the transaction operations are as follows:
 //   - Create: adds new entities to the storage layer. Each entity has a TTL (number of blocks), a payload and a list of annotations. The Key of the entity is derived from the payload content, the transaction hash where the entity was created and the index of the create operation in the transaction.
+//     If the create operation has a Name, the Key is instead derived from the sender, the Namespace and the Name, so it can be known before the transaction is mined.
+//     If an entity with the derived Key already exists, the operation fails, failing the whole transaction.
 //   - Update: updates existing entities. Each entity has a key, a TTL (number of blocks), a payload and a list of annotations. If the entity does not exist, the operation fails, failing the whole transaction.
 //   - Delete: removes entities from the storage layer. If the entity does not exist, the operation fails, failing back the whole transaction.
+//   - BeginUpload, AppendChunk and FinalizeUpload: create an entity whose payload is too large for a single transaction, see BeginUpload.
 //
 // The transaction is atomic, meaning that all operations are applied or none are.
 //
 // Annotations are key-value pairs where the key is a string and the value is either a string or a number.
 // The key-value pairs are used to build indexes and to query the storage layer.
 // Same key can have both string and numeric annotation, but not multiple values of the same type.
+// Tag annotations are list-valued string annotations; every value in the list is indexed as a string annotation with the same key.
 type StorageTransaction struct {
 	Create []Create      `json:"create"`
 	Update []Update      `json:"update"`
 	Delete []common.Hash `json:"delete"`
 	Extend []ExtendTTL   `json:"extend"`
+
+	BeginUpload    []BeginUpload `json:"beginUpload,omitempty" rlp:"optional"`
+	AppendChunk    []AppendChunk `json:"appendChunk,omitempty" rlp:"optional"`
+	FinalizeUpload []common.Hash `json:"finalizeUpload,omitempty" rlp:"optional"`
 }
 
 type Create struct {
@@ -54,6 +86,11 @@ type Create struct {
 	Payload            []byte                     `json:"payload"`
 	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
 	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	Namespace          string                     `json:"namespace,omitempty" rlp:"optional"`
+	Name               string                     `json:"name,omitempty" rlp:"optional"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations,omitempty" rlp:"optional"`
+
+	entity.ContentMetaData `rlp:"optional"`
 }
 
 type Update struct {
@@ -62,6 +99,41 @@ type Update struct {
 	Payload            []byte                     `json:"payload"`
 	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
 	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations,omitempty" rlp:"optional"`
+
+	entity.ContentMetaData `rlp:"optional"`
+}
+
+// BeginUpload begins a chunked upload of an entity, whose payload is then sent in
+// AppendChunk operations of one or more following transactions.
+// The upload has the key of the future entity: if Name is set, the key is derived from the sender,
+// the Namespace and the Name as for creates, otherwise from the transaction hash, the PayloadHash
+// and the index of the operation. The key is also in the GolemBaseStorageUploadBegun log.
+//
+// FinalizeUpload creates the entity once Size bytes were received and their keccak256 hash is PayloadHash,
+// which also becomes the payload hash of the entity.
+// The TTL counts from the block of the finalization.
+// An upload that does not receive a chunk for pendingupload.Timeout blocks is removed by housekeeping.
+type BeginUpload struct {
+	TTL                uint64                     `json:"ttl"`
+	Size               uint64                     `json:"size"`
+	PayloadHash        common.Hash                `json:"payloadHash"`
+	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
+	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations"`
+	Namespace          string                     `json:"namespace"`
+	Name               string                     `json:"name"`
+	ContentType        string                     `json:"contentType,omitempty" rlp:"optional"`
+	ContentEncoding    string                     `json:"contentEncoding,omitempty" rlp:"optional"`
+}
+
+// AppendChunk appends Data to the payload of the upload with UploadKey. Offset has to be the number
+// of bytes received so far, which can be read with golembase_getPendingUpload to resume an upload.
+// Only the sender that began the upload can append to it.
+type AppendChunk struct {
+	UploadKey common.Hash `json:"uploadKey"`
+	Offset    uint64      `json:"offset"`
+	Data      []byte      `json:"data"`
 }
 
 type ExtendTTL struct {
@@ -69,7 +141,54 @@ type ExtendTTL struct {
 	NumberOfBlocks uint64      `json:"numberOfBlocks"`
 }
 
-func (tx *StorageTransaction) Run(blockNumber uint64, txHash common.Hash, sender common.Address, access storageutil.StateAccess) (_ []*types.Log, err error) {
+// ErrUpgradeNotActive is returned for storage transactions that use operations or
+// fields of a Golem Base upgrade that is not active yet.
+var ErrUpgradeNotActive = errors.New("golem base upgrade not active")
+
+// Validate checks that the transaction only uses the operations and fields that are
+// available under the rules. Before Kaolin, only creates, updates, deletes and extends
+// of unnamed entities with string and numeric annotations are allowed.
+func (tx *StorageTransaction) Validate(rules params.GolemBaseRules) error {
+	if rules.IsKaolin {
+		return nil
+	}
+
+	notKaolin := func(what string) error {
+		return fmt.Errorf("%w: %s require the Kaolin upgrade", ErrUpgradeNotActive, what)
+	}
+
+	if len(tx.BeginUpload) != 0 || len(tx.AppendChunk) != 0 || len(tx.FinalizeUpload) != 0 {
+		return notKaolin("chunked uploads")
+	}
+
+	for i, create := range tx.Create {
+		switch {
+		case create.Namespace != "" || create.Name != "":
+			return fmt.Errorf("create operation %d: %w", i, notKaolin("named entities"))
+		case len(create.TagAnnotations) != 0:
+			return fmt.Errorf("create operation %d: %w", i, notKaolin("tag annotations"))
+		case create.ContentMetaData != entity.ContentMetaData{}:
+			return fmt.Errorf("create operation %d: %w", i, notKaolin("content metadata"))
+		}
+	}
+
+	for i, update := range tx.Update {
+		switch {
+		case len(update.TagAnnotations) != 0:
+			return fmt.Errorf("update operation %d: %w", i, notKaolin("tag annotations"))
+		case update.ContentMetaData != entity.ContentMetaData{}:
+			return fmt.Errorf("update operation %d: %w", i, notKaolin("content metadata"))
+		}
+	}
+
+	return nil
+}
+
+// Run applies the operations of the transaction to the storage.
+// The transaction fails if it is not valid under the rules of the block, see Validate.
+// After all operations are applied, the usage of every owner whose entities were created or updated,
+// or who began an upload, is checked against the quota; the transaction fails if an owner exceeds it.
+func (tx *StorageTransaction) Run(rules params.GolemBaseRules, blockNumber uint64, txHash common.Hash, sender common.Address, access storageutil.StateAccess, quota ownerusage.Quota) (_ []*types.Log, err error) {
 
 	defer func() {
 		if err != nil {
@@ -77,11 +196,38 @@ func (tx *StorageTransaction) Run(blockNumber uint64, txHash common.Hash, sender
 		}
 	}()
 
+	err = tx.Validate(rules)
+	if err != nil {
+		return nil, err
+	}
+
 	logs := []*types.Log{}
 
+	hooks := golemtracing.HooksOf(access)
+
+	// usage of the owners that can grow in this transaction, before any operation is applied,
+	// quotas are only checked since Kaolin
+	previousUsage := map[common.Address]ownerusage.Usage{}
+	if rules.IsKaolin {
+		previousUsage[sender] = ownerusage.Get(access, sender)
+		for _, update := range tx.Update {
+			md, err := entity.GetEntityMetaData(access, update.EntityKey)
+			if err != nil {
+				// the update operation reports the missing entity
+				continue
+			}
+			previousUsage[md.Owner] = ownerusage.Get(access, md.Owner)
+		}
+	}
+
 	storeEntity := func(key common.Hash, ap *entity.EntityMetaData, payload []byte, emitLogs bool) error {
 
-		err := entity.Store(access, key, sender, *ap, payload)
+		err := ap.ContentMetaData.Validate(payload)
+		if err != nil {
+			return fmt.Errorf("invalid content metadata of entity %s: %w", key.Hex(), err)
+		}
+
+		err = entity.Store(rules, access, key, sender, *ap, payload)
 		if err != nil {
 			return fmt.Errorf("failed to store entity: %w", err)
 		}
@@ -107,30 +253,51 @@ func (tx *StorageTransaction) Run(blockNumber uint64, txHash common.Hash, sender
 	}
 
 	for i, create := range tx.Create {
-		// Convert i to a big integer and pad to 32 bytes
-		bigI := big.NewInt(int64(i))
-		paddedI := common.LeftPadBytes(bigI.Bytes(), 32)
+		err := hooks.TraceOperation(golemtracing.Operation{Type: golemtracing.Create, Index: i}, func(op *golemtracing.Operation) error {
+			// Convert i to a big integer and pad to 32 bytes
+			bigI := big.NewInt(int64(i))
+			paddedI := common.LeftPadBytes(bigI.Bytes(), 32)
+
+			key := crypto.Keccak256Hash(txHash.Bytes(), create.Payload, paddedI)
+
+			switch {
+			case create.Name != "":
+				key = entity.NamedEntityKey(sender, create.Namespace, create.Name)
+			case create.Namespace != "":
+				return fmt.Errorf("create operation %d has a namespace but no name", i)
+			}
 
-		key := crypto.Keccak256Hash(txHash.Bytes(), create.Payload, paddedI)
+			op.EntityKey = key
 
-		ap := &entity.EntityMetaData{
-			Owner:              sender,
-			ExpiresAtBlock:     blockNumber + create.TTL,
-			StringAnnotations:  create.StringAnnotations,
-			NumericAnnotations: create.NumericAnnotations,
-		}
+			if allentities.Contains(access, key) {
+				return fmt.Errorf("entity %s already exists", key.Hex())
+			}
 
-		err := storeEntity(key, ap, create.Payload, true)
+			ap := &entity.EntityMetaData{
+				Owner:              sender,
+				ExpiresAtBlock:     blockNumber + create.TTL,
+				StringAnnotations:  create.StringAnnotations,
+				NumericAnnotations: create.NumericAnnotations,
+				TagAnnotations:     create.TagAnnotations,
+				ContentMetaData:    create.ContentMetaData,
+			}
+
+			err := storeEntity(key, ap, create.Payload, true)
 
+			if err != nil {
+				return err
+			}
+
+			return nil
+		})
 		if err != nil {
 			return nil, err
 		}
-
 	}
 
 	deleteEntity := func(toDelete common.Hash, emitLogs bool) error {
 
-		err := entity.Delete(access, toDelete)
+		err := entity.Delete(rules, access, toDelete)
 		if err != nil {
 			return fmt.Errorf("failed to delete entity: %w", err)
 		}
@@ -152,85 +319,294 @@ func (tx *StorageTransaction) Run(blockNumber uint64, txHash common.Hash, sender
 
 	}
 
-	for _, toDelete := range tx.Delete {
-		err := deleteEntity(toDelete, true)
+	for i, toDelete := range tx.Delete {
+		err := hooks.TraceOperation(golemtracing.Operation{Type: golemtracing.Delete, Index: i, EntityKey: toDelete}, func(op *golemtracing.Operation) error {
+			err := deleteEntity(toDelete, true)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		})
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	for _, update := range tx.Update {
+	for i, update := range tx.Update {
+		err := hooks.TraceOperation(golemtracing.Operation{Type: golemtracing.Update, Index: i, EntityKey: update.EntityKey}, func(op *golemtracing.Operation) error {
+			oldMetaData, err := entity.GetEntityMetaData(access, update.EntityKey)
+			if err != nil {
+				return fmt.Errorf("failed to get entity meta data for update %s: %w", update.EntityKey.Hex(), err)
+			}
 
-		oldMetaData, err := entity.GetEntityMetaData(access, update.EntityKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get entity meta data for update %s: %w", update.EntityKey.Hex(), err)
-		}
+			if entity.IsExpired(access, update.EntityKey) {
+				return fmt.Errorf("entity %s has expired", update.EntityKey.Hex())
+			}
+
+			err = deleteEntity(update.EntityKey, false)
+			if err != nil {
+				return err
+			}
+
+			ap := &entity.EntityMetaData{
+				ExpiresAtBlock:     blockNumber + update.TTL,
+				StringAnnotations:  update.StringAnnotations,
+				NumericAnnotations: update.NumericAnnotations,
+				TagAnnotations:     update.TagAnnotations,
+				Owner:              oldMetaData.Owner,
+				ContentMetaData:    update.ContentMetaData,
+			}
+
+			err = storeEntity(update.EntityKey, ap, update.Payload, false)
+
+			if err != nil {
+				return err
+			}
+
+			expiresAtBlockNumberBig := uint256.NewInt(ap.ExpiresAtBlock)
+			data := make([]byte, 32)
+			expiresAtBlockNumberBig.PutUint256(data[:32])
+
+			logs = append(logs, &types.Log{
+				Address:     address.GolemBaseStorageProcessorAddress,
+				Topics:      []common.Hash{GolemBaseStorageEntityUpdated, update.EntityKey},
+				Data:        data,
+				BlockNumber: blockNumber,
+			})
 
-		err = deleteEntity(update.EntityKey, false)
+			return nil
+		})
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		ap := &entity.EntityMetaData{
-			ExpiresAtBlock:     blockNumber + update.TTL,
-			StringAnnotations:  update.StringAnnotations,
-			NumericAnnotations: update.NumericAnnotations,
-			Owner:              oldMetaData.Owner,
-		}
+	for i, extend := range tx.Extend {
+		err := hooks.TraceOperation(golemtracing.Operation{Type: golemtracing.Extend, Index: i, EntityKey: extend.EntityKey}, func(op *golemtracing.Operation) error {
+			if entity.IsExpired(access, extend.EntityKey) {
+				return fmt.Errorf("entity %s has expired", extend.EntityKey.Hex())
+			}
+
+			newExpiresAtBlock, err := entity.ExtendTTL(access, extend.EntityKey, extend.NumberOfBlocks)
+			if err != nil {
+				return err
+			}
+
+			oldExpiresAtBlock := newExpiresAtBlock - extend.NumberOfBlocks
+
+			oldExpiresAtBlockBig := uint256.NewInt(oldExpiresAtBlock)
+			newExpiresAtBlockBig := uint256.NewInt(newExpiresAtBlock)
+
+			data := make([]byte, 64)
 
-		err = storeEntity(update.EntityKey, ap, update.Payload, false)
+			oldExpiresAtBlockBig.PutUint256(data[:32])
+			newExpiresAtBlockBig.PutUint256(data[32:])
 
+			logs = append(logs, &types.Log{
+				Address:     address.GolemBaseStorageProcessorAddress,
+				Topics:      []common.Hash{GolemBaseStorageEntityTTLExtended, extend.EntityKey},
+				Data:        data,
+				BlockNumber: blockNumber,
+			})
+
+			return nil
+		})
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	for i, begin := range tx.BeginUpload {
+		err := hooks.TraceOperation(golemtracing.Operation{Type: golemtracing.BeginUpload, Index: i}, func(op *golemtracing.Operation) error {
+			paddedI := common.LeftPadBytes(big.NewInt(int64(i)).Bytes(), 32)
 
-		expiresAtBlockNumberBig := uint256.NewInt(ap.ExpiresAtBlock)
-		data := make([]byte, 32)
-		expiresAtBlockNumberBig.PutUint256(data[:32])
+			key := crypto.Keccak256Hash(txHash.Bytes(), uploadKeySalt, begin.PayloadHash.Bytes(), paddedI)
+
+			switch {
+			case begin.Name != "":
+				key = entity.NamedEntityKey(sender, begin.Namespace, begin.Name)
+			case begin.Namespace != "":
+				return fmt.Errorf("begin upload operation %d has a namespace but no name", i)
+			}
 
-		logs = append(logs, &types.Log{
-			Address:     address.GolemBaseStorageProcessorAddress,
-			Topics:      []common.Hash{GolemBaseStorageEntityUpdated, update.EntityKey},
-			Data:        data,
-			BlockNumber: blockNumber,
+			op.EntityKey = key
+
+			if begin.Size == 0 {
+				return fmt.Errorf("upload %s has no payload", key.Hex())
+			}
+
+			if allentities.Contains(access, key) {
+				return fmt.Errorf("entity %s already exists", key.Hex())
+			}
+
+			content := entity.ContentMetaData{ContentType: begin.ContentType, ContentEncoding: begin.ContentEncoding}
+			err := content.Validate(nil)
+			if err != nil {
+				return fmt.Errorf("invalid content metadata of upload %s: %w", key.Hex(), err)
+			}
+
+			err = pendingupload.Begin(access, key, blockNumber, pendingupload.PendingUpload{
+				Owner:              sender,
+				TTL:                begin.TTL,
+				StringAnnotations:  begin.StringAnnotations,
+				NumericAnnotations: begin.NumericAnnotations,
+				TagAnnotations:     begin.TagAnnotations,
+				Namespace:          begin.Namespace,
+				Name:               begin.Name,
+				Size:               begin.Size,
+				PayloadHash:        begin.PayloadHash,
+				ContentType:        begin.ContentType,
+				ContentEncoding:    begin.ContentEncoding,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to begin upload: %w", err)
+			}
+
+			data := make([]byte, 32)
+			uint256.NewInt(blockNumber + pendingupload.Timeout).PutUint256(data)
+
+			logs = append(logs, &types.Log{
+				Address:     address.GolemBaseStorageProcessorAddress,
+				Topics:      []common.Hash{GolemBaseStorageUploadBegun, key},
+				Data:        data,
+				BlockNumber: blockNumber,
+			})
+
+			return nil
 		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	// getOwnUpload returns the pending upload with the key if the sender began it.
+	// An abandoned upload that housekeeping has not removed completely yet can not be continued.
+	getOwnUpload := func(key common.Hash) (*pendingupload.PendingUpload, error) {
+		upload, err := pendingupload.Get(access, key)
+		if err != nil {
+			return nil, err
+		}
+		if upload.Owner != sender {
+			return nil, fmt.Errorf("upload %s was begun by %s", key.Hex(), upload.Owner.Hex())
+		}
+		if upload.AbandonedAtBlock <= blockNumber {
+			return nil, fmt.Errorf("upload %s was abandoned in block %d", key.Hex(), upload.AbandonedAtBlock)
+		}
+		return upload, nil
 	}
 
-	for _, extend := range tx.Extend {
-		newExpiresAtBlock, err := entity.ExtendTTL(access, extend.EntityKey, extend.NumberOfBlocks)
+	for i, chunk := range tx.AppendChunk {
+		err := hooks.TraceOperation(golemtracing.Operation{Type: golemtracing.AppendChunk, Index: i, EntityKey: chunk.UploadKey}, func(op *golemtracing.Operation) error {
+			_, err := getOwnUpload(chunk.UploadKey)
+			if err != nil {
+				return err
+			}
+
+			_, err = pendingupload.AppendChunk(access, chunk.UploadKey, blockNumber, chunk.Offset, chunk.Data)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		oldExpiresAtBlock := newExpiresAtBlock - extend.NumberOfBlocks
+	for i, key := range tx.FinalizeUpload {
+		err := hooks.TraceOperation(golemtracing.Operation{Type: golemtracing.FinalizeUpload, Index: i, EntityKey: key}, func(op *golemtracing.Operation) error {
+			upload, err := getOwnUpload(key)
+			if err != nil {
+				return err
+			}
 
-		oldExpiresAtBlockBig := uint256.NewInt(oldExpiresAtBlock)
-		newExpiresAtBlockBig := uint256.NewInt(newExpiresAtBlock)
+			if upload.Received != upload.Size {
+				return fmt.Errorf("upload %s received %d of %d bytes", key.Hex(), upload.Received, upload.Size)
+			}
 
-		data := make([]byte, 64)
+			payload := pendingupload.Payload(access, key, upload)
+			if crypto.Keccak256Hash(payload) != upload.PayloadHash {
+				return fmt.Errorf("payload of upload %s does not match its hash %s", key.Hex(), upload.PayloadHash.Hex())
+			}
 
-		oldExpiresAtBlockBig.PutUint256(data[:32])
-		newExpiresAtBlockBig.PutUint256(data[32:])
+			err = pendingupload.Delete(access, key)
+			if err != nil {
+				return fmt.Errorf("failed to delete upload: %w", err)
+			}
 
-		logs = append(logs, &types.Log{
-			Address:     address.GolemBaseStorageProcessorAddress,
-			Topics:      []common.Hash{GolemBaseStorageEntityTTLExtended, extend.EntityKey},
-			Data:        data,
-			BlockNumber: blockNumber,
+			if allentities.Contains(access, key) {
+				return fmt.Errorf("entity %s already exists", key.Hex())
+			}
+
+			content := entity.ContentMetaData{
+				ContentType:     upload.ContentType,
+				ContentEncoding: upload.ContentEncoding,
+				PayloadHash:     upload.PayloadHash,
+			}
+
+			ap := &entity.EntityMetaData{
+				Owner:              sender,
+				ExpiresAtBlock:     blockNumber + upload.TTL,
+				StringAnnotations:  upload.StringAnnotations,
+				NumericAnnotations: upload.NumericAnnotations,
+				TagAnnotations:     upload.TagAnnotations,
+				ContentMetaData:    content,
+			}
+
+			err = storeEntity(key, ap, payload, true)
+			if err != nil {
+				return err
+			}
+
+			create, err := rlp.EncodeToBytes(&Create{
+				TTL:                upload.TTL,
+				StringAnnotations:  upload.StringAnnotations,
+				NumericAnnotations: upload.NumericAnnotations,
+				Namespace:          upload.Namespace,
+				Name:               upload.Name,
+				TagAnnotations:     upload.TagAnnotations,
+				ContentMetaData:    content,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to encode finalized upload: %w", err)
+			}
+
+			logs = append(logs, &types.Log{
+				Address:     address.GolemBaseStorageProcessorAddress,
+				Topics:      []common.Hash{GolemBaseStorageUploadFinalized, key},
+				Data:        create,
+				BlockNumber: blockNumber,
+			})
+
+			return nil
 		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, owner := range slices.SortedFunc(maps.Keys(previousUsage), common.Address.Cmp) {
+		err := quota.Check(previousUsage[owner], ownerusage.Get(access, owner))
+		if err != nil {
+			return nil, fmt.Errorf("owner %s: %w", owner.Hex(), err)
+		}
 	}
 
 	return logs, nil
 }
 
-func ExecuteTransaction(d []byte, blockNumber uint64, txHash common.Hash, sender common.Address, access storageutil.StateAccess) ([]*types.Log, error) {
+// ExecuteTransaction decodes and runs a storage transaction under the rules of the block.
+// If hooks is not nil, the execution is reported to the hooks.
+func ExecuteTransaction(d []byte, rules params.GolemBaseRules, blockNumber uint64, txHash common.Hash, sender common.Address, access storageutil.StateAccess, quota ownerusage.Quota, hooks *golemtracing.Hooks) (_ []*types.Log, err error) {
+	hooks.StorageTxStart(txHash, sender, d)
+	defer func() {
+		hooks.StorageTxEnd(err)
+	}()
+
 	tx := &StorageTransaction{}
-	err := rlp.DecodeBytes(d, tx)
+	err = rlp.DecodeBytes(d, tx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode storage transaction: %w", err)
 	}
-	logs, err := tx.Run(blockNumber, txHash, sender, access)
+	logs, err := tx.Run(rules, blockNumber, txHash, sender, golemtracing.WrapAccess(access, hooks), quota)
 	if err != nil {
 		log.Error("Failed to run storage transaction", "error", err)
 		return nil, fmt.Errorf("failed to run storage transaction: %w", err)
@@ -6,11 +6,14 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/params"
 	"github.com/jeffcogswell/golembase-op-geth/rlp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+var kaolin = params.GolemBaseRules{IsKaolin: true}
+
 func TestStorageTransactionMarshalling(t *testing.T) {
 	t.Run("FullyPopulatedTransaction", func(t *testing.T) {
 		// Create a sample transaction with all fields populated
@@ -88,3 +91,27 @@ func TestStorageTransactionMarshalling(t *testing.T) {
 		assert.Empty(t, decodedEmpty.Delete)
 	})
 }
+
+func TestValidateBeforeKaolin(t *testing.T) {
+	rules := params.GolemBaseRules{}
+
+	valid := &storagetx.StorageTransaction{
+		Create: []storagetx.Create{{TTL: 100, Payload: []byte("hello"), StringAnnotations: []entity.StringAnnotation{{Key: "k", Value: "v"}}}},
+		Update: []storagetx.Update{{EntityKey: common.HexToHash("0x1"), TTL: 100}},
+		Delete: []common.Hash{common.HexToHash("0x2")},
+		Extend: []storagetx.ExtendTTL{{EntityKey: common.HexToHash("0x3"), NumberOfBlocks: 10}},
+	}
+	require.NoError(t, valid.Validate(rules))
+
+	for name, tx := range map[string]*storagetx.StorageTransaction{
+		"named entity":    {Create: []storagetx.Create{{TTL: 100, Name: "name"}}},
+		"tag annotations": {Create: []storagetx.Create{{TTL: 100, TagAnnotations: []entity.TagAnnotation{{Key: "k", Values: []string{"v"}}}}}},
+		"content type":    {Update: []storagetx.Update{{EntityKey: common.HexToHash("0x1"), ContentMetaData: entity.ContentMetaData{ContentType: "text/plain"}}}},
+		"chunked upload":  {FinalizeUpload: []common.Hash{common.HexToHash("0x1")}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, tx.Validate(rules), storagetx.ErrUpgradeNotActive)
+			require.NoError(t, tx.Validate(kaolin))
+		})
+	}
+}
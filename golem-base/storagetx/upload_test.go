@@ -0,0 +1,131 @@
+package storagetx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/pendingupload"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedUpload(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+	payload := bytes.Repeat([]byte("0123456789"), 10)
+
+	tx := &storagetx.StorageTransaction{BeginUpload: []storagetx.BeginUpload{{
+		TTL:               100,
+		Size:              uint64(len(payload)),
+		PayloadHash:       crypto.Keccak256Hash(payload),
+		StringAnnotations: []entity.StringAnnotation{{Key: "k", Value: "v"}},
+	}}}
+	logs, err := tx.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, storagetx.GolemBaseStorageUploadBegun, logs[0].Topics[0])
+	key := logs[0].Topics[1]
+
+	// the pending upload is charged to the owner with its declared size
+	require.Equal(t, ownerusage.Usage{Entities: 1, PayloadBytes: 100, Annotations: 1}, ownerusage.Get(db, owner))
+
+	appendChunk := func(blockNumber uint64, sender common.Address, offset int, chunk []byte) error {
+		tx := &storagetx.StorageTransaction{AppendChunk: []storagetx.AppendChunk{{UploadKey: key, Offset: uint64(offset), Data: chunk}}}
+		_, err := tx.Run(kaolin, blockNumber, common.Hash{byte(blockNumber)}, sender, db, ownerusage.Quota{})
+		return err
+	}
+
+	require.NoError(t, appendChunk(2, owner, 0, payload[:40]))
+
+	// only the owner can append, and only at the offset of the bytes received so far
+	require.ErrorContains(t, appendChunk(3, common.HexToAddress("0x5678"), 40, payload[40:]), "was begun by")
+	require.ErrorContains(t, appendChunk(3, owner, 0, payload[:40]), "expected 40")
+	require.ErrorContains(t, appendChunk(3, owner, 40, append(payload[40:], 'x')), "exceeds the payload size")
+
+	upload, err := pendingupload.Get(db, key)
+	require.NoError(t, err)
+	require.Equal(t, uint64(40), upload.Received)
+	require.Equal(t, 2+pendingupload.Timeout, upload.AbandonedAtBlock)
+
+	// the upload can not be finalized before the whole payload was received
+	finalize := &storagetx.StorageTransaction{FinalizeUpload: []common.Hash{key}}
+	_, err = finalize.Run(kaolin, 3, common.HexToHash("0x3"), owner, db, ownerusage.Quota{})
+	require.ErrorContains(t, err, "received 40 of 100 bytes")
+
+	require.NoError(t, appendChunk(3, owner, 40, payload[40:]))
+
+	logs, err = finalize.Run(kaolin, 4, common.HexToHash("0x4"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, []common.Hash{storagetx.GolemBaseStorageEntityCreated, key}, logs[0].Topics)
+	require.Equal(t, []common.Hash{storagetx.GolemBaseStorageUploadFinalized, key}, logs[1].Topics)
+
+	create := storagetx.Create{}
+	require.NoError(t, rlp.DecodeBytes(logs[1].Data, &create))
+	require.Empty(t, create.Payload)
+	require.Equal(t, crypto.Keccak256Hash(payload), create.PayloadHash)
+
+	require.Equal(t, payload, entity.GetPayload(db, key))
+	md, err := entity.GetEntityMetaData(db, key)
+	require.NoError(t, err)
+	require.Equal(t, uint64(104), md.ExpiresAtBlock)
+	require.Equal(t, owner, md.Owner)
+	require.Equal(t, ownerusage.Usage{Entities: 1, PayloadBytes: 100, Annotations: 1}, ownerusage.Get(db, owner))
+
+	require.False(t, pendingupload.Contains(db, key))
+}
+
+func TestChunkedUploadChecksPayloadHash(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+
+	tx := &storagetx.StorageTransaction{
+		BeginUpload: []storagetx.BeginUpload{{
+			TTL:         100,
+			Size:        5,
+			PayloadHash: crypto.Keccak256Hash([]byte("hello")),
+			Namespace:   "files",
+			Name:        "greeting",
+		}},
+	}
+	_, err = tx.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+
+	// named uploads have the key of the named entity
+	key := entity.NamedEntityKey(owner, "files", "greeting")
+	require.True(t, pendingupload.Contains(db, key))
+
+	tx = &storagetx.StorageTransaction{
+		AppendChunk:    []storagetx.AppendChunk{{UploadKey: key, Data: []byte("world")}},
+		FinalizeUpload: []common.Hash{key},
+	}
+	_, err = tx.Run(kaolin, 2, common.HexToHash("0x2"), owner, db, ownerusage.Quota{})
+	require.ErrorContains(t, err, "does not match its hash")
+}
+
+func TestChunkedUploadRejectsAbandonedUpload(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1234")
+	tx := &storagetx.StorageTransaction{BeginUpload: []storagetx.BeginUpload{{TTL: 100, Size: 10}}}
+	logs, err := tx.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+	key := logs[0].Topics[1]
+
+	// housekeeping may still be removing the upload, it can not be continued
+	appendChunk := &storagetx.StorageTransaction{AppendChunk: []storagetx.AppendChunk{{UploadKey: key, Data: []byte("0123456789")}}}
+	_, err = appendChunk.Run(kaolin, 1+pendingupload.Timeout, common.HexToHash("0x2"), owner, db, ownerusage.Quota{})
+	require.ErrorContains(t, err, "was abandoned")
+}
@@ -14,6 +14,19 @@ type EntityMetaData struct {
 	StringAnnotations  []StringAnnotation  `json:"stringAnnotations"`
 	NumericAnnotations []NumericAnnotation `json:"numericAnnotations"`
 	Owner              common.Address      `json:"owner"`
+	TagAnnotations     []TagAnnotation     `json:"tagAnnotations,omitempty" rlp:"optional"`
+
+	ContentMetaData `rlp:"optional"`
+}
+
+// ContentMetaData describes the payload of an entity. All fields are optional and set by the client.
+//   - ContentType is a MIME type, e.g. `application/json; charset=utf-8`.
+//   - ContentEncoding is the compression of the payload, one of ContentEncodings.
+//   - PayloadHash is the keccak256 hash of the payload as stored (i.e. encoded), checked when the entity is stored.
+type ContentMetaData struct {
+	ContentType     string      `json:"contentType,omitempty" rlp:"optional"`
+	ContentEncoding string      `json:"contentEncoding,omitempty" rlp:"optional"`
+	PayloadHash     common.Hash `json:"payloadHash" rlp:"optional"`
 }
 
 type StringAnnotation struct {
@@ -25,3 +38,11 @@ type NumericAnnotation struct {
 	Key   string `json:"key"`
 	Value uint64 `json:"value"`
 }
+
+// TagAnnotation is a list-valued string annotation.
+// Every value is indexed like a string annotation with the same key,
+// so `key = "value"` matches entities that have the value in the list.
+type TagAnnotation struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
@@ -25,11 +25,13 @@ var AllEntitiesKey = crypto.Keccak256Hash([]byte("golemBase.allEntities"))
 
 // AddEntity adds a new entity hash to the global registry.
 func AddEntity(db StateAccess, hash common.Hash) error {
+	defer storageutil.TraceIndex(db, "allEntities", AllEntitiesKey)()
 	return keyset.AddValue(db, AllEntitiesKey, hash)
 }
 
 // RemoveEntity removes an entity hash from the global registry.
 func RemoveEntity(db StateAccess, hash common.Hash) error {
+	defer storageutil.TraceIndex(db, "allEntities", AllEntitiesKey)()
 	return keyset.RemoveValue(db, AllEntitiesKey, hash)
 }
 
@@ -41,3 +43,14 @@ func Iterate(db StateAccess) func(yield func(hash common.Hash) bool) {
 func Contains(db StateAccess, hash common.Hash) bool {
 	return keyset.ContainsValue(db, AllEntitiesKey, hash)
 }
+
+// Count returns the number of entities in the registry.
+func Count(db StateAccess) uint64 {
+	return keyset.Size(db, AllEntitiesKey).Uint64()
+}
+
+// At returns the entity at the position index of the registry, counting from zero.
+// Removing an entity moves the last entity of the registry to its position.
+func At(db StateAccess, index uint64) common.Hash {
+	return keyset.At(db, AllEntitiesKey, index)
+}
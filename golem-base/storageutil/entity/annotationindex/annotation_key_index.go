@@ -0,0 +1,14 @@
+package annotationindex
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+)
+
+var AnnotationKeyIndexSalt = []byte("golemBaseAnnotationKey")
+
+// AnnotationKeyIndexKey identifies the set of entities that have at least one annotation with the key,
+// regardless of the type and value of the annotation.
+func AnnotationKeyIndexKey(key string) common.Hash {
+	return crypto.Keccak256Hash(AnnotationKeyIndexSalt, []byte(key))
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"fmt"
+	"mime"
+	"slices"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+)
+
+// Content encodings of payloads. An empty content encoding is the same as ContentEncodingIdentity.
+const (
+	ContentEncodingIdentity = "identity"
+	ContentEncodingGzip     = "gzip"
+	ContentEncodingZstd     = "zstd"
+)
+
+// ContentEncodings are the supported content encodings.
+var ContentEncodings = []string{ContentEncodingIdentity, ContentEncodingGzip, ContentEncodingZstd}
+
+// Validate checks that the content type is a valid MIME type, that the content encoding is supported
+// and that the payload hash, if set, is the hash of the payload.
+func (c ContentMetaData) Validate(payload []byte) error {
+	if c.ContentType != "" {
+		_, _, err := mime.ParseMediaType(c.ContentType)
+		if err != nil {
+			return fmt.Errorf("invalid content type %q: %w", c.ContentType, err)
+		}
+	}
+
+	if c.ContentEncoding != "" && !slices.Contains(ContentEncodings, c.ContentEncoding) {
+		return fmt.Errorf("unsupported content encoding %q, expected one of %v", c.ContentEncoding, ContentEncodings)
+	}
+
+	if c.PayloadHash != (common.Hash{}) {
+		hash := crypto.Keccak256Hash(payload)
+		if hash != c.PayloadHash {
+			return fmt.Errorf("payload hash %s does not match the payload hash %s", hash.Hex(), c.PayloadHash.Hex())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package entity_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentMetaDataValidate(t *testing.T) {
+	payload := []byte(`{"hello":"world"}`)
+
+	tests := []struct {
+		name    string
+		content entity.ContentMetaData
+		err     string
+	}{
+		{
+			name:    "no metadata",
+			content: entity.ContentMetaData{},
+		},
+		{
+			name: "all metadata",
+			content: entity.ContentMetaData{
+				ContentType:     "application/json; charset=utf-8",
+				ContentEncoding: entity.ContentEncodingIdentity,
+				PayloadHash:     crypto.Keccak256Hash(payload),
+			},
+		},
+		{
+			name:    "invalid content type",
+			content: entity.ContentMetaData{ContentType: "application/json; charset"},
+			err:     "invalid content type",
+		},
+		{
+			name:    "unsupported content encoding",
+			content: entity.ContentMetaData{ContentEncoding: "br"},
+			err:     "unsupported content encoding",
+		},
+		{
+			name:    "payload hash mismatch",
+			content: entity.ContentMetaData{PayloadHash: common.HexToHash("0x1")},
+			err:     "does not match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.content.Validate(payload)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	payload := bytes.Repeat([]byte("golem base "), 100)
+
+	gzipped := new(bytes.Buffer)
+	gw := gzip.NewWriter(gzipped)
+	_, err := gw.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zstded := enc.EncodeAll(payload, nil)
+
+	for encoding, encoded := range map[string][]byte{
+		"":                             payload,
+		entity.ContentEncodingIdentity: payload,
+		entity.ContentEncodingGzip:     gzipped.Bytes(),
+		entity.ContentEncodingZstd:     zstded,
+	} {
+		decoded, err := entity.DecodePayload(encoding, encoded)
+		require.NoError(t, err, encoding)
+		require.Equal(t, payload, decoded, encoding)
+	}
+
+	_, err = entity.DecodePayload(entity.ContentEncodingGzip, payload)
+	require.Error(t, err)
+}
+
+func TestDecodePayloadLimitsSize(t *testing.T) {
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	bomb := enc.EncodeAll(make([]byte, entity.MaxDecodedPayloadSize+1), nil)
+
+	_, err = entity.DecodePayload(entity.ContentEncodingZstd, bomb)
+	require.ErrorContains(t, err, "exceeds")
+}
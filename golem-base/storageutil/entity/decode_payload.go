@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// MaxDecodedPayloadSize limits the size of a decoded payload, so that a small
+// compressed payload can not expand into an arbitrary amount of memory.
+const MaxDecodedPayloadSize = 64 * 1024 * 1024
+
+// DecodePayload decompresses a payload stored with the content encoding.
+func DecodePayload(contentEncoding string, payload []byte) ([]byte, error) {
+	var r io.Reader
+
+	switch contentEncoding {
+	case "", ContentEncodingIdentity:
+		return payload, nil
+	case ContentEncodingGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip payload: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	case ContentEncodingZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(payload), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zstd payload: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
+
+	decoded, err := io.ReadAll(io.LimitReader(r, MaxDecodedPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s payload: %w", contentEncoding, err)
+	}
+
+	if len(decoded) > MaxDecodedPayloadSize {
+		return nil, fmt.Errorf("decoded payload exceeds %d bytes", MaxDecodedPayloadSize)
+	}
+
+	return decoded, nil
+}
@@ -2,21 +2,30 @@ package entity
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/annotationindex"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entitiesofowner"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/jeffcogswell/golembase-op-geth/params"
 )
 
-func Delete(access StateAccess, toDelete common.Hash) error {
+// Delete removes the entity from the state and from all indexes.
+// The owner usage and the annotation key index are only maintained since Kaolin,
+// and only hold the entities stored before Kaolin once they are migrated.
+func Delete(rules params.GolemBaseRules, access StateAccess, toDelete common.Hash) error {
 	md, err := GetEntityMetaData(access, toDelete)
 	if err != nil {
 		return fmt.Errorf("failed to get entity meta data: %w", err)
 	}
 
+	kaolinIndexed := rules.IsKaolin && isKaolinIndexed(access, toDelete)
+
 	err = allentities.RemoveEntity(access, toDelete)
 	if err != nil {
 		return fmt.Errorf("failed to remove entity from all entities: %w", err)
@@ -24,11 +33,9 @@ func Delete(access StateAccess, toDelete common.Hash) error {
 
 	for _, stringAnnotation := range md.StringAnnotations {
 		setKey := annotationindex.StringAnnotationIndexKey(stringAnnotation.Key, stringAnnotation.Value)
-		err := keyset.RemoveValue(
-			access,
-			setKey,
-			toDelete,
-		)
+		endTrace := storageutil.TraceIndex(access, "stringAnnotation:"+stringAnnotation.Key+"="+stringAnnotation.Value, setKey)
+		err := keyset.RemoveValue(access, setKey, toDelete)
+		endTrace()
 		if err != nil {
 			return fmt.Errorf("failed to remove key %s from the string annotation list: %w", toDelete, err)
 		}
@@ -36,17 +43,39 @@ func Delete(access StateAccess, toDelete common.Hash) error {
 	}
 
 	for _, numericAnnotation := range md.NumericAnnotations {
-		setKeys := annotationindex.NumericAnnotationIndexKey(numericAnnotation.Key, numericAnnotation.Value)
-		err := keyset.RemoveValue(
-			access,
-			setKeys,
-			toDelete,
-		)
+		setKey := annotationindex.NumericAnnotationIndexKey(numericAnnotation.Key, numericAnnotation.Value)
+		endTrace := storageutil.TraceIndex(access, "numericAnnotation:"+numericAnnotation.Key+"="+strconv.FormatUint(numericAnnotation.Value, 10), setKey)
+		err := keyset.RemoveValue(access, setKey, toDelete)
+		endTrace()
 		if err != nil {
 			return fmt.Errorf("failed to remove key %s from the numeric annotation list: %w", toDelete, err)
 		}
 	}
 
+	for _, tagAnnotation := range md.TagAnnotations {
+		for _, value := range tagAnnotation.Values {
+			setKey := annotationindex.StringAnnotationIndexKey(tagAnnotation.Key, value)
+			endTrace := storageutil.TraceIndex(access, "tagAnnotation:"+tagAnnotation.Key+"="+value, setKey)
+			err := keyset.RemoveValue(access, setKey, toDelete)
+			endTrace()
+			if err != nil {
+				return fmt.Errorf("failed to remove key %s from the tag annotation list: %w", toDelete, err)
+			}
+		}
+	}
+
+	if kaolinIndexed {
+		for _, annotationKey := range annotationKeys(*md) {
+			setKey := annotationindex.AnnotationKeyIndexKey(annotationKey)
+			endTrace := storageutil.TraceIndex(access, "annotationKey:"+annotationKey, setKey)
+			err := keyset.RemoveValue(access, setKey, toDelete)
+			endTrace()
+			if err != nil {
+				return fmt.Errorf("failed to remove key %s from the annotation key list: %w", toDelete, err)
+			}
+		}
+	}
+
 	err = entityexpiration.RemoveFromEntitiesToExpire(access, md.ExpiresAtBlock, toDelete)
 	if err != nil {
 		return fmt.Errorf("failed to remove entity from entities to expire: %w", err)
@@ -57,7 +86,22 @@ func Delete(access StateAccess, toDelete common.Hash) error {
 		return fmt.Errorf("failed to remove entity from owner entities: %w", err)
 	}
 
+	if kaolinIndexed {
+		endTrace := storageutil.TraceIndex(access, "ownerUsage", common.BytesToHash(md.Owner.Bytes()))
+		err := ownerusage.Sub(access, md.Owner, UsageOf(*md, uint64(len(GetPayload(access, toDelete)))))
+		endTrace()
+		if err != nil {
+			return fmt.Errorf("failed to update owner usage: %w", err)
+		}
+	}
+
+	if rules.IsKaolin {
+		clearKaolinIndexed(access, toDelete)
+	}
+
+	endTrace := storageutil.TraceIndex(access, "payload", toDelete)
 	DeletePayload(access, toDelete)
+	endTrace()
 
 	return nil
 }
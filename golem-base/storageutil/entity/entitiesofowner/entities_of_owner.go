@@ -12,22 +12,29 @@ type StateAccess = storageutil.StateAccess
 
 var OwnerEntitiesSalt = []byte("golemBase.entitiesOfOwner")
 
+// OwnerEntitiesKey returns the key of the set of the entities of the owner.
+func OwnerEntitiesKey(owner common.Address) common.Hash {
+	return crypto.Keccak256Hash(OwnerEntitiesSalt, owner.Bytes())
+}
+
 func AddEntity(db StateAccess, owner common.Address, entity common.Hash) error {
-	ownerKey := crypto.Keccak256Hash(OwnerEntitiesSalt, owner.Bytes())
+	ownerKey := OwnerEntitiesKey(owner)
+	defer storageutil.TraceIndex(db, "entitiesOfOwner", ownerKey)()
 	return keyset.AddValue(db, ownerKey, entity)
 }
 
 func RemoveEntity(db StateAccess, owner common.Address, entity common.Hash) error {
-	ownerKey := crypto.Keccak256Hash(OwnerEntitiesSalt, owner.Bytes())
+	ownerKey := OwnerEntitiesKey(owner)
+	defer storageutil.TraceIndex(db, "entitiesOfOwner", ownerKey)()
 	return keyset.RemoveValue(db, ownerKey, entity)
 }
 
 func Iterate(db StateAccess, owner common.Address) func(yield func(entity common.Hash) bool) {
-	ownerKey := crypto.Keccak256Hash(OwnerEntitiesSalt, owner.Bytes())
+	ownerKey := OwnerEntitiesKey(owner)
 	return keyset.Iterate(db, ownerKey)
 }
 
 func Count(db StateAccess, owner common.Address) *uint256.Int {
-	ownerKey := crypto.Keccak256Hash(OwnerEntitiesSalt, owner.Bytes())
+	ownerKey := OwnerEntitiesKey(owner)
 	return keyset.Size(db, ownerKey)
 }
@@ -14,9 +14,14 @@ type StateAccess = storageutil.StateAccess
 
 var BlockExpirationSalt = []byte("golemBaseExpiresAtBlock")
 
+// expiresAtBlockKey returns the key of the set of entities that expire at the block.
+func expiresAtBlockKey(blockNumber uint64) common.Hash {
+	return crypto.Keccak256Hash(BlockExpirationSalt, uint256.NewInt(blockNumber).Bytes())
+}
+
 func AddToEntitiesToExpireAtBlock(access StateAccess, blockNumber uint64, entityKey common.Hash) error {
-	expiresAtBlockNumberBig := uint256.NewInt(blockNumber)
-	expiredEntityKey := crypto.Keccak256Hash(BlockExpirationSalt, expiresAtBlockNumberBig.Bytes())
+	expiredEntityKey := expiresAtBlockKey(blockNumber)
+	defer storageutil.TraceIndex(access, "expiration", expiredEntityKey)()
 	err := keyset.AddValue(access, expiredEntityKey, entityKey)
 	if err != nil {
 		return fmt.Errorf("failed to append to key list: %w", err)
@@ -0,0 +1,9 @@
+package entityexpiration
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+)
+
+func CountEntitiesToExpireAtBlock(access StateAccess, blockNumber uint64) uint64 {
+	return keyset.Size(access, expiresAtBlockKey(blockNumber)).Uint64()
+}
@@ -0,0 +1,76 @@
+package entityexpiration
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/holiman/uint256"
+)
+
+// ExpirationBacklogKey identifies the set of block numbers whose expired entities
+// could not all be deleted by housekeeping in their own block.
+// The entities themselves stay in the per-block expiration sets until they are deleted.
+var ExpirationBacklogKey = crypto.Keccak256Hash([]byte("golemBaseExpirationBacklog"))
+
+func AddToBacklog(access StateAccess, blockNumber uint64) error {
+	defer storageutil.TraceIndex(access, "expirationBacklog", ExpirationBacklogKey)()
+	return keyset.AddValue(access, ExpirationBacklogKey, uint256.NewInt(blockNumber).Bytes32())
+}
+
+func RemoveFromBacklog(access StateAccess, blockNumber uint64) error {
+	defer storageutil.TraceIndex(access, "expirationBacklog", ExpirationBacklogKey)()
+	return keyset.RemoveValue(access, ExpirationBacklogKey, uint256.NewInt(blockNumber).Bytes32())
+}
+
+func HasBacklog(access StateAccess) bool {
+	return !keyset.Size(access, ExpirationBacklogKey).IsZero()
+}
+
+// IterateBacklog iterates over the block numbers that still have entities waiting to be expired.
+// The order of the block numbers is not defined.
+func IterateBacklog(access StateAccess) func(yield func(blockNumber uint64) bool) {
+	return func(yield func(blockNumber uint64) bool) {
+		for v := range keyset.Iterate(access, ExpirationBacklogKey) {
+			if !yield(new(uint256.Int).SetBytes32(v[:]).Uint64()) {
+				return
+			}
+		}
+	}
+}
+
+// BacklogSize returns the number of entities that have expired, but are not deleted yet.
+func BacklogSize(access StateAccess) uint64 {
+	size := uint64(0)
+	for blockNumber := range IterateBacklog(access) {
+		size += CountEntitiesToExpireAtBlock(access, blockNumber)
+	}
+	return size
+}
+
+// ExpiredEntities returns the entities that have expired, but are still waiting in the
+// expiration backlog to be deleted by housekeeping. Such entities are hidden from all reads.
+func ExpiredEntities(access StateAccess) map[common.Hash]struct{} {
+	expired := map[common.Hash]struct{}{}
+	for blockNumber := range IterateBacklog(access) {
+		for key := range IteratorOfEntitiesToExpireAtBlock(access, blockNumber) {
+			expired[key] = struct{}{}
+		}
+	}
+	return expired
+}
+
+// IsInBacklog reports whether the block has expired entities that are not deleted yet.
+// An entity that expires at such a block has expired and is hidden from all reads.
+func IsInBacklog(access StateAccess, blockNumber uint64) bool {
+	return keyset.ContainsValue(access, ExpirationBacklogKey, uint256.NewInt(blockNumber).Bytes32())
+}
+
+// NextEntityToExpireAtBlock returns one of the entities that expire at the block,
+// or false if there are none left.
+func NextEntityToExpireAtBlock(access StateAccess, blockNumber uint64) (common.Hash, bool) {
+	for key := range IteratorOfEntitiesToExpireAtBlock(access, blockNumber) {
+		return key, true
+	}
+	return common.Hash{}, false
+}
@@ -30,7 +30,9 @@ func ExtendTTL(
 		return 0, fmt.Errorf("failed to add to entities to expire at block %d: %w", entity.ExpiresAtBlock, err)
 	}
 
+	endTrace := storageutil.TraceIndex(access, "metadata", entityKey)
 	err = StoreEntityMetaData(access, entityKey, *entity)
+	endTrace()
 	if err != nil {
 		return 0, fmt.Errorf("failed to store entity meta data: %w", err)
 	}
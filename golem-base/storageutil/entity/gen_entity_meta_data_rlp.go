@@ -2,6 +2,7 @@
 
 package entity
 
+import "github.com/jeffcogswell/golembase-op-geth/common"
 import "github.com/jeffcogswell/golembase-op-geth/rlp"
 import "io"
 
@@ -26,6 +27,38 @@ func (obj *EntityMetaData) EncodeRLP(_w io.Writer) error {
 	}
 	w.ListEnd(_tmp4)
 	w.WriteBytes(obj.Owner[:])
+	_tmp7 := len(obj.TagAnnotations) > 0
+	_tmp8 := obj.ContentMetaData != (ContentMetaData{})
+	if _tmp7 || _tmp8 {
+		_tmp9 := w.List()
+		for _, _tmp10 := range obj.TagAnnotations {
+			_tmp11 := w.List()
+			w.WriteString(_tmp10.Key)
+			_tmp12 := w.List()
+			for _, _tmp13 := range _tmp10.Values {
+				w.WriteString(_tmp13)
+			}
+			w.ListEnd(_tmp12)
+			w.ListEnd(_tmp11)
+		}
+		w.ListEnd(_tmp9)
+	}
+	if _tmp8 {
+		_tmp14 := w.List()
+		_tmp15 := obj.ContentMetaData.ContentType != ""
+		_tmp16 := obj.ContentMetaData.ContentEncoding != ""
+		_tmp17 := obj.ContentMetaData.PayloadHash != (common.Hash{})
+		if _tmp15 || _tmp16 || _tmp17 {
+			w.WriteString(obj.ContentMetaData.ContentType)
+		}
+		if _tmp16 || _tmp17 {
+			w.WriteString(obj.ContentMetaData.ContentEncoding)
+		}
+		if _tmp17 {
+			w.WriteBytes(obj.ContentMetaData.PayloadHash[:])
+		}
+		w.ListEnd(_tmp14)
+	}
 	w.ListEnd(_tmp0)
 	return w.Flush()
 }
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"slices"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+)
+
+// IsExpired reports whether the entity has expired, but is still waiting in the expiration
+// backlog to be deleted by housekeeping. Such entities are hidden from all reads and can
+// not be updated or extended. The check is a lookup of the expiration block of the entity
+// in the backlog, its cost does not depend on the size of the backlog.
+func IsExpired(access StateAccess, key common.Hash) bool {
+	if !entityexpiration.HasBacklog(access) {
+		return false
+	}
+
+	md, err := GetEntityMetaData(access, key)
+	if err != nil {
+		return false
+	}
+
+	return entityexpiration.IsInBacklog(access, md.ExpiresAtBlock)
+}
+
+// WithoutExpired removes the expired entities from keys.
+// A nil slice is returned as an empty one.
+func WithoutExpired(access StateAccess, keys []common.Hash) []common.Hash {
+	if keys == nil {
+		return make([]common.Hash, 0)
+	}
+
+	if !entityexpiration.HasBacklog(access) {
+		return keys
+	}
+
+	return slices.DeleteFunc(keys, func(key common.Hash) bool {
+		return IsExpired(access, key)
+	})
+}
@@ -0,0 +1,121 @@
+package entity
+
+import (
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
+)
+
+// KaolinMigrationKey is the slot of the storage processor that is set once the
+// entities stored before Kaolin have been added to the indexes introduced by it.
+var KaolinMigrationKey = crypto.Keccak256Hash([]byte("golemBase.kaolinMigration"))
+
+// KaolinMigrationCursorKey is the slot of the storage processor that holds the number of
+// entities at the start of the registry of all entities that the migration still has to
+// check, while the migration is in progress.
+var KaolinMigrationCursorKey = crypto.Keccak256Hash([]byte("golemBase.kaolinMigrationCursor"))
+
+// KaolinIndexedSalt derives the slots that mark the entities that are in the indexes
+// introduced by Kaolin while the migration is in progress.
+var KaolinIndexedSalt = []byte("golemBase.kaolinIndexed")
+
+// KaolinMigrationEntitiesPerBlock is the number of entities the migration checks per block.
+const KaolinMigrationEntitiesPerBlock = 1000
+
+// IsMigratedToKaolin returns true if the state has already been migrated to Kaolin.
+func IsMigratedToKaolin(access StateAccess) bool {
+	return access.GetState(storageutil.GolemDBAddress, KaolinMigrationKey) != (common.Hash{})
+}
+
+// MarkMigratedToKaolin records that the state does not need to be migrated to Kaolin.
+func MarkMigratedToKaolin(access StateAccess) {
+	access.SetState(storageutil.GolemDBAddress, KaolinMigrationKey, common.BigToHash(common.Big1))
+	access.SetState(storageutil.GolemDBAddress, KaolinMigrationCursorKey, common.Hash{})
+}
+
+// MigrateToKaolin adds the entities stored before Kaolin to the usage counters of their
+// owners and to the annotation key index, which are only maintained since Kaolin.
+// It runs once per block from the first Kaolin block on and checks at most maxEntities
+// entities per block, so the migration is spread over as many blocks as it takes; once
+// all entities are checked, later calls do nothing.
+//
+// The registry of all entities is walked from its end to its start. Entities stored,
+// deleted or moved in the registry while the migration is in progress are told apart
+// by their marks, see isKaolinIndexed.
+func MigrateToKaolin(access StateAccess, maxEntities uint64) error {
+	if IsMigratedToKaolin(access) {
+		return nil
+	}
+
+	cursorValue := access.GetState(storageutil.GolemDBAddress, KaolinMigrationCursorKey)
+	cursor := new(uint256.Int).SetBytes32(cursorValue[:]).Uint64()
+	if cursorValue == (common.Hash{}) {
+		// first Kaolin block
+		cursor = allentities.Count(access)
+	}
+
+	for checked := uint64(0); checked < maxEntities; checked++ {
+		// deleting entities shrinks the registry, the last entity takes the place of a deleted one
+		cursor = min(cursor, allentities.Count(access))
+		if cursor == 0 {
+			MarkMigratedToKaolin(access)
+			return nil
+		}
+		cursor--
+
+		key := allentities.At(access, cursor)
+		if isKaolinIndexed(access, key) {
+			continue
+		}
+
+		md, err := GetEntityMetaData(access, key)
+		if err != nil {
+			return fmt.Errorf("failed to get meta data of entity %s: %w", key.Hex(), err)
+		}
+
+		err = addToKaolinIndexes(access, key, *md, GetPayload(access, key))
+		if err != nil {
+			return fmt.Errorf("failed to migrate entity %s: %w", key.Hex(), err)
+		}
+	}
+
+	if cursor == 0 {
+		MarkMigratedToKaolin(access)
+		return nil
+	}
+
+	access.SetState(storageutil.GolemDBAddress, KaolinMigrationCursorKey, uint256.NewInt(cursor).Bytes32())
+	return nil
+}
+
+func kaolinIndexedKey(key common.Hash) common.Hash {
+	return crypto.Keccak256Hash(KaolinIndexedSalt, key[:])
+}
+
+// isKaolinIndexed reports whether the entity is in the usage counters and the annotation key
+// index. Once the migration is complete, all entities are. While it is in progress, only the
+// entities that were migrated or stored since Kaolin are, and those are marked.
+func isKaolinIndexed(access StateAccess, key common.Hash) bool {
+	return IsMigratedToKaolin(access) || access.GetState(storageutil.GolemDBAddress, kaolinIndexedKey(key)) != (common.Hash{})
+}
+
+// setKaolinIndexed marks the entity as added to the indexes introduced by Kaolin,
+// if the migration is still in progress.
+func setKaolinIndexed(access StateAccess, key common.Hash) {
+	if IsMigratedToKaolin(access) {
+		return
+	}
+	access.SetState(storageutil.GolemDBAddress, kaolinIndexedKey(key), common.BigToHash(common.Big1))
+}
+
+// clearKaolinIndexed removes the mark of a deleted entity.
+func clearKaolinIndexed(access StateAccess, key common.Hash) {
+	slot := kaolinIndexedKey(key)
+	if access.GetState(storageutil.GolemDBAddress, slot) != (common.Hash{}) {
+		access.SetState(storageutil.GolemDBAddress, slot, common.Hash{})
+	}
+}
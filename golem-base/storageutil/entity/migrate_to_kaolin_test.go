@@ -0,0 +1,69 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/annotationindex"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateToKaolinAcrossBlocks(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1")
+	kaolin := params.GolemBaseRules{IsKaolin: true}
+
+	store := func(rules params.GolemBaseRules, i byte) common.Hash {
+		key := common.Hash{i}
+		err := entity.Store(rules, db, key, owner, entity.EntityMetaData{
+			Owner:             owner,
+			ExpiresAtBlock:    100,
+			StringAnnotations: []entity.StringAnnotation{{Key: "kind", Value: "a"}},
+		}, []byte("payload"))
+		require.NoError(t, err)
+		return key
+	}
+
+	keys := []common.Hash{}
+	for i := range byte(5) {
+		keys = append(keys, store(params.GolemBaseRules{}, i+1))
+	}
+
+	// the first block checks the last two entities of the registry
+	require.NoError(t, entity.MigrateToKaolin(db, 2))
+	require.False(t, entity.IsMigratedToKaolin(db))
+	require.Equal(t, ownerusage.Usage{Entities: 2, PayloadBytes: 14, Annotations: 2}, ownerusage.Get(db, owner))
+
+	// a migrated entity is moved into the part that is not checked yet, an entity
+	// that is not migrated yet is deleted, and a new one is stored
+	require.NoError(t, entity.Delete(kaolin, db, keys[0]))
+	require.Equal(t, ownerusage.Usage{Entities: 2, PayloadBytes: 14, Annotations: 2}, ownerusage.Get(db, owner))
+	store(kaolin, 6)
+
+	require.NoError(t, entity.MigrateToKaolin(db, 2))
+	require.False(t, entity.IsMigratedToKaolin(db))
+	require.NoError(t, entity.MigrateToKaolin(db, 2))
+	require.True(t, entity.IsMigratedToKaolin(db))
+
+	want := ownerusage.Usage{Entities: 5, PayloadBytes: 35, Annotations: 5}
+	require.Equal(t, want, ownerusage.Get(db, owner))
+	require.Equal(t, want, ownerusage.Total(db))
+	require.Equal(t, uint64(5), keyset.Size(db, annotationindex.AnnotationKeyIndexKey("kind")).Uint64())
+
+	// later calls do nothing
+	require.NoError(t, entity.MigrateToKaolin(db, 2))
+	require.Equal(t, want, ownerusage.Get(db, owner))
+
+	for _, key := range keys[1:] {
+		require.NoError(t, entity.Delete(kaolin, db, key))
+	}
+	require.Equal(t, ownerusage.Usage{Entities: 1, PayloadBytes: 7, Annotations: 1}, ownerusage.Get(db, owner))
+}
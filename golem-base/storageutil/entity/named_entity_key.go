@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/holiman/uint256"
+)
+
+var NamedEntityKeySalt = []byte("golemBaseNamedEntityKey")
+
+// NamedEntityKey derives the key of an entity created with a client chosen name.
+// Similar to CREATE2, the key only depends on the owner, the namespace and the name,
+// so it is known before the creating transaction is mined and can be used as a
+// well-known address for the entity.
+// The namespace is prefixed with its length as a 32 byte big endian number, so that no
+// two pairs of namespace and name hash the same input, whatever characters they contain.
+func NamedEntityKey(owner common.Address, namespace, name string) common.Hash {
+	namespaceLength := uint256.NewInt(uint64(len(namespace))).PaddedBytes(32)
+	return crypto.Keccak256Hash(NamedEntityKeySalt, owner.Bytes(), namespaceLength, []byte(namespace), []byte(name))
+}
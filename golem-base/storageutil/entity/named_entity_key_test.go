@@ -0,0 +1,34 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamedEntityKeyIsUnambiguous(t *testing.T) {
+	owner := common.HexToAddress("0x1234")
+
+	// pairs that concatenate to the same bytes, with or without a separator
+	pairs := [][2]string{
+		{"notes", "first"},
+		{"notes|", "first"},
+		{"notes", "|first"},
+		{"note", "sfirst"},
+		{"", "notesfirst"},
+		{"notes|\"|", "first"},
+		{"notes", "\"|first"},
+	}
+
+	keys := map[common.Hash][2]string{}
+	for _, pair := range pairs {
+		key := entity.NamedEntityKey(owner, pair[0], pair[1])
+		other, found := keys[key]
+		require.False(t, found, "namespace %q and name %q have the same key as namespace %q and name %q", pair[0], pair[1], other[0], other[1])
+		keys[key] = pair
+	}
+
+	require.NotEqual(t, entity.NamedEntityKey(owner, "notes", "first"), entity.NamedEntityKey(common.HexToAddress("0x5678"), "notes", "first"))
+}
@@ -0,0 +1,140 @@
+// Package ownerusage keeps running counters of the storage used by each owner.
+//
+// For every owner the number of entities, the number of payload bytes and the number
+// of annotations are stored in three consecutive storage slots, starting at
+// keccak256(OwnerUsageSalt, owner). The counters are updated whenever an entity
+// is stored or deleted, so reading the usage of an owner is O(1).
+//
+// The usage of all owners together is kept in the same way, starting at keccak256(TotalUsageSalt).
+package ownerusage
+
+import (
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/holiman/uint256"
+)
+
+type StateAccess = storageutil.StateAccess
+
+var OwnerUsageSalt = []byte("golemBase.ownerUsage")
+
+var TotalUsageSalt = []byte("golemBase.totalUsage")
+
+// Usage is the storage used by an owner.
+type Usage struct {
+	Entities     uint64 `json:"entities"`
+	PayloadBytes uint64 `json:"payloadBytes"`
+	Annotations  uint64 `json:"annotations"`
+}
+
+// usageSlots returns the storage slots of the entity, payload bytes and annotation counters of the owner.
+func usageSlots(owner common.Address) [3]common.Hash {
+	return slotsAt(crypto.Keccak256(OwnerUsageSalt, owner.Bytes()))
+}
+
+// totalUsageSlots returns the storage slots of the counters of all owners together.
+func totalUsageSlots() [3]common.Hash {
+	return slotsAt(crypto.Keccak256(TotalUsageSalt))
+}
+
+func slotsAt(start []byte) [3]common.Hash {
+	base := new(uint256.Int).SetBytes32(start)
+	slots := [3]common.Hash{}
+	for i := range slots {
+		slots[i] = new(uint256.Int).AddUint64(base, uint64(i)).Bytes32()
+	}
+	return slots
+}
+
+func getCounter(db StateAccess, slot common.Hash) uint64 {
+	v := db.GetState(storageutil.GolemDBAddress, slot)
+	return new(uint256.Int).SetBytes32(v[:]).Uint64()
+}
+
+func setCounter(db StateAccess, slot common.Hash, value uint64) {
+	db.SetState(storageutil.GolemDBAddress, slot, uint256.NewInt(value).Bytes32())
+}
+
+// Get returns the current usage of the owner.
+func Get(db StateAccess, owner common.Address) Usage {
+	return getAt(db, usageSlots(owner))
+}
+
+// Total returns the current usage of all owners together.
+func Total(db StateAccess) Usage {
+	return getAt(db, totalUsageSlots())
+}
+
+func getAt(db StateAccess, slots [3]common.Hash) Usage {
+	return Usage{
+		Entities:     getCounter(db, slots[0]),
+		PayloadBytes: getCounter(db, slots[1]),
+		Annotations:  getCounter(db, slots[2]),
+	}
+}
+
+func setAt(db StateAccess, slots [3]common.Hash, u Usage) {
+	setCounter(db, slots[0], u.Entities)
+	setCounter(db, slots[1], u.PayloadBytes)
+	setCounter(db, slots[2], u.Annotations)
+}
+
+// Add adds the usage of a stored entity to the counters of the owner and to the total counters.
+func Add(db StateAccess, owner common.Address, u Usage) {
+	setAt(db, usageSlots(owner), Get(db, owner).plus(u))
+	setAt(db, totalUsageSlots(), Total(db).plus(u))
+}
+
+// Sub removes the usage of a deleted entity from the counters of the owner and from the total counters.
+// It returns an error if a counter would go below zero, which means the counters
+// are out of sync with the stored entities.
+func Sub(db StateAccess, owner common.Address, u Usage) error {
+	owned, err := Get(db, owner).minus(u)
+	if err != nil {
+		return fmt.Errorf("usage of owner %s: %w", owner.Hex(), err)
+	}
+
+	total, err := Total(db).minus(u)
+	if err != nil {
+		return fmt.Errorf("total usage: %w", err)
+	}
+
+	setAt(db, usageSlots(owner), owned)
+	setAt(db, totalUsageSlots(), total)
+	return nil
+}
+
+func (u Usage) plus(o Usage) Usage {
+	return Usage{
+		Entities:     u.Entities + o.Entities,
+		PayloadBytes: u.PayloadBytes + o.PayloadBytes,
+		Annotations:  u.Annotations + o.Annotations,
+	}
+}
+
+// Minus returns the usage u without o, with every counter saturating at zero.
+func (u Usage) Minus(o Usage) Usage {
+	return Usage{
+		Entities:     saturatingSub(u.Entities, o.Entities),
+		PayloadBytes: saturatingSub(u.PayloadBytes, o.PayloadBytes),
+		Annotations:  saturatingSub(u.Annotations, o.Annotations),
+	}
+}
+
+// minus returns the usage u without o, or an error if any counter of o exceeds the one of u.
+func (u Usage) minus(o Usage) (Usage, error) {
+	if o.Entities > u.Entities || o.PayloadBytes > u.PayloadBytes || o.Annotations > u.Annotations {
+		return Usage{}, fmt.Errorf("cannot subtract %+v from %+v", o, u)
+	}
+	return u.Minus(o), nil
+}
+
+func saturatingSub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
@@ -0,0 +1,33 @@
+package ownerusage_test
+
+import (
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubFailsOnUnderflow(t *testing.T) {
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1")
+	other := common.HexToAddress("0x2")
+	ownerusage.Add(db, owner, ownerusage.Usage{Entities: 1, PayloadBytes: 10, Annotations: 2})
+
+	err = ownerusage.Sub(db, owner, ownerusage.Usage{Entities: 1, PayloadBytes: 11, Annotations: 2})
+	require.Error(t, err)
+	require.Equal(t, ownerusage.Usage{Entities: 1, PayloadBytes: 10, Annotations: 2}, ownerusage.Get(db, owner))
+
+	err = ownerusage.Sub(db, other, ownerusage.Usage{Entities: 1})
+	require.Error(t, err)
+	require.Equal(t, ownerusage.Usage{Entities: 1, PayloadBytes: 10, Annotations: 2}, ownerusage.Total(db))
+
+	err = ownerusage.Sub(db, owner, ownerusage.Usage{Entities: 1, PayloadBytes: 10, Annotations: 2})
+	require.NoError(t, err)
+	require.Equal(t, ownerusage.Usage{}, ownerusage.Get(db, owner))
+	require.Equal(t, ownerusage.Usage{}, ownerusage.Total(db))
+}
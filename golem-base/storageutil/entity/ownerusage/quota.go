@@ -0,0 +1,46 @@
+package ownerusage
+
+import (
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/params"
+)
+
+// Quota limits the storage an owner can use. A zero limit means there is no limit.
+type Quota struct {
+	MaxEntities     uint64
+	MaxPayloadBytes uint64
+	MaxAnnotations  uint64
+}
+
+// Check returns an error if usage exceeds the quota in a counter that grew compared to previous.
+// Owners that are already over a quota (for example with the entities they stored before Kaolin) can still
+// delete entities or shrink them, but not grow further.
+func (q Quota) Check(previous, usage Usage) error {
+	exceeds := func(limit, previous, current uint64) bool {
+		return limit != 0 && current > limit && current > previous
+	}
+
+	switch {
+	case exceeds(q.MaxEntities, previous.Entities, usage.Entities):
+		return fmt.Errorf("entity quota exceeded: %d entities, limit is %d", usage.Entities, q.MaxEntities)
+	case exceeds(q.MaxPayloadBytes, previous.PayloadBytes, usage.PayloadBytes):
+		return fmt.Errorf("payload quota exceeded: %d bytes, limit is %d", usage.PayloadBytes, q.MaxPayloadBytes)
+	case exceeds(q.MaxAnnotations, previous.Annotations, usage.Annotations):
+		return fmt.Errorf("annotation quota exceeded: %d annotations, limit is %d", usage.Annotations, q.MaxAnnotations)
+	}
+
+	return nil
+}
+
+// QuotaOf returns the per-owner quota configured for the chain.
+func QuotaOf(config *params.ChainConfig) Quota {
+	if config.GolemBase == nil {
+		return Quota{}
+	}
+	return Quota{
+		MaxEntities:     config.GolemBase.MaxEntitiesPerOwner,
+		MaxPayloadBytes: config.GolemBase.MaxPayloadBytesPerOwner,
+		MaxAnnotations:  config.GolemBase.MaxAnnotationsPerOwner,
+	}
+}
@@ -0,0 +1,46 @@
+package pendingupload
+
+import (
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+)
+
+var AbandonedAtBlockSalt = []byte("golemBase.uploadsAbandonedAtBlock")
+
+func abandonedAtBlockKey(blockNumber uint64) common.Hash {
+	return crypto.Keccak256Hash(AbandonedAtBlockSalt, uint256.NewInt(blockNumber).Bytes())
+}
+
+func addToAbandonedAtBlock(access StateAccess, blockNumber uint64, key common.Hash) error {
+	setKey := abandonedAtBlockKey(blockNumber)
+	defer storageutil.TraceIndex(access, "uploadAbandonment", setKey)()
+	err := keyset.AddValue(access, setKey, key)
+	if err != nil {
+		return fmt.Errorf("failed to add upload to the uploads abandoned at block %d: %w", blockNumber, err)
+	}
+	return nil
+}
+
+func removeFromAbandonedAtBlock(access StateAccess, blockNumber uint64, key common.Hash) error {
+	setKey := abandonedAtBlockKey(blockNumber)
+	defer storageutil.TraceIndex(access, "uploadAbandonment", setKey)()
+	err := keyset.RemoveValue(access, setKey, key)
+	if err != nil {
+		return fmt.Errorf("failed to remove upload from the uploads abandoned at block %d: %w", blockNumber, err)
+	}
+	return nil
+}
+
+// NextAbandonedAtBlock returns one of the uploads that are abandoned at the block,
+// or false if there are none left.
+func NextAbandonedAtBlock(access StateAccess, blockNumber uint64) (common.Hash, bool) {
+	for key := range keyset.Iterate(access, abandonedAtBlockKey(blockNumber)) {
+		return key, true
+	}
+	return common.Hash{}, false
+}
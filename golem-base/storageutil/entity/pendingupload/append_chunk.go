@@ -0,0 +1,52 @@
+package pendingupload
+
+import (
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/stateblob"
+)
+
+// AppendChunk appends data at offset to the payload of the upload and postpones its
+// abandonment to Timeout blocks after blockNumber.
+// The offset has to be the number of bytes received so far, so a chunk that was already
+// included can not be appended twice when an interrupted upload is resumed.
+func AppendChunk(access StateAccess, key common.Hash, blockNumber uint64, offset uint64, data []byte) (*PendingUpload, error) {
+	upload, err := Get(access, key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(data) == 0:
+		return nil, fmt.Errorf("chunk for upload %s is empty", key.Hex())
+	case offset != upload.Received:
+		return nil, fmt.Errorf("chunk for upload %s starts at offset %d, expected %d", key.Hex(), offset, upload.Received)
+	case upload.Received+uint64(len(data)) > upload.Size:
+		return nil, fmt.Errorf("chunk for upload %s exceeds the payload size of %d bytes", key.Hex(), upload.Size)
+	}
+
+	stateblob.SetBlob(access, chunkKey(key, upload.Chunks), data)
+
+	upload.Received += uint64(len(data))
+	upload.Chunks++
+
+	err = removeFromAbandonedAtBlock(access, upload.AbandonedAtBlock, key)
+	if err != nil {
+		return nil, err
+	}
+
+	upload.AbandonedAtBlock = blockNumber + Timeout
+
+	err = addToAbandonedAtBlock(access, upload.AbandonedAtBlock, key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store(access, key, upload)
+	if err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
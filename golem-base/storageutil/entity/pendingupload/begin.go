@@ -0,0 +1,40 @@
+package pendingupload
+
+import (
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+)
+
+// Begin starts an upload with the key, it is abandoned Timeout blocks after blockNumber
+// unless a chunk is appended before. The usage of the upload is added to the counters of its owner.
+func Begin(access StateAccess, key common.Hash, blockNumber uint64, upload PendingUpload) error {
+	if Contains(access, key) {
+		return fmt.Errorf("upload %s already exists", key.Hex())
+	}
+
+	upload.Received = 0
+	upload.Chunks = 0
+	upload.AbandonedAtBlock = blockNumber + Timeout
+
+	endTrace := storageutil.TraceIndex(access, "pendingUploads", PendingUploadsKey)
+	err := keyset.AddValue(access, PendingUploadsKey, key)
+	endTrace()
+	if err != nil {
+		return fmt.Errorf("failed to add upload to pending uploads: %w", err)
+	}
+
+	err = addToAbandonedAtBlock(access, upload.AbandonedAtBlock, key)
+	if err != nil {
+		return err
+	}
+
+	endTrace = storageutil.TraceIndex(access, "ownerUsage", common.BytesToHash(upload.Owner.Bytes()))
+	ownerusage.Add(access, upload.Owner, upload.Usage())
+	endTrace()
+
+	return store(access, key, &upload)
+}
@@ -0,0 +1,80 @@
+package pendingupload
+
+import (
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/stateblob"
+)
+
+// Delete removes the upload with all its chunks, after it was finalized or abandoned,
+// and removes its usage from the counters of its owner.
+func Delete(access StateAccess, key common.Hash) error {
+	upload, err := Get(access, key)
+	if err != nil {
+		return err
+	}
+
+	for i := range upload.Chunks {
+		stateblob.DeleteBlob(access, chunkKey(key, i))
+	}
+
+	err = removeFromAbandonedAtBlock(access, upload.AbandonedAtBlock, key)
+	if err != nil {
+		return err
+	}
+
+	endTrace := storageutil.TraceIndex(access, "pendingUploads", PendingUploadsKey)
+	err = keyset.RemoveValue(access, PendingUploadsKey, key)
+	endTrace()
+	if err != nil {
+		return fmt.Errorf("failed to remove upload from pending uploads: %w", err)
+	}
+
+	endTrace = storageutil.TraceIndex(access, "ownerUsage", common.BytesToHash(upload.Owner.Bytes()))
+	err = ownerusage.Sub(access, upload.Owner, upload.Usage())
+	endTrace()
+	if err != nil {
+		return fmt.Errorf("failed to update owner usage: %w", err)
+	}
+
+	stateblob.DeleteBlob(access, crypto.Keccak256Hash(UploadSalt, key[:]))
+
+	return nil
+}
+
+// DeleteAbandoned removes an abandoned upload, its chunks from the last one on and then the
+// upload itself. It deletes at most maxBlobs chunks and uploads, or all of them if maxBlobs
+// is zero, and returns the number it deleted and whether the upload is gone. An upload that
+// is not gone keeps the chunks that are left until the next call.
+func DeleteAbandoned(access StateAccess, key common.Hash, maxBlobs uint64) (uint64, bool, error) {
+	upload, err := Get(access, key)
+	if err != nil {
+		return 0, false, err
+	}
+
+	deleted := uint64(0)
+	for upload.Chunks > 0 {
+		if maxBlobs != 0 && deleted == maxBlobs {
+			return deleted, false, store(access, key, upload)
+		}
+		upload.Chunks--
+		upload.Received -= stateblob.DeleteBlob(access, chunkKey(key, upload.Chunks))
+		deleted++
+	}
+
+	if maxBlobs != 0 && deleted == maxBlobs {
+		return deleted, false, store(access, key, upload)
+	}
+
+	err = Delete(access, key)
+	if err != nil {
+		return deleted, false, err
+	}
+
+	return deleted + 1, true, nil
+}
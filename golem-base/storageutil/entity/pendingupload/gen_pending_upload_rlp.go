@@ -0,0 +1,58 @@
+// Code generated by rlpgen. DO NOT EDIT.
+
+package pendingupload
+
+import "github.com/jeffcogswell/golembase-op-geth/rlp"
+import "io"
+
+func (obj *PendingUpload) EncodeRLP(_w io.Writer) error {
+	w := rlp.NewEncoderBuffer(_w)
+	_tmp0 := w.List()
+	w.WriteBytes(obj.Owner[:])
+	w.WriteUint64(obj.TTL)
+	_tmp1 := w.List()
+	for _, _tmp2 := range obj.StringAnnotations {
+		_tmp3 := w.List()
+		w.WriteString(_tmp2.Key)
+		w.WriteString(_tmp2.Value)
+		w.ListEnd(_tmp3)
+	}
+	w.ListEnd(_tmp1)
+	_tmp4 := w.List()
+	for _, _tmp5 := range obj.NumericAnnotations {
+		_tmp6 := w.List()
+		w.WriteString(_tmp5.Key)
+		w.WriteUint64(_tmp5.Value)
+		w.ListEnd(_tmp6)
+	}
+	w.ListEnd(_tmp4)
+	_tmp7 := w.List()
+	for _, _tmp8 := range obj.TagAnnotations {
+		_tmp9 := w.List()
+		w.WriteString(_tmp8.Key)
+		_tmp10 := w.List()
+		for _, _tmp11 := range _tmp8.Values {
+			w.WriteString(_tmp11)
+		}
+		w.ListEnd(_tmp10)
+		w.ListEnd(_tmp9)
+	}
+	w.ListEnd(_tmp7)
+	w.WriteString(obj.Namespace)
+	w.WriteString(obj.Name)
+	w.WriteUint64(obj.Size)
+	w.WriteBytes(obj.PayloadHash[:])
+	w.WriteUint64(obj.Received)
+	w.WriteUint64(obj.Chunks)
+	w.WriteUint64(obj.AbandonedAtBlock)
+	_tmp12 := obj.ContentType != ""
+	_tmp13 := obj.ContentEncoding != ""
+	if _tmp12 || _tmp13 {
+		w.WriteString(obj.ContentType)
+	}
+	if _tmp13 {
+		w.WriteString(obj.ContentEncoding)
+	}
+	w.ListEnd(_tmp0)
+	return w.Flush()
+}
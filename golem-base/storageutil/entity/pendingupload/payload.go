@@ -0,0 +1,15 @@
+package pendingupload
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/stateblob"
+)
+
+// Payload returns the chunks received for the upload so far, concatenated.
+func Payload(access StateAccess, key common.Hash, upload *PendingUpload) []byte {
+	payload := make([]byte, 0, upload.Received)
+	for i := range upload.Chunks {
+		payload = append(payload, stateblob.GetBlob(access, chunkKey(key, i))...)
+	}
+	return payload
+}
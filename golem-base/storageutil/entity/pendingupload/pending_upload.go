@@ -0,0 +1,124 @@
+// Package pendingupload keeps the state of chunked uploads of entity payloads.
+//
+// A payload that does not fit into the calldata of a single transaction is uploaded in chunks:
+// the upload is begun with the metadata of the entity, the size and the hash of the payload,
+// chunks are appended in order across several transactions, and the upload is finalized into
+// a normal entity once the whole payload was received and its hash matches.
+//
+// Every chunk is stored as a separate blob, so appending does not rewrite the chunks received before.
+// An upload that receives no chunk for Timeout blocks is abandoned and removed by housekeeping.
+//
+// While an upload is pending, the entity it will become, with its declared Size, is charged to the
+// usage counters of the owner, so the quotas also bound the data of pending uploads. The charge is
+// refunded when the upload is deleted, after it was finalized or abandoned.
+package pendingupload
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/stateblob"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+//go:generate go run ../../../../rlp/rlpgen -type PendingUpload -out gen_pending_upload_rlp.go
+
+type StateAccess = storageutil.StateAccess
+
+// Timeout is the number of blocks without a new chunk after which an upload is abandoned.
+const Timeout uint64 = 1800
+
+// PendingUploadsKey identifies the set of the keys of all pending uploads.
+var PendingUploadsKey = crypto.Keccak256Hash([]byte("golemBase.pendingUploads"))
+
+var UploadSalt = []byte("golemBase.upload")
+
+var ChunkSalt = []byte("golemBase.uploadChunk")
+
+// PendingUpload is the state of an upload that is not finalized yet.
+// The upload has the key that the entity will have once the upload is finalized.
+type PendingUpload struct {
+	Owner              common.Address             `json:"owner"`
+	TTL                uint64                     `json:"ttl"`
+	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
+	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations"`
+	Namespace          string                     `json:"namespace"`
+	Name               string                     `json:"name"`
+
+	// Size is the size of the whole payload and PayloadHash its keccak256 hash.
+	Size        uint64      `json:"size"`
+	PayloadHash common.Hash `json:"payloadHash"`
+
+	// Received is the number of payload bytes received so far in Chunks chunks.
+	// The next chunk has to start at offset Received.
+	Received uint64 `json:"received"`
+	Chunks   uint64 `json:"chunks"`
+
+	// AbandonedAtBlock is the block in which housekeeping removes the upload
+	// unless another chunk is appended or the upload is finalized before.
+	AbandonedAtBlock uint64 `json:"abandonedAtBlock"`
+
+	ContentType     string `json:"contentType,omitempty" rlp:"optional"`
+	ContentEncoding string `json:"contentEncoding,omitempty" rlp:"optional"`
+}
+
+// Contains reports whether an upload with the key is pending.
+func Contains(access StateAccess, key common.Hash) bool {
+	return keyset.ContainsValue(access, PendingUploadsKey, key)
+}
+
+// Iterate iterates over the keys of all pending uploads.
+func Iterate(access StateAccess) func(yield func(key common.Hash) bool) {
+	return keyset.Iterate(access, PendingUploadsKey)
+}
+
+// Get returns the pending upload with the key.
+func Get(access StateAccess, key common.Hash) (*PendingUpload, error) {
+	if !Contains(access, key) {
+		return nil, fmt.Errorf("upload %s not found", key.Hex())
+	}
+
+	d := stateblob.GetBlob(access, crypto.Keccak256Hash(UploadSalt, key[:]))
+
+	upload := PendingUpload{}
+	err := rlp.DecodeBytes(d, &upload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode upload %s: %w", key.Hex(), err)
+	}
+
+	return &upload, nil
+}
+
+// Usage returns the storage the upload is charged to its owner while it is pending:
+// that of the entity it becomes once the whole payload is received.
+func (u *PendingUpload) Usage() ownerusage.Usage {
+	return entity.UsageOf(entity.EntityMetaData{
+		StringAnnotations:  u.StringAnnotations,
+		NumericAnnotations: u.NumericAnnotations,
+		TagAnnotations:     u.TagAnnotations,
+	}, u.Size)
+}
+
+func store(access StateAccess, key common.Hash, upload *PendingUpload) error {
+	buf := new(bytes.Buffer)
+	err := rlp.Encode(buf, upload)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload: %w", err)
+	}
+
+	stateblob.SetBlob(access, crypto.Keccak256Hash(UploadSalt, key[:]), buf.Bytes())
+	return nil
+}
+
+func chunkKey(key common.Hash, index uint64) common.Hash {
+	paddedIndex := uint256.NewInt(index).Bytes32()
+	return crypto.Keccak256Hash(ChunkSalt, key[:], paddedIndex[:])
+}
@@ -2,6 +2,8 @@ package entity
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
@@ -9,12 +11,17 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/annotationindex"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entitiesofowner"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/entityexpiration"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/keyset"
+	"github.com/jeffcogswell/golembase-op-geth/params"
 )
 
 type StateAccess = storageutil.StateAccess
 
+// Store adds the entity to the state and to all indexes.
+// The owner usage and the annotation key index are only maintained since Kaolin.
 func Store(
+	rules params.GolemBaseRules,
 	access StateAccess,
 	key common.Hash,
 	sender common.Address,
@@ -32,7 +39,9 @@ func Store(
 		return fmt.Errorf("failed to add entity to owner entities: %w", err)
 	}
 
+	endTrace := storageutil.TraceIndex(access, "metadata", key)
 	err = StoreEntityMetaData(access, key, emd)
+	endTrace()
 	if err != nil {
 		return fmt.Errorf("failed to store entity meta data: %w", err)
 	}
@@ -43,28 +52,109 @@ func Store(
 	}
 
 	for _, stringAnnotation := range emd.StringAnnotations {
-		err = keyset.AddValue(
-			access,
-			annotationindex.StringAnnotationIndexKey(stringAnnotation.Key, stringAnnotation.Value),
-			key,
-		)
+		setKey := annotationindex.StringAnnotationIndexKey(stringAnnotation.Key, stringAnnotation.Value)
+		endTrace := storageutil.TraceIndex(access, "stringAnnotation:"+stringAnnotation.Key+"="+stringAnnotation.Value, setKey)
+		err = keyset.AddValue(access, setKey, key)
+		endTrace()
 		if err != nil {
 			return fmt.Errorf("failed to append to key list: %w", err)
 		}
 	}
 
 	for _, numericAnnotation := range emd.NumericAnnotations {
-		err = keyset.AddValue(
-			access,
-			annotationindex.NumericAnnotationIndexKey(numericAnnotation.Key, numericAnnotation.Value),
-			key,
-		)
+		setKey := annotationindex.NumericAnnotationIndexKey(numericAnnotation.Key, numericAnnotation.Value)
+		endTrace := storageutil.TraceIndex(access, "numericAnnotation:"+numericAnnotation.Key+"="+strconv.FormatUint(numericAnnotation.Value, 10), setKey)
+		err = keyset.AddValue(access, setKey, key)
+		endTrace()
 		if err != nil {
 			return fmt.Errorf("failed to append to key list: %w", err)
 		}
 	}
 
+	for _, tagAnnotation := range emd.TagAnnotations {
+		for _, value := range tagAnnotation.Values {
+			setKey := annotationindex.StringAnnotationIndexKey(tagAnnotation.Key, value)
+			endTrace := storageutil.TraceIndex(access, "tagAnnotation:"+tagAnnotation.Key+"="+value, setKey)
+			err = keyset.AddValue(access, setKey, key)
+			endTrace()
+			if err != nil {
+				return fmt.Errorf("failed to append to key list: %w", err)
+			}
+		}
+	}
+
+	endTrace = storageutil.TraceIndex(access, "payload", key)
 	StorePayload(access, key, payload)
+	endTrace()
+
+	if rules.IsKaolin {
+		err = addToKaolinIndexes(access, key, emd, payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addToKaolinIndexes adds the entity to the annotation key index and to the usage counters of its owner.
+// While the migration to Kaolin is in progress, the entity is marked as added, see MigrateToKaolin.
+func addToKaolinIndexes(access StateAccess, key common.Hash, emd EntityMetaData, payload []byte) error {
+	for _, annotationKey := range annotationKeys(emd) {
+		setKey := annotationindex.AnnotationKeyIndexKey(annotationKey)
+		endTrace := storageutil.TraceIndex(access, "annotationKey:"+annotationKey, setKey)
+		err := keyset.AddValue(access, setKey, key)
+		endTrace()
+		if err != nil {
+			return fmt.Errorf("failed to append to key list: %w", err)
+		}
+	}
+
+	endTrace := storageutil.TraceIndex(access, "ownerUsage", common.BytesToHash(emd.Owner.Bytes()))
+	ownerusage.Add(access, emd.Owner, UsageOf(emd, uint64(len(payload))))
+	endTrace()
+
+	setKaolinIndexed(access, key)
 
 	return nil
 }
+
+// UsageOf returns the storage an entity with payloadBytes bytes of payload accounts for
+// in the usage counters of its owner.
+func UsageOf(emd EntityMetaData, payloadBytes uint64) ownerusage.Usage {
+	return ownerusage.Usage{
+		Entities:     1,
+		PayloadBytes: payloadBytes,
+		Annotations:  uint64(len(emd.StringAnnotations) + len(emd.NumericAnnotations) + tagValueCount(emd)),
+	}
+}
+
+func tagValueCount(emd EntityMetaData) int {
+	count := 0
+	for _, tagAnnotation := range emd.TagAnnotations {
+		count += len(tagAnnotation.Values)
+	}
+	return count
+}
+
+// annotationKeys returns the distinct keys of all annotations of the entity.
+func annotationKeys(emd EntityMetaData) []string {
+	keys := []string{}
+	add := func(key string) {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, a := range emd.StringAnnotations {
+		add(a.Key)
+	}
+	for _, a := range emd.NumericAnnotations {
+		add(a.Key)
+	}
+	for _, a := range emd.TagAnnotations {
+		add(a.Key)
+	}
+
+	return keys
+}
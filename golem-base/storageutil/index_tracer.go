@@ -0,0 +1,22 @@
+package storageutil
+
+import (
+	"github.com/jeffcogswell/golembase-op-geth/common"
+)
+
+// IndexTracer is implemented by state accesses that attribute the slots they touch to indexes.
+type IndexTracer interface {
+	EnterIndex(index string, setKey common.Hash)
+	ExitIndex()
+}
+
+// TraceIndex marks the start of an update of the index if access is an IndexTracer.
+// The returned function marks the end of the update.
+func TraceIndex(access StateAccess, index string, setKey common.Hash) func() {
+	tracer, ok := access.(IndexTracer)
+	if !ok {
+		return func() {}
+	}
+	tracer.EnterIndex(index, setKey)
+	return tracer.ExitIndex
+}
@@ -147,6 +147,14 @@ func Size(db StateAccess, setKey common.Hash) *uint256.Int {
 	return new(uint256.Int).SetBytes(lenValue[:])
 }
 
+// At returns the value at the position index of the set, counting from zero.
+// Removing a value moves the last value of the set to the position of the removed one.
+func At(db StateAccess, setKey common.Hash, index uint64) common.Hash {
+	elementAddress := new(uint256.Int).SetBytes32(setKey[:])
+	elementAddress.AddUint64(elementAddress, index+1)
+	return db.GetState(storageutil.GolemDBAddress, elementAddress.Bytes32())
+}
+
 // Clear removes all elements from the set.
 // It iterates through all values in the set and clears their mappings,
 // then resets the set's size to zero.
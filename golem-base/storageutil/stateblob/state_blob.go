@@ -98,10 +98,11 @@ func GetBlob(db StateAccess, key common.Hash) []byte {
 
 var emptyHash = common.Hash{}
 
-func DeleteBlob(db StateAccess, key common.Hash) {
+// DeleteBlob deletes the blob and returns its length.
+func DeleteBlob(db StateAccess, key common.Hash) uint64 {
 	head := db.GetState(GolemDBAddress, key)
 	if head == emptyHash {
-		return
+		return 0
 	}
 
 	// Clear the head slot
@@ -109,7 +110,7 @@ func DeleteBlob(db StateAccess, key common.Hash) {
 
 	// For small payloads (≤31 bytes), we only need to clear the head slot
 	if head[31]&0x01 == 0 {
-		return
+		return uint64(head[31] / 2)
 	}
 
 	// For large payloads
@@ -125,4 +126,6 @@ func DeleteBlob(db StateAccess, key common.Hash) {
 		db.SetState(GolemDBAddress, keyInt.Bytes32(), emptyHash)
 		keyInt.AddUint64(keyInt, 1)
 	}
+
+	return dataLength
 }
@@ -0,0 +1,44 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+// DepositStorageTransaction sends the storage transaction from the funded account as
+// an L1 deposit and mines the block that includes it.
+func (w *World) DepositStorageTransaction(
+	ctx context.Context,
+	storageTx *storagetx.StorageTransaction,
+) (*types.Receipt, error) {
+
+	// RLP encode the storage transaction
+	rlpData, err := rlp.EncodeToBytes(storageTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode storage transaction: %w", err)
+	}
+
+	// deposits are not sent to the transaction pool, the block has to be mined explicitly
+	node := w.GethInstance.Node
+	txHash := node.Deposit(w.FundedAccount.Address, address.GolemBaseStorageProcessorAddress, 2_800_000, rlpData)
+	node.Commit()
+
+	receipt, err := w.GethInstance.ETHClient.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt of deposit %s: %w", txHash.Hex(), err)
+	}
+
+	if receipt.Status == types.ReceiptStatusFailed {
+		return nil, fmt.Errorf("deposit failed")
+	}
+
+	w.LastReceipt = receipt
+
+	return receipt, nil
+
+}
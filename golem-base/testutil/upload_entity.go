@@ -0,0 +1,117 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/jeffcogswell/golembase-op-geth/accounts/abi/bind"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+// UploadEntity creates an entity by uploading its payload in chunks of chunkSize bytes,
+// each in its own transaction, between the transactions that begin and finalize the upload.
+func (w *World) UploadEntity(ctx context.Context, ttl uint64, payload []byte, chunkSize int) (*types.Receipt, error) {
+
+	receipt, err := w.sendStorageTransaction(ctx, &storagetx.StorageTransaction{
+		BeginUpload: []storagetx.BeginUpload{
+			{
+				TTL:         ttl,
+				Size:        uint64(len(payload)),
+				PayloadHash: crypto.Keccak256Hash(payload),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin upload: %w", err)
+	}
+
+	key := receipt.Logs[0].Topics[1]
+
+	for offset := 0; offset < len(payload); offset += chunkSize {
+		end := min(offset+chunkSize, len(payload))
+		_, err = w.sendStorageTransaction(ctx, &storagetx.StorageTransaction{
+			AppendChunk: []storagetx.AppendChunk{
+				{
+					UploadKey: key,
+					Offset:    uint64(offset),
+					Data:      payload[offset:end],
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to append chunk at offset %d: %w", offset, err)
+		}
+	}
+
+	receipt, err = w.sendStorageTransaction(ctx, &storagetx.StorageTransaction{
+		FinalizeUpload: []common.Hash{key},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize upload: %w", err)
+	}
+
+	w.LastReceipt = receipt
+
+	w.CreatedEntityKey = key
+
+	return receipt, nil
+}
+
+func (w *World) sendStorageTransaction(ctx context.Context, storageTx *storagetx.StorageTransaction) (*types.Receipt, error) {
+
+	client := w.GethInstance.ETHClient
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	nonce, err := client.PendingNonceAt(ctx, w.FundedAccount.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	rlpData, err := rlp.EncodeToBytes(storageTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode storage transaction: %w", err)
+	}
+
+	txdata := &types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      nonce,
+		GasTipCap:  big.NewInt(1e9), // 1 Gwei
+		GasFeeCap:  big.NewInt(5e9), // 5 Gwei
+		Gas:        2_800_000,
+		To:         &address.GolemBaseStorageProcessorAddress,
+		Value:      big.NewInt(0), // No ETH transfer needed
+		Data:       rlpData,
+		AccessList: types.AccessList{},
+	}
+
+	signedTx, err := types.SignNewTx(w.FundedAccount.PrivateKey, types.LatestSignerForChainID(chainID), txdata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	err = client.SendTransaction(ctx, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	receipt, err := bind.WaitMined(ctx, client, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for transaction: %w", err)
+	}
+
+	if receipt.Status == types.ReceiptStatusFailed {
+		return nil, fmt.Errorf("transaction failed")
+	}
+
+	return receipt, nil
+}
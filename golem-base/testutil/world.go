@@ -6,7 +6,9 @@ import (
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/entityproof"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/golemtype"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/history"
 )
 
 // World is the test world - it holds all the state that is shared between steps
@@ -17,17 +19,23 @@ type World struct {
 	SearchResult     []golemtype.SearchResult
 	CreatedEntityKey common.Hash
 	LastError        error
+	EntityHistory    []history.Revision
+	ExpiringEntities []golemtype.ExpiringEntity
+	ExpiringPages    int
+	EntityProof      *entityproof.EntityProof
 }
 
-func NewWorld(ctx context.Context, gethPath string) (*World, error) {
-	geth, err := startGethInstance(ctx, gethPath)
+func NewWorld(ctx context.Context) (*World, error) {
+	acc, err := newFundedAccount()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start geth instance: %w", err)
+		return nil, fmt.Errorf("failed to create funded account: %w", err)
 	}
 
-	acc, err := geth.createAccountAndTransferFunds(ctx, EthToWei(100))
+	geth, err := startGethInstance(types.GenesisAlloc{
+		acc.Address: {Balance: EthToWei(100)},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create account and transfer funds: %w", err)
+		return nil, fmt.Errorf("failed to start geth instance: %w", err)
 	}
 
 	return &World{
@@ -38,13 +46,11 @@ func NewWorld(ctx context.Context, gethPath string) (*World, error) {
 }
 
 func (w *World) Shutdown() {
-	w.GethInstance.shutdown()
+	w.GethInstance.Node.Close()
 }
 
+// AddLogsToTestError is kept for the worlds embedding World that add the logs of
+// their processes. The node runs in-process and logs to the default logger.
 func (w *World) AddLogsToTestError(err error) error {
-	if err == nil {
-		return nil
-	}
-
-	return fmt.Errorf("%w\n\nGeth Logs:\n%s", err, w.GethInstance.output.String())
+	return err
 }
@@ -0,0 +1,329 @@
+package wal
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/housekeepingtx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/pendingupload"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/holiman/uint256"
+)
+
+// ForEachOperation decodes the storage operations that were successfully applied in the block
+// and calls fn for each of them, in the order they were applied.
+// The hash of the transaction that caused the operation is passed along with the operation.
+// The deletions of expired entities by housekeeping come first and are passed with the hash
+// types.GolemBaseHousekeepingTxHash of the block. Before the Kaolin upgrade, housekeeping runs in
+// deposit transactions and its deletions are passed with the hash of the deposit transaction,
+// before the operations of the transaction itself, see housekeepingtx.SplitLogs.
+// Deposit transactions from L1 are decoded like any other transaction, their sender is the L1 sender.
+// The payload of a finalized upload is put together from the chunks appended in the block and,
+// if the upload was begun in an earlier block, the chunks found in parentState.
+func ForEachOperation(block *types.Block, chainID *big.Int, receipts []*types.Receipt, parentState ParentState, fn func(txHash common.Hash, op Operation) error) error {
+
+	txns := block.Transactions()
+
+	signer := types.LatestSignerForChainID(chainID)
+
+	uploads := &uploadPayloads{parentState: parentState, payloads: map[common.Hash][]byte{}}
+
+	expirations := func(txHash common.Hash, logs []*types.Log) error {
+		for _, l := range logs {
+			if len(l.Topics) != 2 || l.Topics[0] != storagetx.GolemBaseStorageEntityDeleted {
+				continue
+			}
+
+			key := l.Topics[1]
+
+			err := fn(txHash, Operation{
+				Delete: &key,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if housekeeping := types.GolemBaseHousekeepingReceipt(txns, receipts); housekeeping != nil {
+		err := expirations(types.GolemBaseHousekeepingTxHash(block.NumberU64()), housekeeping.Logs)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, tx := range txns {
+		receipt := receipts[i]
+
+		housekeeping, ownLogs := housekeepingtx.SplitLogs(tx, receipt)
+
+		err := expirations(tx.Hash(), housekeeping)
+		if err != nil {
+			return err
+		}
+
+		if receipt.Status == types.ReceiptStatusFailed {
+			continue
+		}
+
+		toAddr := common.Address{}
+		if tx.To() != nil {
+			toAddr = *tx.To()
+		}
+
+		switch {
+		case toAddr == address.GolemBaseStorageProcessorAddress:
+
+			stx := storagetx.StorageTransaction{}
+			err := rlp.DecodeBytes(tx.Data(), &stx)
+			if err != nil {
+				return fmt.Errorf("failed to decode storage transaction: %w", err)
+			}
+
+			createdLogs := []*types.Log{}
+			updatedLogs := []*types.Log{}
+			extendedLogs := []*types.Log{}
+			finalizedLogs := []*types.Log{}
+
+			for _, log := range ownLogs {
+				if len(log.Topics) < 2 {
+					continue
+				}
+
+				if log.Topics[0] == storagetx.GolemBaseStorageEntityCreated {
+					createdLogs = append(createdLogs, log)
+				}
+
+				if log.Topics[0] == storagetx.GolemBaseStorageEntityUpdated {
+					updatedLogs = append(updatedLogs, log)
+				}
+
+				if log.Topics[0] == storagetx.GolemBaseStorageEntityTTLExtended {
+					extendedLogs = append(extendedLogs, log)
+				}
+
+				if log.Topics[0] == storagetx.GolemBaseStorageUploadBegun {
+					uploads.begin(log.Topics[1])
+				}
+
+				if log.Topics[0] == storagetx.GolemBaseStorageUploadFinalized {
+					finalizedLogs = append(finalizedLogs, log)
+				}
+
+			}
+
+			for i, create := range stx.Create {
+
+				l := createdLogs[i]
+				key := l.Topics[1]
+				expiresAtBlockU256 := uint256.NewInt(0).SetBytes(l.Data)
+				expiresAtBlock := expiresAtBlockU256.Uint64()
+
+				from, err := types.Sender(signer, tx)
+				if err != nil {
+					return fmt.Errorf("failed to get sender of create transaction %s: %w", tx.Hash().Hex(), err)
+				}
+
+				cr := Create{
+					EntityKey:          key,
+					ExpiresAtBlock:     expiresAtBlock,
+					Payload:            create.Payload,
+					StringAnnotations:  create.StringAnnotations,
+					NumericAnnotations: create.NumericAnnotations,
+					TagAnnotations:     create.TagAnnotations,
+					Owner:              from,
+					Namespace:          create.Namespace,
+					Name:               create.Name,
+					ContentMetaData:    create.ContentMetaData,
+				}
+
+				err = fn(tx.Hash(), Operation{
+					Create: &cr,
+				})
+				if err != nil {
+					return err
+				}
+
+			}
+
+			for _, del := range stx.Delete {
+				err := fn(tx.Hash(), Operation{
+					Delete: &del,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			for i, update := range stx.Update {
+
+				log := updatedLogs[i]
+				key := log.Topics[1]
+				expiresAtBlockU256 := uint256.NewInt(0).SetBytes(log.Data)
+				expiresAtBlock := expiresAtBlockU256.Uint64()
+
+				ur := Update{
+					EntityKey:          key,
+					ExpiresAtBlock:     expiresAtBlock,
+					Payload:            update.Payload,
+					StringAnnotations:  update.StringAnnotations,
+					NumericAnnotations: update.NumericAnnotations,
+					TagAnnotations:     update.TagAnnotations,
+					ContentMetaData:    update.ContentMetaData,
+				}
+
+				err := fn(tx.Hash(), Operation{
+					Update: &ur,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			for i, extend := range stx.Extend {
+
+				log := extendedLogs[i]
+
+				oldExpiresAtU256 := uint256.NewInt(0).SetBytes(log.Data[:32])
+				oldExpiresAt := oldExpiresAtU256.Uint64()
+
+				newExpiresAtU256 := uint256.NewInt(0).SetBytes(log.Data[32:])
+				newExpiresAt := newExpiresAtU256.Uint64()
+
+				ex := ExtendTTL{
+					EntityKey:    extend.EntityKey,
+					OldExpiresAt: oldExpiresAt,
+					NewExpiresAt: newExpiresAt,
+				}
+
+				err := fn(tx.Hash(), Operation{
+					Extend: &ex,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, chunk := range stx.AppendChunk {
+				err := uploads.append(chunk.UploadKey, chunk.Data)
+				if err != nil {
+					return err
+				}
+			}
+
+			// finalized uploads create entities after all other operations,
+			// their created logs follow the ones of the create operations
+			for i, l := range finalizedLogs {
+
+				create := storagetx.Create{}
+				err := rlp.DecodeBytes(l.Data, &create)
+				if err != nil {
+					return fmt.Errorf("failed to decode finalized upload %s: %w", l.Topics[1].Hex(), err)
+				}
+
+				payload, err := uploads.finalize(l.Topics[1], create.PayloadHash)
+				if err != nil {
+					return err
+				}
+
+				expiresAtBlockU256 := uint256.NewInt(0).SetBytes(createdLogs[len(stx.Create)+i].Data)
+
+				from, err := types.Sender(signer, tx)
+				if err != nil {
+					return fmt.Errorf("failed to get sender of finalize transaction %s: %w", tx.Hash().Hex(), err)
+				}
+
+				cr := Create{
+					EntityKey:          l.Topics[1],
+					ExpiresAtBlock:     expiresAtBlockU256.Uint64(),
+					Payload:            payload,
+					StringAnnotations:  create.StringAnnotations,
+					NumericAnnotations: create.NumericAnnotations,
+					TagAnnotations:     create.TagAnnotations,
+					Owner:              from,
+					Namespace:          create.Namespace,
+					Name:               create.Name,
+					ContentMetaData:    create.ContentMetaData,
+				}
+
+				err = fn(tx.Hash(), Operation{
+					Create: &cr,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+		default:
+		}
+
+	}
+
+	return nil
+}
+
+// uploadPayloads keeps the payloads of the uploads that chunks were appended to in a block.
+type uploadPayloads struct {
+	parentState ParentState
+	parent      storageutil.StateAccess
+	payloads    map[common.Hash][]byte
+}
+
+func (u *uploadPayloads) begin(key common.Hash) {
+	u.payloads[key] = []byte{}
+}
+
+// payload returns the payload received so far, reading it from the parent state
+// if the upload was begun in an earlier block.
+func (u *uploadPayloads) payload(key common.Hash) ([]byte, error) {
+	if payload, ok := u.payloads[key]; ok {
+		return payload, nil
+	}
+
+	if u.parent == nil {
+		if u.parentState == nil {
+			return nil, fmt.Errorf("the parent state is needed to read the chunks of upload %s", key.Hex())
+		}
+		parent, err := u.parentState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the parent state to read the chunks of upload %s: %w", key.Hex(), err)
+		}
+		u.parent = parent
+	}
+
+	upload, err := pendingupload.Get(u.parent, key)
+	if err != nil {
+		return nil, err
+	}
+	return pendingupload.Payload(u.parent, key, upload), nil
+}
+
+func (u *uploadPayloads) append(key common.Hash, data []byte) error {
+	payload, err := u.payload(key)
+	if err != nil {
+		return err
+	}
+	u.payloads[key] = append(payload, data...)
+	return nil
+}
+
+// finalize returns the whole payload of the upload and checks it against the hash of the finalized entity.
+func (u *uploadPayloads) finalize(key common.Hash, payloadHash common.Hash) ([]byte, error) {
+	payload, err := u.payload(key)
+	if err != nil {
+		return nil, err
+	}
+	delete(u.payloads, key)
+
+	if crypto.Keccak256Hash(payload) != payloadHash {
+		return nil, fmt.Errorf("payload of finalized upload %s does not match its hash %s", key.Hex(), payloadHash.Hex())
+	}
+	return payload, nil
+}
@@ -0,0 +1,88 @@
+package wal_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/ownerusage"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/wal"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachOperationReadsUploadedPayload(t *testing.T) {
+	chainID := big.NewInt(1337)
+	kaolin := params.GolemBaseRules{IsKaolin: true}
+
+	key, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+
+	db, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	payload := bytes.Repeat([]byte("0123456789"), 10)
+
+	begin := &storagetx.StorageTransaction{BeginUpload: []storagetx.BeginUpload{{
+		TTL:         100,
+		Size:        uint64(len(payload)),
+		PayloadHash: crypto.Keccak256Hash(payload),
+	}}}
+	logs, err := begin.Run(kaolin, 1, common.HexToHash("0x1"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+	uploadKey := logs[0].Topics[1]
+
+	first := &storagetx.StorageTransaction{AppendChunk: []storagetx.AppendChunk{{UploadKey: uploadKey, Offset: 0, Data: payload[:40]}}}
+	_, err = first.Run(kaolin, 2, common.HexToHash("0x2"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+
+	parent := db.Copy()
+
+	// the block appends the rest of the payload and finalizes the upload
+	stx := &storagetx.StorageTransaction{
+		AppendChunk:    []storagetx.AppendChunk{{UploadKey: uploadKey, Offset: 40, Data: payload[40:]}},
+		FinalizeUpload: []common.Hash{uploadKey},
+	}
+	logs, err = stx.Run(kaolin, 3, common.HexToHash("0x3"), owner, db, ownerusage.Quota{})
+	require.NoError(t, err)
+
+	data, err := rlp.EncodeToBytes(stx)
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Gas:       1_000_000,
+		GasFeeCap: big.NewInt(1),
+		To:        &address.GolemBaseStorageProcessorAddress,
+		Data:      data,
+	})
+	require.NoError(t, err)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3)}).WithBody(types.Body{Transactions: []*types.Transaction{tx}})
+	receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, Logs: logs}}
+
+	creates := []*wal.Create{}
+	err = wal.ForEachOperation(block, chainID, receipts, func() (storageutil.StateAccess, error) { return parent, nil }, func(txHash common.Hash, op wal.Operation) error {
+		if op.Create != nil {
+			creates = append(creates, op.Create)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, creates, 1)
+	require.Equal(t, uploadKey, creates[0].EntityKey)
+	require.Equal(t, owner, creates[0].Owner)
+	require.Equal(t, uint64(103), creates[0].ExpiresAtBlock)
+	require.Equal(t, payload, creates[0].Payload)
+
+	// the chunks appended before the block can only be read from the parent state
+	err = wal.ForEachOperation(block, chainID, receipts, nil, func(txHash common.Hash, op wal.Operation) error { return nil })
+	require.ErrorContains(t, err, "parent state is needed")
+}
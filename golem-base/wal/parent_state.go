@@ -0,0 +1,31 @@
+package wal
+
+import (
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil"
+)
+
+// ParentState opens the state a block was applied to. It is only called for blocks that
+// finalize uploads begun in an earlier block, to read the chunks appended before the block.
+type ParentState func() (storageutil.StateAccess, error)
+
+// Chain is the part of the block chain needed to open the state of a block.
+type Chain interface {
+	GetHeaderByHash(hash common.Hash) *types.Header
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// ParentStateOf returns the ParentState of the block in the chain.
+func ParentStateOf(chain Chain, block *types.Block) ParentState {
+	return func() (storageutil.StateAccess, error) {
+		parent := chain.GetHeaderByHash(block.ParentHash())
+		if parent == nil {
+			return nil, fmt.Errorf("parent of block %d not found", block.NumberU64())
+		}
+		return chain.StateAt(parent.Root)
+	}
+}
@@ -4,22 +4,38 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/core/types"
-	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
-	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
 	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
 	"github.com/jeffcogswell/golembase-op-geth/log"
-	"github.com/jeffcogswell/golembase-op-geth/rlp"
-	"github.com/holiman/uint256"
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
 )
 
+var (
+	walWriteTimer   = metrics.NewRegisteredTimer("golembase/wal/write", nil)
+	walBytesCounter = metrics.NewRegisteredCounter("golembase/wal/bytes", nil)
+)
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
 type BlockInfo struct {
 	Number     uint64      `json:"number,string"`
 	Hash       common.Hash `json:"hash"`
@@ -39,7 +55,11 @@ type Create struct {
 	Payload            []byte                     `json:"payload"`
 	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
 	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations,omitempty"`
 	Owner              common.Address             `json:"owner"`
+	Namespace          string                     `json:"namespace,omitempty"`
+	Name               string                     `json:"name,omitempty"`
+	entity.ContentMetaData
 }
 
 type Update struct {
@@ -48,6 +68,8 @@ type Update struct {
 	Payload            []byte                     `json:"payload"`
 	StringAnnotations  []entity.StringAnnotation  `json:"stringAnnotations"`
 	NumericAnnotations []entity.NumericAnnotation `json:"numericAnnotations"`
+	TagAnnotations     []entity.TagAnnotation     `json:"tagAnnotations,omitempty"`
+	entity.ContentMetaData
 }
 
 type ExtendTTL struct {
@@ -76,12 +98,16 @@ func PathToBlockNumber(path string) (uint64, error) {
 	return strconv.ParseUint(matches[1], 10, 64)
 }
 
-func WriteLogForBlock(dir string, block *types.Block, chainID *big.Int, receipts []*types.Receipt) (err error) {
+func WriteLogForBlock(dir string, block *types.Block, chainID *big.Int, receipts []*types.Receipt, parentState ParentState) (err error) {
+
+	start := time.Now()
 
 	defer func() {
 		if err != nil {
 			log.Error("failed to write log for block", "block", block.NumberU64(), "error", err)
+			return
 		}
+		walWriteTimer.UpdateSince(start)
 	}()
 
 	tempFilename := BlockNumberToFilename(block.NumberU64()) + ".temp"
@@ -96,7 +122,8 @@ func WriteLogForBlock(dir string, block *types.Block, chainID *big.Int, receipts
 		os.Remove(filepath.Join(dir, tempFilename))
 	}()
 
-	enc := json.NewEncoder(tf)
+	cw := &countingWriter{w: tf}
+	enc := json.NewEncoder(cw)
 
 	enc.Encode(BlockInfo{
 		Number:     block.NumberU64(),
@@ -104,163 +131,11 @@ func WriteLogForBlock(dir string, block *types.Block, chainID *big.Int, receipts
 		ParentHash: block.ParentHash(),
 	})
 
-	txns := block.Transactions()
-
-	signer := types.LatestSignerForChainID(chainID)
-
-	for i, tx := range txns {
-		receipt := receipts[i]
-		if receipt.Status == types.ReceiptStatusFailed {
-			continue
-		}
-
-		toAddr := common.Address{}
-		if tx.To() != nil {
-			toAddr = *tx.To()
-		}
-
-		switch {
-		case tx.Type() == types.DepositTxType:
-			for _, l := range receipt.Logs {
-				if len(l.Topics) != 2 {
-					continue
-				}
-
-				if l.Topics[0] != storagetx.GolemBaseStorageEntityDeleted {
-					continue
-				}
-
-				key := l.Topics[1]
-
-				err := enc.Encode(Operation{
-					Delete: &key,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to encode delete operation: %w", err)
-				}
-
-			}
-			// create
-		case toAddr == address.GolemBaseStorageProcessorAddress:
-
-			stx := storagetx.StorageTransaction{}
-			err := rlp.DecodeBytes(tx.Data(), &stx)
-			if err != nil {
-				return fmt.Errorf("failed to decode storage transaction: %w", err)
-			}
-
-			createdLogs := []*types.Log{}
-			updatedLogs := []*types.Log{}
-			extendedLogs := []*types.Log{}
-
-			for _, log := range receipt.Logs {
-				if len(log.Topics) < 2 {
-					continue
-				}
-
-				if log.Topics[0] == storagetx.GolemBaseStorageEntityCreated {
-					createdLogs = append(createdLogs, log)
-				}
-
-				if log.Topics[0] == storagetx.GolemBaseStorageEntityUpdated {
-					updatedLogs = append(updatedLogs, log)
-				}
-
-				if log.Topics[0] == storagetx.GolemBaseStorageEntityTTLExtended {
-					extendedLogs = append(extendedLogs, log)
-				}
-
-			}
-
-			for i, create := range stx.Create {
-
-				l := createdLogs[i]
-				key := l.Topics[1]
-				expiresAtBlockU256 := uint256.NewInt(0).SetBytes(l.Data)
-				expiresAtBlock := expiresAtBlockU256.Uint64()
-
-				from, err := types.Sender(signer, tx)
-				if err != nil {
-					return fmt.Errorf("failed to get sender of create transaction %s: %w", tx.Hash().Hex(), err)
-				}
-
-				cr := Create{
-					EntityKey:          key,
-					ExpiresAtBlock:     expiresAtBlock,
-					Payload:            create.Payload,
-					StringAnnotations:  create.StringAnnotations,
-					NumericAnnotations: create.NumericAnnotations,
-					Owner:              from,
-				}
-
-				err = enc.Encode(Operation{
-					Create: &cr,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to encode create operation: %w", err)
-				}
-
-			}
-
-			for _, del := range stx.Delete {
-				err := enc.Encode(Operation{
-					Delete: &del,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to encode delete operation: %w", err)
-				}
-			}
-
-			for i, update := range stx.Update {
-
-				log := updatedLogs[i]
-				key := log.Topics[1]
-				expiresAtBlockU256 := uint256.NewInt(0).SetBytes(log.Data)
-				expiresAtBlock := expiresAtBlockU256.Uint64()
-
-				ur := Update{
-					EntityKey:          key,
-					ExpiresAtBlock:     expiresAtBlock,
-					Payload:            update.Payload,
-					StringAnnotations:  update.StringAnnotations,
-					NumericAnnotations: update.NumericAnnotations,
-				}
-
-				err := enc.Encode(Operation{
-					Update: &ur,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to encode update operation: %w", err)
-				}
-			}
-
-			for i, extend := range stx.Extend {
-
-				log := extendedLogs[i]
-
-				oldExpiresAtU256 := uint256.NewInt(0).SetBytes(log.Data[:32])
-				oldExpiresAt := oldExpiresAtU256.Uint64()
-
-				newExpiresAtU256 := uint256.NewInt(0).SetBytes(log.Data[32:])
-				newExpiresAt := newExpiresAtU256.Uint64()
-
-				ex := ExtendTTL{
-					EntityKey:    extend.EntityKey,
-					OldExpiresAt: oldExpiresAt,
-					NewExpiresAt: newExpiresAt,
-				}
-
-				err := enc.Encode(Operation{
-					Extend: &ex,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to encode extend operation: %w", err)
-				}
-			}
-
-		default:
-		}
-
+	err = ForEachOperation(block, chainID, receipts, parentState, func(txHash common.Hash, op Operation) error {
+		return enc.Encode(op)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write operations: %w", err)
 	}
 
 	err = tf.Close()
@@ -273,5 +148,7 @@ func WriteLogForBlock(dir string, block *types.Block, chainID *big.Int, receipts
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
 
+	walBytesCounter.Inc(cw.n)
+
 	return nil
 }
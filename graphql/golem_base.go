@@ -0,0 +1,394 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/holiman/uint256"
+	"github.com/jeffcogswell/golembase-op-geth/common"
+	"github.com/jeffcogswell/golembase-op-geth/common/hexutil"
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/housekeepingtx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/query"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity/allentities"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+)
+
+var errCursorNotFound = errors.New("entity given as after is not in the result")
+
+// golemBaseBackend is implemented by backends that limit the cost of Golem Base queries.
+type golemBaseBackend interface {
+	GolemBaseQueryLimits() query.Limits
+}
+
+// Entity represents a Golem Base entity at a particular block.
+// r, key, state and header are mandatory; the metadata is fetched when required.
+type Entity struct {
+	r      *Resolver
+	key    common.Hash
+	state  *state.StateDB
+	header *types.Header
+	mu     sync.Mutex
+	// mu protects following resources
+	metaData *entity.EntityMetaData
+}
+
+// resolveMetaData returns the metadata of the entity, fetching it if needed.
+func (e *Entity) resolveMetaData() (*entity.EntityMetaData, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.metaData != nil {
+		return e.metaData, nil
+	}
+	md, err := entity.GetEntityMetaData(e.state, e.key)
+	if err != nil {
+		return nil, err
+	}
+	e.metaData = md
+	return md, nil
+}
+
+func (e *Entity) Key(ctx context.Context) common.Hash {
+	return e.key
+}
+
+func (e *Entity) Owner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	md, err := e.resolveMetaData()
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		r:             e.r,
+		address:       md.Owner,
+		blockNrOrHash: args.NumberOr(rpc.BlockNumberOrHashWithHash(e.header.Hash(), false)),
+	}, nil
+}
+
+func (e *Entity) ExpiresAt(ctx context.Context) (hexutil.Uint64, error) {
+	md, err := e.resolveMetaData()
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(md.ExpiresAtBlock), nil
+}
+
+func (e *Entity) StringAnnotations(ctx context.Context) ([]*StringAnnotation, error) {
+	md, err := e.resolveMetaData()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*StringAnnotation, 0, len(md.StringAnnotations))
+	for _, a := range md.StringAnnotations {
+		ret = append(ret, &StringAnnotation{a})
+	}
+	return ret, nil
+}
+
+func (e *Entity) NumericAnnotations(ctx context.Context) ([]*NumericAnnotation, error) {
+	md, err := e.resolveMetaData()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*NumericAnnotation, 0, len(md.NumericAnnotations))
+	for _, a := range md.NumericAnnotations {
+		ret = append(ret, &NumericAnnotation{a})
+	}
+	return ret, nil
+}
+
+func (e *Entity) Payload(ctx context.Context) hexutil.Bytes {
+	return entity.GetPayload(e.state, e.key)
+}
+
+// CreatedIn returns the transaction with the last creation log of the entity up to
+// the entity's block. Named entities can be created again after they are deleted.
+func (e *Entity) CreatedIn(ctx context.Context) (*Transaction, error) {
+	filter := e.r.filterSystem.NewRangeFilter(
+		0,
+		e.header.Number.Int64(),
+		[]common.Address{address.GolemBaseStorageProcessorAddress},
+		[][]common.Hash{{storagetx.GolemBaseStorageEntityCreated}, {e.key}},
+	)
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, nil
+	}
+	return &Transaction{r: e.r, hash: logs[len(logs)-1].TxHash}, nil
+}
+
+// StringAnnotation represents a string annotation of an entity.
+type StringAnnotation struct {
+	a entity.StringAnnotation
+}
+
+func (a *StringAnnotation) Key(ctx context.Context) string {
+	return a.a.Key
+}
+
+func (a *StringAnnotation) Value(ctx context.Context) string {
+	return a.a.Value
+}
+
+// NumericAnnotation represents a numeric annotation of an entity.
+type NumericAnnotation struct {
+	a entity.NumericAnnotation
+}
+
+func (a *NumericAnnotation) Key(ctx context.Context) string {
+	return a.a.Key
+}
+
+func (a *NumericAnnotation) Value(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(a.a.Value)
+}
+
+// StorageOperation represents a Golem Base storage operation, decoded from a log of the storage processor.
+type StorageOperation struct {
+	r           *Resolver
+	transaction *Transaction
+	log         *types.Log
+}
+
+// newStorageOperation returns the storage operation reported by the log, or nil
+// if the log is not a storage processor log.
+func newStorageOperation(r *Resolver, transaction *Transaction, log *types.Log) *StorageOperation {
+	if log.Address != address.GolemBaseStorageProcessorAddress || len(log.Topics) != 2 {
+		return nil
+	}
+	switch log.Topics[0] {
+	case storagetx.GolemBaseStorageEntityCreated,
+		storagetx.GolemBaseStorageEntityUpdated,
+		storagetx.GolemBaseStorageEntityDeleted,
+		storagetx.GolemBaseStorageEntityTTLExtended:
+		return &StorageOperation{r: r, transaction: transaction, log: log}
+	}
+	return nil
+}
+
+func (o *StorageOperation) Type(ctx context.Context) string {
+	switch o.log.Topics[0] {
+	case storagetx.GolemBaseStorageEntityCreated:
+		return "create"
+	case storagetx.GolemBaseStorageEntityUpdated:
+		return "update"
+	case storagetx.GolemBaseStorageEntityDeleted:
+		return "delete"
+	default:
+		return "extend"
+	}
+}
+
+func (o *StorageOperation) Key(ctx context.Context) common.Hash {
+	return o.log.Topics[1]
+}
+
+// ExpiresAt returns the expiration block of the entity after the operation.
+// Extend logs hold the old and the new expiration block, the other logs only the new one.
+func (o *StorageOperation) ExpiresAt(ctx context.Context) *hexutil.Uint64 {
+	if len(o.log.Data) < 32 {
+		return nil
+	}
+	expiresAt := hexutil.Uint64(new(uint256.Int).SetBytes(o.log.Data[len(o.log.Data)-32:]).Uint64())
+	return &expiresAt
+}
+
+func (o *StorageOperation) Transaction(ctx context.Context) *Transaction {
+	return o.transaction
+}
+
+func (o *StorageOperation) Entity(ctx context.Context) (*Entity, error) {
+	return o.r.entityAt(ctx, rpc.BlockNumberOrHashWithHash(o.log.BlockHash, false), o.log.Topics[1])
+}
+
+// storageOperations returns the storage operations reported by the logs.
+func storageOperations(r *Resolver, logs []*types.Log, transaction func(log *types.Log) *Transaction) []*StorageOperation {
+	ret := []*StorageOperation{}
+	for _, log := range logs {
+		if op := newStorageOperation(r, transaction(log), log); op != nil {
+			ret = append(ret, op)
+		}
+	}
+	return ret
+}
+
+func (b *Block) Entity(ctx context.Context, args struct{ Key common.Hash }) (*Entity, error) {
+	return b.r.entityAt(ctx, *b.numberOrHash, args.Key)
+}
+
+// StorageOperations returns the storage operations of the block in the order they were applied.
+// The deletions of expired entities by housekeeping are reported without a transaction,
+// whether their logs are part of the housekeeping receipt of the block or, before Kaolin,
+// of the receipt of a deposit transaction.
+func (b *Block) StorageOperations(ctx context.Context) ([]*StorageOperation, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	receipts, err := b.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	noTransaction := func(*types.Log) *Transaction {
+		return nil
+	}
+	ret := []*StorageOperation{}
+	if housekeeping := types.GolemBaseHousekeepingReceipt(block.Transactions(), receipts); housekeeping != nil {
+		ret = append(ret, storageOperations(b.r, housekeeping.Logs, noTransaction)...)
+	}
+	for i, tx := range block.Transactions() {
+		if i >= len(receipts) {
+			break
+		}
+		housekeeping, ownLogs := housekeepingtx.SplitLogs(tx, receipts[i])
+		ret = append(ret, storageOperations(b.r, housekeeping, noTransaction)...)
+		ret = append(ret, storageOperations(b.r, ownLogs, func(log *types.Log) *Transaction {
+			return &Transaction{r: b.r, hash: log.TxHash}
+		})...)
+	}
+	return ret, nil
+}
+
+// StorageOperations returns the storage operations applied by the transaction, without
+// the deletions of expired entities by housekeeping that are part of the receipts of
+// deposit transactions before Kaolin.
+func (t *Transaction) StorageOperations(ctx context.Context) (*[]*StorageOperation, error) {
+	tx, _ := t.resolve(ctx)
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || tx == nil || receipt == nil {
+		return nil, err
+	}
+	_, ownLogs := housekeepingtx.SplitLogs(tx, receipt)
+	ret := storageOperations(t.r, ownLogs, func(*types.Log) *Transaction {
+		return t
+	})
+	return &ret, nil
+}
+
+// entityBlock returns the block the entity fields of the query are resolved at.
+func entityBlock(block *Long) rpc.BlockNumberOrHash {
+	if block == nil {
+		return rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	}
+	return rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(*block))
+}
+
+// entityState returns the state and header of the block.
+func (r *Resolver) entityState(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	state, header, err := r.backend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if state == nil || header == nil {
+		return nil, nil, fmt.Errorf("state of block %s not found", blockNrOrHash.String())
+	}
+	return state, header, nil
+}
+
+// entityAt returns the entity at the block, or nil if it does not exist or has expired.
+func (r *Resolver) entityAt(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, key common.Hash) (*Entity, error) {
+	state, header, err := r.entityState(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if !allentities.Contains(state, key) || entity.IsExpired(state, key) {
+		return nil, nil
+	}
+	return &Entity{r: r, key: key, state: state, header: header}, nil
+}
+
+// entityPage returns the first entities yielded by keys after the entity with the key after,
+// hiding the expired ones. Keys are only read as far as the page reaches.
+// A nil first or after does not limit the page.
+func (r *Resolver) entityPage(state *state.StateDB, header *types.Header, keys iter.Seq2[common.Hash, error], first *int32, after *common.Hash) ([]*Entity, error) {
+	if first != nil && *first < 0 {
+		return nil, errors.New("first must not be negative")
+	}
+	ret := []*Entity{}
+	if first != nil && *first == 0 {
+		return ret, nil
+	}
+	found := after == nil
+	for key, err := range keys {
+		if err != nil {
+			return nil, err
+		}
+		if entity.IsExpired(state, key) {
+			continue
+		}
+		if !found {
+			found = key == *after
+			continue
+		}
+		ret = append(ret, &Entity{r: r, key: key, state: state, header: header})
+		if first != nil && len(ret) == int(*first) {
+			return ret, nil
+		}
+	}
+	if !found {
+		return nil, errCursorNotFound
+	}
+	return ret, nil
+}
+
+// queryLimits returns the limits of Golem Base queries of the backend.
+func (r *Resolver) queryLimits() query.Limits {
+	if b, ok := r.backend.(golemBaseBackend); ok {
+		return b.GolemBaseQueryLimits()
+	}
+	return query.Limits{}
+}
+
+func (r *Resolver) Entity(ctx context.Context, args struct {
+	Key   common.Hash
+	Block *Long
+}) (*Entity, error) {
+	return r.entityAt(ctx, entityBlock(args.Block), args.Key)
+}
+
+func (r *Resolver) Entities(ctx context.Context, args struct {
+	Query string
+	First *int32
+	After *common.Hash
+	Block *Long
+}) ([]*Entity, error) {
+	expr, err := query.Parse(args.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+	state, header, err := r.entityState(ctx, entityBlock(args.Block))
+	if err != nil {
+		return nil, err
+	}
+	entities, err := r.entityPage(state, header, expr.Iterate(query.NewStateDataSource(state, r.queryLimits())), args.First, args.After)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate query: %w", err)
+	}
+	return entities, nil
+}
+
+func (r *Resolver) EntitiesOfOwner(ctx context.Context, args struct {
+	Address common.Address
+	First   *int32
+	After   *common.Hash
+	Block   *Long
+}) ([]*Entity, error) {
+	state, header, err := r.entityState(ctx, entityBlock(args.Block))
+	if err != nil {
+		return nil, err
+	}
+	entities, err := r.entityPage(state, header, query.NewOwnerSet(state, args.Address, r.queryLimits()).Iterate(), args.First, args.After)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities of owner: %w", err)
+	}
+	return entities, nil
+}
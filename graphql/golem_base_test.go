@@ -0,0 +1,139 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/core"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/crypto"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/address"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storagetx"
+	"github.com/jeffcogswell/golembase-op-geth/golem-base/storageutil/entity"
+	"github.com/jeffcogswell/golembase-op-geth/params"
+	"github.com/jeffcogswell/golembase-op-geth/rlp"
+)
+
+func TestGraphQLEntities(t *testing.T) {
+	config := *params.AllEthashProtocolChanges
+	config.GolemBase = &params.GolemBaseConfig{KaolinTime: new(uint64)}
+
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		genesis = &core.Genesis{
+			Config:     &config,
+			GasLimit:   11500000,
+			Difficulty: big.NewInt(1048576),
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		signer    = types.LatestSigner(genesis.Config)
+		stack     = createNode(t)
+		entityKey = entity.NamedEntityKey(addr, "notes", "first")
+		expiring  = entity.NamedEntityKey(addr, "notes", "expiring")
+		processor = address.GolemBaseStorageProcessorAddress
+	)
+	defer stack.Close()
+
+	data, err := rlp.EncodeToBytes(&storagetx.StorageTransaction{
+		Create: []storagetx.Create{{
+			// expires in block 2 and is deleted by housekeeping
+			TTL:       1,
+			Payload:   []byte("bye"),
+			Namespace: "notes",
+			Name:      "expiring",
+		}, {
+			TTL:                100,
+			Payload:            []byte("hello"),
+			StringAnnotations:  []entity.StringAnnotation{{Key: "type", Value: "note"}},
+			NumericAnnotations: []entity.NumericAnnotation{{Key: "version", Value: 2}},
+			Namespace:          "notes",
+			Name:               "first",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("could not encode storage transaction: %v", err)
+	}
+
+	var tx *types.Transaction
+	// housekeeping runs in block 2 even though it has no transactions
+	handler, _ := newGQLService(t, stack, false, genesis, 2, func(i int, gen *core.BlockGen) {
+		if i == 0 {
+			tx, _ = types.SignNewTx(key, signer, &types.LegacyTx{To: &processor, Gas: 1000000, GasPrice: big.NewInt(params.InitialBaseFee), Data: data})
+			gen.AddTx(tx)
+		}
+	})
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+
+	entityJSON := fmt.Sprintf(`{"key":"%s","owner":{"address":"%s"},"expiresAt":"0x65","stringAnnotations":[{"key":"type","value":"note"}],"numericAnnotations":[{"key":"version","value":"0x2"}],"payload":"0x68656c6c6f","createdIn":{"hash":"%s"}}`, entityKey.Hex(), strings.ToLower(addr.Hex()), tx.Hash().Hex())
+	entityFields := "key owner { address } expiresAt stringAnnotations { key value } numericAnnotations { key value } payload createdIn { hash }"
+
+	for i, tt := range []struct {
+		body string
+		want string
+	}{
+		{
+			body: fmt.Sprintf(`{ entity(key: "%s") { %s } }`, entityKey.Hex(), entityFields),
+			want: fmt.Sprintf(`{"entity":%s}`, entityJSON),
+		},
+		// the entity does not exist before it is created
+		{
+			body: fmt.Sprintf(`{ entity(key: "%s", block: 0) { key } }`, entityKey.Hex()),
+			want: `{"entity":null}`,
+		},
+		{
+			body: fmt.Sprintf(`{ entities(query: "type = \"note\" && version = 2") { %s } }`, entityFields),
+			want: fmt.Sprintf(`{"entities":[%s]}`, entityJSON),
+		},
+		{
+			body: fmt.Sprintf(`{ entities(query: "type = \"note\"", after: "%s") { key } }`, entityKey.Hex()),
+			want: `{"entities":[]}`,
+		},
+		{
+			body: fmt.Sprintf(`{ entitiesOfOwner(address: "%s") { key } }`, addr.Hex()),
+			want: fmt.Sprintf(`{"entitiesOfOwner":[{"key":"%s"}]}`, entityKey.Hex()),
+		},
+		{
+			body: fmt.Sprintf(`{ entitiesOfOwner(address: "%s", first: 1) { key } a: entitiesOfOwner(address: "%s", first: 0) { key } b: entitiesOfOwner(address: "%s", after: "%s") { key } }`, addr.Hex(), addr.Hex(), addr.Hex(), entityKey.Hex()),
+			want: fmt.Sprintf(`{"entitiesOfOwner":[{"key":"%s"}],"a":[],"b":[]}`, entityKey.Hex()),
+		},
+		{
+			body: `{ block(number: 1) { storageOperations { type key expiresAt transaction { hash } entity { payload } } } }`,
+			want: fmt.Sprintf(`{"block":{"storageOperations":[{"type":"create","key":"%s","expiresAt":"0x2","transaction":{"hash":"%s"},"entity":{"payload":"0x627965"}},{"type":"create","key":"%s","expiresAt":"0x65","transaction":{"hash":"%s"},"entity":{"payload":"0x68656c6c6f"}}]}}`, expiring.Hex(), tx.Hash().Hex(), entityKey.Hex(), tx.Hash().Hex()),
+		},
+		{
+			body: fmt.Sprintf(`{ transaction(hash: "%s") { storageOperations { type key } } }`, tx.Hash().Hex()),
+			want: fmt.Sprintf(`{"transaction":{"storageOperations":[{"type":"create","key":"%s"},{"type":"create","key":"%s"}]}}`, expiring.Hex(), entityKey.Hex()),
+		},
+		// the expired entity is deleted by housekeeping, not by a transaction
+		{
+			body: fmt.Sprintf(`{ block(number: 2) { entity(key: "%s") { expiresAt } storageOperations { type key transaction { hash } entity { key } } } }`, entityKey.Hex()),
+			want: fmt.Sprintf(`{"block":{"entity":{"expiresAt":"0x65"},"storageOperations":[{"type":"delete","key":"%s","transaction":null,"entity":null}]}}`, expiring.Hex()),
+		},
+		// the deletion is in the housekeeping receipt of the block
+		{
+			body: `{ block(number: 2) { logs(filter: {}) { index topics transaction { hash } } } }`,
+			want: fmt.Sprintf(`{"block":{"logs":[{"index":"0x0","topics":["%s","%s"],"transaction":{"hash":"%s"}}]}}`, storagetx.GolemBaseStorageEntityDeleted.Hex(), expiring.Hex(), types.GolemBaseHousekeepingTxHash(2).Hex()),
+		},
+	} {
+		res := handler.Schema.Exec(context.Background(), tt.body, "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("failed to execute query for testcase #%d: %v", i, res.Errors)
+		}
+		have, err := json.Marshal(res.Data)
+		if err != nil {
+			t.Fatalf("failed to encode graphql response for testcase #%d: %s", i, err)
+		}
+		if string(have) != tt.want {
+			t.Errorf("response unmatch for testcase #%d.\nExpected:\n%s\nGot:\n%s\n", i, tt.want, have)
+		}
+	}
+}
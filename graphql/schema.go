@@ -170,6 +170,9 @@ const schema string = `
         rawReceipt: Bytes!
         # BlobVersionedHashes is a set of hash outputs from the blobs in the transaction.
         blobVersionedHashes: [Bytes32!]
+        # StorageOperations are the Golem Base storage operations applied by this
+        # transaction. This will be null if the transaction has not yet been mined.
+        storageOperations: [StorageOperation!]
     }
 
     # BlockFilterCriteria encapsulates log filter criteria for a filter applied
@@ -276,6 +279,58 @@ const schema string = `
         blobGasUsed: Long
         # ExcessBlobGas is a running total of blob gas consumed in excess of the target, prior to the block.
         excessBlobGas: Long
+        # Entity fetches a Golem Base entity at the current block's state.
+        entity(key: Bytes32!): Entity
+        # StorageOperations are the Golem Base storage operations applied in this
+        # block, including the deletions of expired entities by housekeeping.
+        storageOperations: [StorageOperation!]!
+    }
+
+    # StringAnnotation is a string annotation of a Golem Base entity.
+    type StringAnnotation {
+        key: String!
+        value: String!
+    }
+
+    # NumericAnnotation is a numeric annotation of a Golem Base entity.
+    type NumericAnnotation {
+        key: String!
+        value: Long!
+    }
+
+    # Entity is a Golem Base entity at a particular block.
+    type Entity {
+        # Key is the key identifying the entity.
+        key: Bytes32!
+        # Owner is the account that created the entity.
+        owner(block: Long): Account!
+        # ExpiresAt is the number of the block at which the entity expires.
+        expiresAt: Long!
+        stringAnnotations: [StringAnnotation!]!
+        numericAnnotations: [NumericAnnotation!]!
+        # Payload is the data stored in the entity.
+        payload: Bytes!
+        # CreatedIn is the transaction that created the entity. It is found by
+        # scanning the logs of the storage processor up to the entity's block.
+        createdIn: Transaction
+    }
+
+    # StorageOperation is a Golem Base storage operation, as reported by the
+    # logs of the storage processor.
+    type StorageOperation {
+        # Type is one of create, update, delete or extend.
+        type: String!
+        # Key is the key of the entity the operation was applied to.
+        key: Bytes32!
+        # ExpiresAt is the number of the block at which the entity expires after
+        # the operation. It is null for deletions.
+        expiresAt: Long
+        # Transaction is the transaction that applied the operation. It is null
+        # for the deletions of expired entities by housekeeping.
+        transaction: Transaction
+        # Entity is the entity at the state of the block containing the operation.
+        # It is null if the entity does not exist at the end of the block.
+        entity: Entity
     }
 
     # CallData represents the data associated with a local contract call.
@@ -382,6 +437,17 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # Entity fetches a Golem Base entity by key. If block is not supplied,
+        # the most recent known block is used.
+        entity(key: Bytes32!, block: Long): Entity
+        # Entities returns the Golem Base entities matching a query, e.g.
+        # type = "note" && version = 2. At most first entities are returned,
+        # starting after the entity with the key after.
+        entities(query: String!, first: Int, after: Bytes32, block: Long): [Entity!]!
+        # EntitiesOfOwner returns the Golem Base entities owned by an address.
+        # At most first entities are returned, starting after the entity with
+        # the key after.
+        entitiesOfOwner(address: Address!, first: Int, after: Bytes32, block: Long): [Entity!]!
     }
 
     type Mutation {
@@ -42,12 +42,14 @@ import (
 	"github.com/jeffcogswell/golembase-op-geth/eth/gasestimator"
 	"github.com/jeffcogswell/golembase-op-geth/eth/tracers/logger"
 	"github.com/jeffcogswell/golembase-op-geth/internal/ethapi/override"
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
 	"github.com/jeffcogswell/golembase-op-geth/log"
 	"github.com/jeffcogswell/golembase-op-geth/p2p"
 	"github.com/jeffcogswell/golembase-op-geth/params"
 	"github.com/jeffcogswell/golembase-op-geth/rlp"
 	"github.com/jeffcogswell/golembase-op-geth/rpc"
 	"github.com/jeffcogswell/golembase-op-geth/trie"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // estimateGasErrorRatio is the amount of overestimation eth_estimateGas is
@@ -342,7 +344,7 @@ func (api *BlockChainAPI) GetBalance(ctx context.Context, address common.Address
 		}
 	}
 
-	state, _, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	state, _, err := stateAndHeaderByNumberOrHash(ctx, api.b, blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
@@ -411,7 +413,7 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 			return nil, err
 		}
 	}
-	statedb, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	statedb, header, err := stateAndHeaderByNumberOrHash(ctx, api.b, blockNrOrHash)
 	if statedb == nil || err != nil {
 		return nil, err
 	}
@@ -621,7 +623,7 @@ func (api *BlockChainAPI) GetCode(ctx context.Context, address common.Address, b
 		}
 	}
 
-	state, _, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	state, _, err := stateAndHeaderByNumberOrHash(ctx, api.b, blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
@@ -652,7 +654,7 @@ func (api *BlockChainAPI) GetStorageAt(ctx context.Context, address common.Addre
 		}
 	}
 
-	state, _, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	state, _, err := stateAndHeaderByNumberOrHash(ctx, api.b, blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
@@ -690,6 +692,10 @@ func (api *BlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rp
 		return nil, err
 	}
 	txs := block.Transactions()
+	// the Golem Base housekeeping receipt has no transaction, its logs are returned by eth_getLogs
+	if types.GolemBaseHousekeepingReceipt(txs, receipts) != nil {
+		receipts = receipts[:len(txs)]
+	}
 	if len(txs) != len(receipts) {
 		return nil, fmt.Errorf("receipts length mismatch: %d vs %d", len(txs), len(receipts))
 	}
@@ -809,22 +815,37 @@ func applyMessageWithEVM(ctx context.Context, evm *vm.EVM, msg *core.Message, ti
 	}()
 
 	// Execute the message.
+	_, span := telemetry.StartSpan(ctx, "evm.applyMessage")
 	result, err := core.ApplyMessage(evm, msg, gp)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int64("evm.gas_limit", int64(msg.GasLimit)))
+		if result != nil {
+			span.SetAttributes(
+				attribute.Int64("evm.gas_used", int64(result.UsedGas)),
+				attribute.Bool("evm.reverted", result.Failed()),
+			)
+		}
+	}
 
 	// If the timer caused an abort, return an appropriate error message
 	if evm.Cancelled() {
-		return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		err := fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		telemetry.EndSpan(span, err)
+		return nil, err
 	}
 	if err != nil {
-		return result, fmt.Errorf("err: %w (supplied gas %d)", err, msg.GasLimit)
+		err = fmt.Errorf("err: %w (supplied gas %d)", err, msg.GasLimit)
+		telemetry.EndSpan(span, err)
+		return result, err
 	}
+	telemetry.EndSpan(span, nil)
 	return result, nil
 }
 
 func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *override.StateOverride, blockOverrides *override.BlockOverrides, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
-	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	state, header, err := stateAndHeaderByNumberOrHash(ctx, b, blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
@@ -889,7 +910,7 @@ func (api *BlockChainAPI) SimulateV1(ctx context.Context, opts simOpts, blockNrO
 		n := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 		blockNrOrHash = &n
 	}
-	state, base, err := api.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	state, base, err := stateAndHeaderByNumberOrHash(ctx, api.b, *blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
@@ -917,7 +938,7 @@ func (api *BlockChainAPI) SimulateV1(ctx context.Context, opts simOpts, blockNrO
 // non-zero) and `gasCap` (if non-zero).
 func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *override.StateOverride, blockOverrides *override.BlockOverrides, gasCap uint64) (hexutil.Uint64, error) {
 	// Retrieve the base state and mutate it with any overrides
-	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	state, header, err := stateAndHeaderByNumberOrHash(ctx, b, blockNrOrHash)
 	if state == nil || err != nil {
 		return 0, err
 	}
@@ -1322,7 +1343,7 @@ func (api *BlockChainAPI) CreateAccessList(ctx context.Context, args Transaction
 // If the transaction itself fails, an vmErr is returned.
 func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash, args TransactionArgs) (acl types.AccessList, gasUsed uint64, vmErr error, err error) {
 	// Retrieve the execution context
-	db, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	db, header, err := stateAndHeaderByNumberOrHash(ctx, b, blockNrOrHash)
 	if db == nil || err != nil {
 		return nil, 0, nil, err
 	}
@@ -1488,7 +1509,7 @@ func (api *TransactionAPI) GetTransactionCount(ctx context.Context, address comm
 		}
 	}
 
-	state, _, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	state, _, err := stateAndHeaderByNumberOrHash(ctx, api.b, blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
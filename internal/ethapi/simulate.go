@@ -223,6 +223,11 @@ func (sim *simulator) processBlock(ctx context.Context, block *simBlock, header,
 	if sim.chainConfig.IsPrague(header.Number, header.Time) || sim.chainConfig.IsVerkle(header.Number, header.Time) {
 		core.ProcessParentBlockHash(header.ParentHash, evm)
 	}
+	// the logs of housekeeping are not reported in the results of the calls
+	housekeeping, err := core.ProcessGolemBaseHousekeeping(evm)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	var allLogs []*types.Log
 	for i, call := range block.Calls {
 		if err := ctx.Err(); err != nil {
@@ -294,6 +299,9 @@ func (sim *simulator) processBlock(ctx context.Context, block *simBlock, header,
 		reqHash := types.CalcRequestsHash(requests)
 		header.RequestsHash = &reqHash
 	}
+	if housekeeping != nil {
+		receipts = types.AppendGolemBaseHousekeepingReceipt(receipts, housekeeping, common.Hash{}, header.Number.Uint64())
+	}
 	b := types.NewBlock(header, &types.Body{Transactions: txes, Withdrawals: withdrawals}, receipts, trie.NewStackTrie(nil), sim.chainConfig)
 	repairLogs(callResults, b.Hash())
 	return b, callResults, receipts, nil
@@ -0,0 +1,25 @@
+package ethapi
+
+import (
+	"context"
+
+	"github.com/jeffcogswell/golembase-op-geth/core/state"
+	"github.com/jeffcogswell/golembase-op-geth/core/types"
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// stateAndHeaderByNumberOrHash looks up the state of the block in a span of the trace of the call.
+func stateAndHeaderByNumberOrHash(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ethapi.stateAndHeader")
+	statedb, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if header != nil && span.IsRecording() {
+		span.SetAttributes(
+			attribute.Int64("block.number", header.Number.Int64()),
+			attribute.String("block.hash", header.Hash().Hex()),
+		)
+	}
+	telemetry.EndSpan(span, err)
+	return statedb, header, err
+}
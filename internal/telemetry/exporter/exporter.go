@@ -0,0 +1,49 @@
+// Package exporter exports the spans of the telemetry package to an OpenTelemetry
+// collector over OTLP/HTTP.
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Config configures the export of spans.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP traces endpoint of the collector,
+	// e.g. http://localhost:4318/v1/traces.
+	Endpoint string
+
+	// ServiceName is the service.name resource attribute of the spans.
+	ServiceName string
+
+	// SampleRatio is the fraction of traces that are sampled. Traces started by a caller
+	// that propagates its trace context follow the sampling decision of the caller.
+	SampleRatio float64
+}
+
+// Setup enables tracing and starts exporting the spans to the collector.
+// The returned function flushes the spans that were not exported yet and disables tracing.
+func Setup(config Config) (shutdown func(context.Context) error, err error) {
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(config.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+	)
+	telemetry.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		telemetry.SetTracerProvider(nil)
+		return provider.Shutdown(ctx)
+	}, nil
+}
@@ -0,0 +1,126 @@
+package exporter
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
+	"github.com/stretchr/testify/require"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is an in-process OTLP/HTTP collector.
+type collector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := new(collectortrace.ExportTraceServiceRequest)
+	if err := proto.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+func (c *collector) span(name string) *tracepb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+type testService struct{}
+
+func (testService) Work(ctx context.Context) string {
+	_, span := telemetry.StartSpan(ctx, "test.work")
+	defer span.End()
+	return "done"
+}
+
+func TestExportRPCSpans(t *testing.T) {
+	c := new(collector)
+	collectorServer := httptest.NewServer(c)
+	defer collectorServer.Close()
+
+	shutdown, err := Setup(Config{
+		Endpoint:    collectorServer.URL + "/v1/traces",
+		ServiceName: "test",
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+	require.True(t, telemetry.Enabled())
+
+	srv := rpc.NewServer()
+	defer srv.Stop()
+	require.NoError(t, srv.RegisterName("test", testService{}))
+	rpcServer := httptest.NewServer(srv)
+	defer rpcServer.Close()
+
+	// the call continues the trace of the caller
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+	req, err := http.NewRequest(http.MethodPost, rpcServer.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"test_work","params":[]}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// shutting down flushes the spans
+	require.NoError(t, shutdown(context.Background()))
+	require.False(t, telemetry.Enabled())
+
+	call := c.span("test_work")
+	require.NotNil(t, call)
+	require.Equal(t, traceID, hex.EncodeToString(call.TraceId))
+	require.Equal(t, parentID, hex.EncodeToString(call.ParentSpanId))
+	require.Equal(t, tracepb.Span_SPAN_KIND_SERVER, call.Kind)
+
+	work := c.span("test.work")
+	require.NotNil(t, work)
+	require.Equal(t, call.SpanId, work.ParentSpanId)
+}
+
+func TestDisabledTracingDoesNotAllocate(t *testing.T) {
+	require.False(t, telemetry.Enabled())
+
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		spanCtx, span := telemetry.StartServerSpan(ctx, "test")
+		_, child := telemetry.StartSpan(spanCtx, "child")
+		telemetry.EndSpan(child, io.EOF)
+		telemetry.EndSpan(span, nil)
+	})
+	require.Zero(t, allocs)
+}
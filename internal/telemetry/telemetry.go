@@ -0,0 +1,94 @@
+// Package telemetry provides OpenTelemetry tracing of the RPC and execution path.
+//
+// Tracing is disabled until a tracer provider is installed with SetTracerProvider, e.g. by
+// the exporter package. While it is disabled, StartSpan returns the context unchanged and a
+// span that does nothing, so instrumented code does not allocate or record anything.
+//
+// This package only depends on the OpenTelemetry API, so it can be used by packages like
+// rpc without linking the SDK and the exporters into their users.
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of all spans.
+const tracerName = "github.com/jeffcogswell/golembase-op-geth"
+
+var (
+	tracer     atomic.Pointer[trace.Tracer]
+	propagator = propagation.TraceContext{}
+
+	// noopSpan is returned by StartSpan while tracing is disabled.
+	noopSpan = trace.SpanFromContext(context.Background())
+)
+
+// SetTracerProvider enables tracing with the spans created by the provider.
+// A nil provider disables tracing again.
+func SetTracerProvider(provider trace.TracerProvider) {
+	if provider == nil {
+		tracer.Store(nil)
+		return
+	}
+	t := provider.Tracer(tracerName)
+	tracer.Store(&t)
+}
+
+// Enabled reports whether tracing is enabled. Callers can check it before preparing
+// expensive span attributes.
+func Enabled() bool {
+	return tracer.Load() != nil
+}
+
+// StartSpan starts a span that is a child of the span in ctx, if any.
+// The span has to be ended by the caller, e.g. with EndSpan. Attributes should only be
+// set if span.IsRecording(), so that they are not prepared while tracing is disabled.
+func StartSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	t := tracer.Load()
+	if t == nil {
+		return ctx, noopSpan
+	}
+	return (*t).Start(ctx, name)
+}
+
+// StartServerSpan starts a span of a server handling a request of a remote client.
+func StartServerSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	t := tracer.Load()
+	if t == nil {
+		return ctx, noopSpan
+	}
+	return (*t).Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer))
+}
+
+// EndSpan records err, if any, as the status of the span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil && span.IsRecording() {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract returns a copy of ctx with the remote span context of the W3C trace context
+// headers (traceparent and tracestate) of an HTTP request, so that the spans of the
+// request continue the trace of the caller.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	if !Enabled() {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject sets the W3C trace context headers of the span in ctx on an outgoing request.
+func Inject(ctx context.Context, header http.Header) {
+	if !Enabled() {
+		return
+	}
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
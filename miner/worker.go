@@ -80,6 +80,10 @@ type environment struct {
 
 	witness *stateless.Witness
 
+	// golemBaseHousekeeping is the receipt of the Golem Base housekeeping that ran
+	// before the transactions, nil before the Kaolin upgrade
+	golemBaseHousekeeping *types.Receipt
+
 	noTxs  bool            // true if we are reproducing a block, and do not have to check interop txs
 	rpcCtx context.Context // context to control block-building RPC work. No RPC allowed if nil.
 }
@@ -147,9 +151,16 @@ func (miner *Miner) generateWork(params *generateParams, witness bool) *newPaylo
 
 	misc.EnsureCreate2Deployer(miner.chainConfig, work.header.Time, work.state)
 
+	// Golem Base housekeeping runs before the transactions of the block
+	work.golemBaseHousekeeping, err = core.ProcessGolemBaseHousekeeping(work.evm)
+	if err != nil {
+		return &newPayloadResult{err: err}
+	}
+
 	// If there are no transactions, add a housekeeping transaction.
 	// This is for the case we're running geth in dev mode wihtout op-node running.
-	if len(params.txs) == 0 {
+	// Since Kaolin, housekeeping does not need a transaction.
+	if len(params.txs) == 0 && !miner.chainConfig.GolemBaseRules(work.header.Time).IsKaolin {
 		params.txs = types.Transactions{
 			types.NewTx(&types.DepositTx{
 				// System address
@@ -223,7 +234,13 @@ func (miner *Miner) generateWork(params *generateParams, witness bool) *newPaylo
 		work.header.RequestsHash = &reqHash
 	}
 
-	block, err := miner.engine.FinalizeAndAssemble(miner.chain, work.header, work.state, &body, work.receipts)
+	// the receipt of the Golem Base housekeeping follows the receipts of the transactions
+	receipts := work.receipts
+	if work.golemBaseHousekeeping != nil {
+		receipts = types.AppendGolemBaseHousekeepingReceipt(receipts, work.golemBaseHousekeeping, common.Hash{}, work.header.Number.Uint64())
+	}
+
+	block, err := miner.engine.FinalizeAndAssemble(miner.chain, work.header, work.state, &body, receipts)
 	if err != nil {
 		return &newPayloadResult{err: err}
 	}
@@ -232,7 +249,7 @@ func (miner *Miner) generateWork(params *generateParams, witness bool) *newPaylo
 		fees:     totalFees(block, work.receipts),
 		sidecars: work.sidecars,
 		stateDB:  work.state,
-		receipts: work.receipts,
+		receipts: receipts,
 		requests: requests,
 		witness:  work.witness,
 	}
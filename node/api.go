@@ -179,8 +179,12 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
 		rpcEndpointConfig: rpcEndpointConfig{
+			jwtSecret:              api.node.rpcJWTSecret,
+			jwtAllowExpiry:         true,
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			rateLimit:              api.node.config.RPCRateLimit,
+			recorder:               api.node.rpcRecorder,
 		},
 	}
 	if cors != nil {
@@ -257,8 +261,12 @@ func (api *adminAPI) StartWS(host *string, port *int, allowedOrigins *string, ap
 		Origins: api.node.config.WSOrigins,
 		// ExposeAll: api.node.config.WSExposeAll,
 		rpcEndpointConfig: rpcEndpointConfig{
+			jwtSecret:              api.node.rpcJWTSecret,
+			jwtAllowExpiry:         true,
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			rateLimit:              api.node.config.RPCRateLimit,
+			recorder:               api.node.rpcRecorder,
 		},
 	}
 	if apis != nil {
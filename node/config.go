@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/jeffcogswell/golembase-op-geth/common"
 	"github.com/jeffcogswell/golembase-op-geth/crypto"
@@ -204,9 +205,23 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCRateLimit limits the rate of method calls per client on the HTTP and WebSocket
+	// endpoints. The authenticated endpoints are not limited.
+	RPCRateLimit rpc.RateLimitConfig `toml:",omitempty"`
+
+	// RPCRecord records the method calls of the HTTP and WebSocket endpoints and their
+	// responses to a rotating file, e.g. to replay them against another node. The
+	// authenticated endpoints are not recorded.
+	RPCRecord rpc.RecordConfig `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
+	// RPCJWTAuth requires a JWT signed with the jwt secret on the HTTP and WebSocket
+	// endpoints too, so that they accept the tokens of the authenticated endpoints.
+	// Tokens can restrict the namespaces and methods that their bearer may call.
+	RPCJWTAuth bool `toml:",omitempty"`
+
 	// EnablePersonal enables the deprecated personal namespace.
 	EnablePersonal bool `toml:"-"`
 
@@ -214,6 +229,15 @@ type Config struct {
 
 	// GolemBaseWriteAheadLogDir is the path to the write-ahead log file for the Golem Base.
 	GolemBaseWriteAheadLogDir string `toml:",omitempty"`
+
+	// GolemBaseHistory enables the entity history index of the Golem Base in the node's database.
+	GolemBaseHistory bool `toml:",omitempty"`
+
+	// GolemBaseQueryMaxSlots limits the storage slots a single Golem Base query can read, zero means no limit.
+	GolemBaseQueryMaxSlots uint64 `toml:",omitempty"`
+
+	// GolemBaseQueryTimeout limits the time a single Golem Base query can take, zero means no limit.
+	GolemBaseQueryTimeout time.Duration `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
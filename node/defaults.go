@@ -21,6 +21,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/jeffcogswell/golembase-op-geth/p2p"
 	"github.com/jeffcogswell/golembase-op-geth/p2p/nat"
@@ -67,12 +68,26 @@ var DefaultConfig = Config{
 	BatchRequestLimit:    1000,
 	BatchResponseMaxSize: 25 * 1000 * 1000,
 	GraphQLVirtualHosts:  []string{"localhost"},
+	RPCRateLimit: rpc.RateLimitConfig{
+		Burst: 200,
+		MethodCosts: map[string]int{
+			"eth_call":                 5,
+			"eth_estimateGas":          5,
+			"eth_getLogs":              10,
+			"debug_trace*":             50,
+			"golembase_queryEntities":  10,
+			"golembase_getEntityProof": 5,
+		},
+	},
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
 		MaxPeers:   50,
 		NAT:        nat.Any(),
 	},
 	DBEngine: "", // Use whatever exists, will default to Pebble if non-existent and supported
+
+	GolemBaseQueryMaxSlots: 1_000_000,
+	GolemBaseQueryTimeout:  5 * time.Second,
 }
 
 // DefaultDataDir is the default data directory to use for the databases and other
@@ -43,3 +43,26 @@ func NewJWTAuth(jwtsecret [32]byte) rpc.HTTPAuth {
 		return nil
 	}
 }
+
+// NewScopedJWTToken creates a token with the given subject that restricts its bearer to
+// the namespaces and methods of the scope. On the regular endpoints, a token with an
+// expiry is valid until then, so it can be issued once per service. Otherwise, it has
+// to be used within a minute, like the tokens of NewJWTAuth.
+func NewScopedJWTToken(jwtsecret []byte, subject string, scope rpc.Scope) (string, error) {
+	claims := jwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  subject,
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+		Namespaces: scope.Namespaces,
+		Methods:    scope.Methods,
+	}
+	if !scope.Expiry.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(scope.Expiry)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtsecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to create JWT token: %w", err)
+	}
+	return token, nil
+}
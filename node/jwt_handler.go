@@ -22,22 +22,47 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/jeffcogswell/golembase-op-geth/rpc"
 )
 
 const jwtExpiryTimeout = 60 * time.Second
 
+// jwtClaims are the claims of a token. Besides the registered claims, a token can restrict
+// the namespaces and methods that its bearer may call.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Namespaces []string `json:"namespaces,omitempty"`
+	Methods    []string `json:"methods,omitempty"`
+}
+
+// scope returns the scope of the claims, or nil if they do not restrict the bearer.
+func (c *jwtClaims) scope() *rpc.Scope {
+	if len(c.Namespaces) == 0 && len(c.Methods) == 0 && c.ExpiresAt == nil {
+		return nil
+	}
+	scope := &rpc.Scope{Namespaces: c.Namespaces, Methods: c.Methods}
+	if c.ExpiresAt != nil {
+		scope.Expiry = c.ExpiresAt.Time
+	}
+	return scope
+}
+
 type jwtHandler struct {
-	keyFunc func(token *jwt.Token) (interface{}, error)
-	next    http.Handler
+	keyFunc     func(token *jwt.Token) (interface{}, error)
+	allowExpiry bool // accept tokens with an expiry after the issued-at timeout
+	next        http.Handler
 }
 
-// newJWTHandler creates a http.Handler with jwt authentication support.
-func newJWTHandler(secret []byte, next http.Handler) http.Handler {
+// newJWTHandler creates a http.Handler with jwt authentication support. If allowExpiry
+// is set, tokens with an expiry are valid until then rather than for jwtExpiryTimeout
+// after they were issued. The authenticated endpoints never allow that.
+func newJWTHandler(secret []byte, allowExpiry bool, next http.Handler) http.Handler {
 	return &jwtHandler{
 		keyFunc: func(token *jwt.Token) (interface{}, error) {
 			return secret, nil
 		},
-		next: next,
+		allowExpiry: allowExpiry,
+		next:        next,
 	}
 }
 
@@ -45,7 +70,7 @@ func newJWTHandler(secret []byte, next http.Handler) http.Handler {
 func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	var (
 		strToken string
-		claims   jwt.RegisteredClaims
+		claims   jwtClaims
 	)
 	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
 		strToken = strings.TrimPrefix(auth, "Bearer ")
@@ -70,11 +95,18 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 		http.Error(out, "token is expired", http.StatusUnauthorized)
 	case claims.IssuedAt == nil:
 		http.Error(out, "missing issued-at", http.StatusUnauthorized)
-	case time.Since(claims.IssuedAt.Time) > jwtExpiryTimeout:
+	case !(handler.allowExpiry && claims.ExpiresAt != nil) && time.Since(claims.IssuedAt.Time) > jwtExpiryTimeout:
+		// Tokens with an expiry can be valid until then, e.g. tokens issued per service.
 		http.Error(out, "stale token", http.StatusUnauthorized)
 	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
 		http.Error(out, "future token", http.StatusUnauthorized)
 	default:
+		if claims.Subject != "" {
+			r = r.WithContext(rpc.WithRateLimitKey(r.Context(), "jwt:"+claims.Subject))
+		}
+		if scope := claims.scope(); scope != nil {
+			r = r.WithContext(rpc.WithScope(r.Context(), scope))
+		}
 		handler.next.ServeHTTP(out, r)
 	}
 }
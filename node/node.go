@@ -57,14 +57,16 @@ type Node struct {
 	state         int           // Tracks state of node lifecycle
 
 	lock          sync.Mutex
-	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	http          *httpServer //
-	ws            *httpServer //
-	httpAuth      *httpServer //
-	wsAuth        *httpServer //
-	ipc           *ipcServer  // Stores information about the ipc http server
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	lifecycles    []Lifecycle   // All registered backends, services, and auxiliary services that have a lifecycle
+	rpcAPIs       []rpc.API     // List of APIs currently provided by the node
+	http          *httpServer   //
+	ws            *httpServer   //
+	httpAuth      *httpServer   //
+	wsAuth        *httpServer   //
+	ipc           *ipcServer    // Stores information about the ipc http server
+	inprocHandler *rpc.Server   // In-process RPC request handler to process the API requests
+	rpcJWTSecret  []byte        // JWT secret of the HTTP and WebSocket endpoints, if RPCJWTAuth is set
+	rpcRecorder   *rpc.Recorder // records the calls of the HTTP and WebSocket endpoints, if RPCRecord is set
 
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
@@ -390,9 +392,21 @@ func (n *Node) startRPC() error {
 		openAPIs, allAPIs = n.getAPIs()
 	)
 
+	if n.config.RPCJWTAuth {
+		jwtSecret, err := n.obtainJWTSecret(n.config.JWTSecret)
+		if err != nil {
+			return err
+		}
+		n.rpcJWTSecret = jwtSecret
+	}
+	n.rpcRecorder = rpc.NewRecorder(n.config.RPCRecord)
 	rpcConfig := rpcEndpointConfig{
+		jwtSecret:              n.rpcJWTSecret,
+		jwtAllowExpiry:         true,
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		rateLimit:              n.config.RPCRateLimit,
+		recorder:               n.rpcRecorder,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -494,9 +508,12 @@ func (n *Node) startRPC() error {
 	}
 	// Configure authenticated API
 	if len(openAPIs) != len(allAPIs) {
-		jwtSecret, err := n.obtainJWTSecret(n.config.JWTSecret)
-		if err != nil {
-			return err
+		jwtSecret := n.rpcJWTSecret
+		if jwtSecret == nil {
+			var err error
+			if jwtSecret, err = n.obtainJWTSecret(n.config.JWTSecret); err != nil {
+				return err
+			}
 		}
 		if err := initAuth(n.config.AuthPort, jwtSecret); err != nil {
 			return err
@@ -529,6 +546,9 @@ func (n *Node) stopRPC() {
 	n.wsAuth.stop()
 	n.ipc.stop()
 	n.stopInProc()
+	if err := n.rpcRecorder.Close(); err != nil {
+		n.log.Error("Failed to close RPC recording", "err", err)
+	}
 }
 
 // startInProc registers all RPC APIs on the inproc server.
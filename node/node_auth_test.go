@@ -19,6 +19,7 @@ package node
 import (
 	"context"
 	crand "crypto/rand"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -198,6 +199,111 @@ func TestAuthEndpoints(t *testing.T) {
 	}
 }
 
+func TestScopedJWTToken(t *testing.T) {
+	secret := make([]byte, 32)
+	if _, err := crand.Read(secret); err != nil {
+		t.Fatalf("failed to create jwt secret: %v", err)
+	}
+	jwtPath := filepath.Join(t.TempDir(), "jwt_secret")
+	if err := os.WriteFile(jwtPath, []byte(hexutil.Encode(secret)), 0600); err != nil {
+		t.Fatalf("failed to prepare jwt secret file: %v", err)
+	}
+	conf := &Config{
+		HTTPHost:   "127.0.0.1",
+		WSHost:     "127.0.0.1",
+		AuthAddr:   "127.0.0.1",
+		JWTSecret:  jwtPath,
+		RPCJWTAuth: true,
+
+		WSModules:   []string{"eth", "test"},
+		HTTPModules: []string{"eth", "test"},
+	}
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create a new node: %v", err)
+	}
+	node.RegisterAPIs([]rpc.API{
+		{Namespace: "engine", Service: helloRPC("hello engine"), Authenticated: true},
+		{Namespace: "eth", Service: helloRPC("hello eth")},
+		{Namespace: "test", Service: helloRPC("hello test")},
+	})
+	if err := node.Start(); err != nil {
+		t.Fatalf("failed to start test node: %v", err)
+	}
+	defer node.Close()
+
+	token, err := NewScopedJWTToken(secret, "etl", rpc.Scope{Namespaces: []string{"eth"}, Expiry: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the token is accepted by the regular and the authenticated endpoints, and
+	// restricted to the eth namespace on both
+	endpoints := map[string]string{
+		node.HTTPEndpoint():     "test_helloWorld",
+		node.WSEndpoint():       "test_helloWorld",
+		node.HTTPAuthEndpoint(): "engine_helloWorld",
+		node.WSAuthEndpoint():   "engine_helloWorld",
+	}
+	for endpoint, denied := range endpoints {
+		cl, err := rpc.DialOptions(context.Background(), endpoint, rpc.WithHeader("Authorization", "Bearer "+token))
+		if err != nil {
+			t.Fatalf("%s: failed to dial: %v", endpoint, err)
+		}
+		var x string
+		if err := cl.Call(&x, "eth_helloWorld"); err != nil || x != "hello eth" {
+			t.Errorf("%s: allowed call failed: %q, %v", endpoint, x, err)
+		}
+		err = cl.Call(&x, denied)
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32004 {
+			t.Errorf("%s: expected %s to be unauthorized, got %v", endpoint, denied, err)
+		}
+		cl.Close()
+	}
+
+	// a token issued long ago with an expiry in the future is only accepted by the regular
+	// endpoints, the authenticated endpoints still require a recent issued-at
+	stale, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for endpoint, expectFail := range map[string]bool{
+		node.HTTPEndpoint():     false,
+		node.WSEndpoint():       false,
+		node.HTTPAuthEndpoint(): true,
+		node.WSAuthEndpoint():   true,
+	} {
+		var x string
+		cl, err := rpc.DialOptions(context.Background(), endpoint, rpc.WithHeader("Authorization", "Bearer "+stale))
+		if err == nil {
+			err = cl.Call(&x, "eth_helloWorld")
+			cl.Close()
+		}
+		if expectFail && err == nil {
+			t.Errorf("%s: expected stale token to be rejected", endpoint)
+		}
+		if !expectFail && err != nil {
+			t.Errorf("%s: stale token with expiry failed: %v", endpoint, err)
+		}
+	}
+
+	// the regular endpoints require a token
+	cl, err := rpc.DialOptions(context.Background(), node.HTTPEndpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+	var x string
+	if err := cl.Call(&x, "eth_helloWorld"); err == nil {
+		t.Error("expected call without token to fail")
+	}
+}
+
 func noneAuth(secret [32]byte) rpc.HTTPAuth {
 	return func(header http.Header) error {
 		token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
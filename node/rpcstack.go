@@ -55,9 +55,12 @@ type wsConfig struct {
 
 type rpcEndpointConfig struct {
 	jwtSecret              []byte // optional JWT secret
+	jwtAllowExpiry         bool   // accept JWTs until their expiry, never set on the auth endpoints
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
+	rateLimit              rpc.RateLimitConfig
+	recorder               *rpc.Recorder // optional recording of method calls
 }
 
 type rpcHandler struct {
@@ -306,6 +309,8 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetRateLimit(config.rateLimit)
+	srv.SetRecorder(config.recorder)
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
@@ -314,7 +319,7 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	}
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		Handler: newHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret, config.jwtAllowExpiry),
 		server:  srv,
 	})
 	return nil
@@ -341,6 +346,8 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetRateLimit(config.rateLimit)
+	srv.SetRecorder(config.recorder)
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
@@ -349,7 +356,7 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	}
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
+		Handler: newWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret, config.jwtAllowExpiry),
 		server:  srv,
 	})
 	return nil
@@ -395,19 +402,27 @@ func isWebsocket(r *http.Request) bool {
 
 // NewHTTPHandlerStack returns wrapped http-related handlers
 func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSecret []byte) http.Handler {
+	return newHTTPHandlerStack(srv, cors, vhosts, jwtSecret, false)
+}
+
+func newHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSecret []byte, jwtAllowExpiry bool) http.Handler {
 	// Wrap the CORS-handler within a host-handler
 	handler := newCorsHandler(srv, cors)
 	handler = newVHostHandler(vhosts, handler)
 	if len(jwtSecret) != 0 {
-		handler = newJWTHandler(jwtSecret, handler)
+		handler = newJWTHandler(jwtSecret, jwtAllowExpiry, handler)
 	}
 	return newGzipHandler(handler)
 }
 
 // NewWSHandlerStack returns a wrapped ws-related handler.
 func NewWSHandlerStack(srv http.Handler, jwtSecret []byte) http.Handler {
+	return newWSHandlerStack(srv, jwtSecret, false)
+}
+
+func newWSHandlerStack(srv http.Handler, jwtSecret []byte, jwtAllowExpiry bool) http.Handler {
 	if len(jwtSecret) != 0 {
-		return newJWTHandler(jwtSecret, srv)
+		return newJWTHandler(jwtSecret, jwtAllowExpiry, srv)
 	}
 	return srv
 }
@@ -561,6 +576,12 @@ func (w *gzipResponseWriter) Flush() {
 	}
 }
 
+// Unwrap returns the underlying writer, so that an http.ResponseController can set the
+// deadlines of event streams.
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.resp
+}
+
 func (w *gzipResponseWriter) close() {
 	if w.gz == nil {
 		return
@@ -18,11 +18,14 @@ package node
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -389,6 +392,14 @@ func TestJWT(t *testing.T) {
 		func() string {
 			return fmt.Sprintf("Bearer %v", issueToken(secret, nil, testClaim{"iat": time.Now().Unix() - int64(jwtExpiryTimeout.Seconds()) - 1}))
 		},
+		// stale with a future expiry, the endpoint does not allow expiry
+		func() string {
+			return fmt.Sprintf("Bearer %v", issueToken(secret, nil, testClaim{
+				"iat":        time.Now().Unix() - 3600,
+				"exp":        time.Now().Unix() + 3600,
+				"namespaces": []string{"rpc"},
+			}))
+		},
 		// wrong algo
 		func() string {
 			return fmt.Sprintf("Bearer %v", issueToken(secret, jwt.SigningMethodHS512, testClaim{"iat": time.Now().Unix() + 4}))
@@ -397,6 +408,10 @@ func TestJWT(t *testing.T) {
 		func() string {
 			return fmt.Sprintf("Bearer %v", issueToken(secret, nil, testClaim{"iat": time.Now().Unix(), "exp": time.Now().Unix()}))
 		},
+		// malformed scope
+		func() string {
+			return fmt.Sprintf("Bearer %v", issueToken(secret, nil, testClaim{"iat": time.Now().Unix(), "namespaces": "rpc"}))
+		},
 		// missing mandatory iat
 		func() string {
 			return fmt.Sprintf("Bearer %v", issueToken(secret, nil, testClaim{}))
@@ -598,6 +613,80 @@ func TestHTTPWriteTimeout(t *testing.T) {
 	})
 }
 
+// TestRateLimit checks that clients authenticated with a JWT are rate limited by its subject.
+func TestRateLimit(t *testing.T) {
+	secret := []byte("secret")
+	cfg := rpcEndpointConfig{
+		jwtSecret: secret,
+		rateLimit: rpc.RateLimitConfig{Rate: 1e-9, Burst: 2},
+	}
+	srv := createAndStartServer(t, &httpConfig{rpcEndpointConfig: cfg}, false, nil, nil)
+	defer srv.stop()
+	url := fmt.Sprintf("http://%v", srv.listenAddr())
+
+	token := func(subject string) string {
+		ss, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim{"iat": time.Now().Unix(), "sub": subject}).SignedString(secret)
+		return "Bearer " + ss
+	}
+	greet := func(subject string) string {
+		resp := rpcRequest(t, url, "test_greet", "Authorization", token(subject))
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	for range 2 {
+		if body := greet("alice"); !strings.Contains(body, `"result":"Hello"`) {
+			t.Fatalf("call failed: %s", body)
+		}
+	}
+	if body := greet("alice"); !strings.Contains(body, `"code":-32005`) {
+		t.Fatalf("expected rate limit error, got %s", body)
+	}
+	if body := greet("bob"); !strings.Contains(body, `"result":"Hello"`) {
+		t.Fatalf("call of another subject failed: %s", body)
+	}
+}
+
+// TestRecord checks that the HTTP and WebSocket endpoints record their calls.
+func TestRecord(t *testing.T) {
+	for _, transport := range []string{"http", "ws"} {
+		t.Run(transport, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rpc.jsonl")
+			recorder := rpc.NewRecorder(rpc.RecordConfig{Path: path})
+			cfg := rpcEndpointConfig{recorder: recorder}
+			srv := createAndStartServer(t, &httpConfig{rpcEndpointConfig: cfg}, true, &wsConfig{rpcEndpointConfig: cfg}, nil)
+			defer srv.stop()
+
+			cl, err := rpc.Dial(fmt.Sprintf("%s://%v", transport, srv.listenAddr()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cl.Call(nil, testMethod); err != nil {
+				t.Fatal(err)
+			}
+			cl.Close()
+			if err := recorder.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var call rpc.RecordedCall
+			if err := json.Unmarshal(data, &call); err != nil {
+				t.Fatalf("invalid recording %q: %v", data, err)
+			}
+			if call.Transport != transport || !strings.Contains(string(call.Request), testMethod) {
+				t.Fatalf("wrong recording: %s", data)
+			}
+		})
+	}
+}
+
 func apis() []rpc.API {
 	return []rpc.API{
 		{
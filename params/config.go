@@ -185,6 +185,11 @@ var (
 			Cancun: DefaultCancunBlobConfig,
 			Prague: DefaultPragueBlobConfig,
 		},
+
+		// the developer chain starts with all Golem Base upgrades
+		GolemBase: &GolemBaseConfig{
+			KaolinTime: newUint64(0),
+		},
 	}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
@@ -432,6 +437,9 @@ type ChainConfig struct {
 
 	// Optimism config, nil if not active
 	Optimism *OptimismConfig `json:"optimism,omitempty"`
+
+	// Golem Base config, nil if the defaults apply
+	GolemBase *GolemBaseConfig `json:"golemBase,omitempty"`
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -569,6 +577,7 @@ func (c *ChainConfig) Description() string {
 	if c.InteropTime != nil {
 		banner += fmt.Sprintf(" - Interop:                     @%-10v\n", *c.InteropTime)
 	}
+	banner += c.golemBaseDescription()
 	return banner
 }
 
@@ -1044,6 +1053,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.InteropTime, newcfg.InteropTime, headTimestamp, genesisTimestamp) {
 		return newTimestampCompatError("Interop fork timestamp", c.InteropTime, newcfg.InteropTime)
 	}
+	if err := c.checkGolemBaseCompatible(newcfg, headTimestamp, genesisTimestamp); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -168,6 +168,51 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToTime: 9,
 			},
 		},
+		{
+			stored:        &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(10)}},
+			new:           &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(20)}},
+			headTimestamp: 5,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(10)}},
+			new:           &ChainConfig{},
+			headTimestamp: 15,
+			wantErr: &ConfigCompatError{
+				What:         "Golem Base Kaolin upgrade timestamp",
+				StoredTime:   newUint64(10),
+				NewTime:      nil,
+				RewindToTime: 9,
+			},
+		},
+		{
+			stored:        &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(10), MaxExpirationsPerBlock: 100}},
+			new:           &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(10), MaxExpirationsPerBlock: 200}},
+			headTimestamp: 5,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(10), MaxExpirationsPerBlock: 100}},
+			new:           &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(10), MaxExpirationsPerBlock: 200}},
+			headTimestamp: 15,
+			wantErr: &ConfigCompatError{
+				What:         "Golem Base maxExpirationsPerBlock since the Kaolin upgrade",
+				StoredTime:   newUint64(10),
+				NewTime:      newUint64(10),
+				RewindToTime: 9,
+			},
+		},
+		{
+			stored:        &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(10)}},
+			new:           &ChainConfig{GolemBase: &GolemBaseConfig{KaolinTime: newUint64(10), MaxPayloadBytesPerOwner: 1 << 20}},
+			headTimestamp: 15,
+			wantErr: &ConfigCompatError{
+				What:         "Golem Base maxPayloadBytesPerOwner since the Kaolin upgrade",
+				StoredTime:   newUint64(10),
+				NewTime:      newUint64(10),
+				RewindToTime: 9,
+			},
+		},
 	}
 
 	for i, test := range tests {
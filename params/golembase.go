@@ -0,0 +1,99 @@
+package params
+
+import "fmt"
+
+// GolemBaseConfig is the configuration of the Golem Base storage layer.
+//
+// Changes to the semantics of storage transactions and housekeeping are activated by
+// named upgrades at a block timestamp, so that existing chains keep executing their
+// history under the rules it was created with. Upgrades are named after clays.
+type GolemBaseConfig struct {
+	// Kaolin enables named entities, tag annotations, content metadata and chunked uploads.
+	KaolinTime *uint64 `json:"kaolinTime,omitempty"` // Kaolin switch time (nil = no upgrade, 0 = already on Kaolin)
+
+	// MaxExpirationsPerBlock limits the number of expired entities that housekeeping
+	// deletes in a single block. Entities that exceed the limit are carried over to
+	// the following blocks. Zero means there is no limit.
+	MaxExpirationsPerBlock uint64 `json:"maxExpirationsPerBlock,omitempty"`
+
+	// Per-owner quotas enforced on storage transactions. Zero means there is no limit.
+	MaxEntitiesPerOwner     uint64 `json:"maxEntitiesPerOwner,omitempty"`
+	MaxPayloadBytesPerOwner uint64 `json:"maxPayloadBytesPerOwner,omitempty"`
+	MaxAnnotationsPerOwner  uint64 `json:"maxAnnotationsPerOwner,omitempty"`
+}
+
+// GolemBaseMaxExpirationsPerBlock returns the housekeeping limit of the chain,
+// zero if there is none.
+func (c *ChainConfig) GolemBaseMaxExpirationsPerBlock() uint64 {
+	if c.GolemBase == nil {
+		return 0
+	}
+	return c.GolemBase.MaxExpirationsPerBlock
+}
+
+// IsGolemBaseKaolin returns whether time is either equal to the Golem Base Kaolin upgrade time or greater.
+func (c *ChainConfig) IsGolemBaseKaolin(time uint64) bool {
+	return c.GolemBase != nil && isTimestampForked(c.GolemBase.KaolinTime, time)
+}
+
+// GolemBaseRules is the set of Golem Base upgrades active at a block.
+// Unlike Rules, it is passed to the Golem Base packages, which do not depend
+// on the Ethereum forks.
+type GolemBaseRules struct {
+	IsKaolin bool
+}
+
+// GolemBaseRules returns the Golem Base upgrades active at the timestamp.
+func (c *ChainConfig) GolemBaseRules(time uint64) GolemBaseRules {
+	return GolemBaseRules{
+		IsKaolin: c.IsGolemBaseKaolin(time),
+	}
+}
+
+// golemBaseDescription returns the part of the banner describing the Golem Base upgrades.
+func (c *ChainConfig) golemBaseDescription() string {
+	if c.GolemBase == nil {
+		return ""
+	}
+	var banner string
+	if c.GolemBase.KaolinTime != nil {
+		banner += fmt.Sprintf(" - Golem Base Kaolin:           @%-10v\n", *c.GolemBase.KaolinTime)
+	}
+	return banner
+}
+
+// checkGolemBaseCompatible checks whether the Golem Base upgrades of newcfg can be applied
+// to a chain at headTimestamp that was created with c.
+//
+// The housekeeping limit and the quotas are only applied since Kaolin, so they can not
+// change once Kaolin is active: the blocks since then were executed with the stored values.
+// Changing them requires rewinding the chain to before Kaolin.
+func (c *ChainConfig) checkGolemBaseCompatible(newcfg *ChainConfig, headTimestamp uint64, genesisTimestamp *uint64) *ConfigCompatError {
+	oldGolemBase, newGolemBase := GolemBaseConfig{}, GolemBaseConfig{}
+	if c.GolemBase != nil {
+		oldGolemBase = *c.GolemBase
+	}
+	if newcfg.GolemBase != nil {
+		newGolemBase = *newcfg.GolemBase
+	}
+	if isForkTimestampIncompatible(oldGolemBase.KaolinTime, newGolemBase.KaolinTime, headTimestamp, genesisTimestamp) {
+		return newTimestampCompatError("Golem Base Kaolin upgrade timestamp", oldGolemBase.KaolinTime, newGolemBase.KaolinTime)
+	}
+	if !isTimestampForked(oldGolemBase.KaolinTime, headTimestamp) {
+		return nil
+	}
+	for _, limit := range []struct {
+		what     string
+		old, new uint64
+	}{
+		{"Golem Base maxExpirationsPerBlock", oldGolemBase.MaxExpirationsPerBlock, newGolemBase.MaxExpirationsPerBlock},
+		{"Golem Base maxEntitiesPerOwner", oldGolemBase.MaxEntitiesPerOwner, newGolemBase.MaxEntitiesPerOwner},
+		{"Golem Base maxPayloadBytesPerOwner", oldGolemBase.MaxPayloadBytesPerOwner, newGolemBase.MaxPayloadBytesPerOwner},
+		{"Golem Base maxAnnotationsPerOwner", oldGolemBase.MaxAnnotationsPerOwner, newGolemBase.MaxAnnotationsPerOwner},
+	} {
+		if limit.old != limit.new {
+			return newTimestampCompatError(limit.what+" since the Kaolin upgrade", oldGolemBase.KaolinTime, newGolemBase.KaolinTime)
+		}
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"slices"
 	"strconv"
 	"sync/atomic"
 	"time"
@@ -46,6 +47,10 @@ const (
 	defaultDialTimeout = 10 * time.Second // used if context has no deadline
 	subscribeTimeout   = 10 * time.Second // overall timeout eth_subscribe, rpc_modules calls
 	unsubscribeTimeout = 10 * time.Second // timeout for *_unsubscribe calls
+
+	// Delays between attempts to resubscribe after the connection was lost.
+	minResubscribeDelay = 100 * time.Millisecond
+	maxResubscribeDelay = 10 * time.Second
 )
 
 const (
@@ -81,6 +86,10 @@ type Client struct {
 	isHTTP   bool      // connection type: http, ws or ipc
 	services *serviceRegistry
 
+	// eventStreamsUnsupported is set once the HTTP server answered a subscribe call
+	// without a stream of Server-Sent Events.
+	eventStreamsUnsupported atomic.Bool
+
 	idCounter atomic.Uint32
 
 	// This function, if non-nil, is called when the connection is lost.
@@ -89,6 +98,9 @@ type Client struct {
 	// config fields
 	batchItemLimit       int
 	batchResponseMaxSize int
+	rateLimiter          *rateLimiter
+	recorder             *Recorder
+	autoReconnect        bool
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -121,6 +133,11 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
 	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
+	handler.rateLimiter = c.rateLimiter
+	handler.recorder = c.recorder
+	if c.autoReconnect {
+		handler.resubscribe = c.resubscribe
+	}
 	return &clientConn{conn, handler}
 }
 
@@ -141,6 +158,7 @@ type requestOp struct {
 	err         error
 	resp        chan []*jsonrpcMessage // the response goes here
 	sub         *ClientSubscription    // set for Subscribe requests.
+	resubscribe bool                   // true if sub is already running
 	hadResponse bool                   // true when the request was responded to
 }
 
@@ -248,6 +266,9 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig)
 		idgen:                cfg.idgen,
 		batchItemLimit:       cfg.batchItemLimit,
 		batchResponseMaxSize: cfg.batchResponseLimit,
+		rateLimiter:          cfg.rateLimiter,
+		recorder:             cfg.recorder,
+		autoReconnect:        cfg.autoReconnect,
 		writeConn:            conn,
 		close:                make(chan struct{}),
 		closing:              make(chan struct{}),
@@ -298,6 +319,8 @@ func (c *Client) SupportedModules() (map[string]string, error) {
 // Close closes the client, aborting any in-flight requests.
 func (c *Client) Close() {
 	if c.isHTTP {
+		// End the subscriptions, calls are independent requests.
+		c.writeConn.(*httpConn).close()
 		return
 	}
 	select {
@@ -501,6 +524,14 @@ func (c *Client) ShhSubscribe(ctx context.Context, channel interface{}, args ...
 // The context argument cancels the RPC request that sets up the subscription but has no
 // effect on the subscription after Subscribe has returned.
 //
+// Over HTTP, every subscription is a separate request that receives the notifications
+// as a stream of Server-Sent Events.
+//
+// With the WithAutoReconnect option, subscriptions over websocket and IPC survive the
+// loss of the connection: the client reconnects and subscribes again, resuming after the
+// cursor of the last notification it received. Servers that support this replay the
+// notifications that were missed in between.
+//
 // Slow subscribers will be dropped eventually. Client buffers up to 20000 notifications
 // before considering the subscriber dead. The subscription Err channel will receive
 // ErrSubscriptionQueueOverflow. Use a sufficiently large buffer on the channel or ensure
@@ -515,7 +546,7 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 		panic("channel given to Subscribe must not be nil")
 	}
 	if c.isHTTP {
-		return nil, ErrNotificationsUnsupported
+		return c.subscribeHTTP(ctx, namespace, chanVal, args...)
 	}
 
 	msg, err := c.newMessage(namespace+subscribeMethodSuffix, args...)
@@ -527,6 +558,7 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 		resp: make(chan []*jsonrpcMessage, 1),
 		sub:  newClientSubscription(c, namespace, chanVal),
 	}
+	op.sub.args = args
 
 	// Send the subscription request.
 	// The arrival and validity of the response is signaled on sub.quit.
@@ -539,11 +571,80 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 	return op.sub, nil
 }
 
+// resubscribe subscribes again after the connection of sub was lost. It retries until
+// the subscription is resumed, ends or the client is closed.
+func (c *Client) resubscribe(sub *ClientSubscription) {
+	delay := minResubscribeDelay
+	for {
+		err := c.subscribeAgain(sub)
+		if err == nil {
+			return
+		}
+		var rpcErr Error
+		if errors.As(err, &rpcErr) || err == ErrClientQuit || err == errDead {
+			// The server refused to resume the subscription, or there is no way to
+			// reconnect.
+			sub.close(err)
+			return
+		}
+		log.Debug("RPC client resubscribe failed", "namespace", sub.namespace, "err", err)
+
+		select {
+		case <-time.After(delay):
+		case <-sub.forwardDone:
+			return
+		case <-c.closing:
+			sub.close(ErrClientQuit)
+			return
+		}
+		delay = min(2*delay, maxResubscribeDelay)
+	}
+}
+
+// subscribeAgain sends the subscribe call of sub again, resuming after the cursor of the
+// last notification.
+func (c *Client) subscribeAgain(sub *ClientSubscription) error {
+	args := slices.Clip(sub.args)
+	if cursor := sub.cursor(); cursor != nil {
+		args = append(args, resumeParam{ResumeFrom: cursor})
+	}
+	msg, err := c.newMessage(sub.namespace+subscribeMethodSuffix, args...)
+	if err != nil {
+		return err
+	}
+	op := &requestOp{
+		ids:         []json.RawMessage{msg.ID},
+		resp:        make(chan []*jsonrpcMessage, 1),
+		sub:         sub,
+		resubscribe: true,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	defer cancel()
+	if err := c.send(ctx, op, msg); err != nil {
+		return err
+	}
+	if _, err := op.wait(ctx, c); err != nil {
+		return err
+	}
+
+	// Unsubscribe may have been called while the call was in flight.
+	select {
+	case <-sub.forwardDone:
+		sub.requestUnsubscribe()
+	default:
+	}
+	return nil
+}
+
 // SupportsSubscriptions reports whether subscriptions are supported by the client
 // transport. When this returns false, Subscribe and related methods will return
 // ErrNotificationsUnsupported.
+//
+// Over HTTP, subscriptions use Server-Sent Events. They are assumed to be supported until
+// the server answers a subscribe call without an event stream, after which this returns false.
 func (c *Client) SupportsSubscriptions() bool {
-	return !c.isHTTP
+	return !c.isHTTP || !c.eventStreamsUnsupported.Load()
 }
 
 func (c *Client) newMessage(method string, paramsIn ...interface{}) (*jsonrpcMessage, error) {
@@ -41,6 +41,9 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	rateLimiter        *rateLimiter
+	recorder           *Recorder
+	autoReconnect      bool
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -121,6 +124,18 @@ func WithHTTPAuth(a HTTPAuth) ClientOption {
 // auth information to the request.
 type HTTPAuth func(h http.Header) error
 
+// WithAutoReconnect makes subscriptions over websocket and IPC survive the loss of the
+// connection. The client reconnects and subscribes again, resuming after the cursor of
+// the last notification it received, and the subscription stays open in between.
+//
+// Resuming is transparent if the server replays the missed notifications. A server that
+// cannot resume a subscription answers with an error, which ends the subscription.
+func WithAutoReconnect() ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.autoReconnect = true
+	})
+}
+
 // WithBatchItemLimit changes the maximum number of items allowed in batch requests.
 //
 // Note: this option applies when processing incoming batch requests. It does not affect
@@ -61,6 +61,8 @@ const (
 	errcodeDefault          = -32000
 	errcodeTimeout          = -32002
 	errcodeResponseTooLarge = -32003
+	errcodeUnauthorized     = -32004
+	errcodeLimitExceeded    = -32005
 	errcodePanic            = -32603
 	errcodeMarshalError     = -32603
 
@@ -71,6 +73,8 @@ const (
 	errMsgTimeout          = "request timed out"
 	errMsgResponseTooLarge = "response too large"
 	errMsgBatchTooLarge    = "batch too large"
+	errMsgRateLimited      = "rate limit exceeded"
+	errMsgAuthExpired      = "authorization expired"
 )
 
 var ErrNoHistoricalFallback = NoHistoricalFallbackError{}
@@ -28,7 +28,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
 	"github.com/jeffcogswell/golembase-op-geth/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // handler handles JSON-RPC messages. There is one handler per connection. Note that
@@ -65,6 +69,14 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	rateLimiter          *rateLimiter // nil if calls are not rate limited
+	recorder             *Recorder    // nil if calls are not recorded
+
+	// unsubscribeEventStream, if set, ends subscriptions of other HTTP requests of the same client.
+	unsubscribeEventStream func(context.Context, ID) bool
+
+	// resubscribe, if set, resumes client subscriptions after the connection was lost.
+	resubscribe func(*ClientSubscription)
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -353,6 +365,10 @@ func (h *handler) cancelAllRequests(err error, inflightReq *requestOp) {
 	}
 	for id, sub := range h.clientSubs {
 		delete(h.clientSubs, id)
+		if h.resubscribe != nil && err != ErrClientQuit {
+			go h.resubscribe(sub)
+			continue
+		}
 		sub.close(err)
 	}
 }
@@ -407,13 +423,17 @@ func (h *handler) handleResponses(batch []*jsonrpcMessage, handleCall func(*json
 		// indicates success. EthSubscribe gets unblocked in either case through
 		// the op.resp channel.
 		if op.sub != nil {
+			var subid string
 			if msg.Error != nil {
 				op.err = msg.Error
 			} else {
-				op.err = json.Unmarshal(msg.Result, &op.sub.subid)
+				op.err = json.Unmarshal(msg.Result, &subid)
 				if op.err == nil {
-					go op.sub.run()
-					h.clientSubs[op.sub.subid] = op.sub
+					op.sub.setID(subid)
+					if !op.resubscribe {
+						go op.sub.run()
+					}
+					h.clientSubs[subid] = op.sub
 				}
 			}
 		}
@@ -456,7 +476,7 @@ func (h *handler) handleSubscriptionResult(msg *jsonrpcMessage) {
 		return
 	}
 	if h.clientSubs[result.ID] != nil {
-		h.clientSubs[result.ID].deliver(result.Result)
+		h.clientSubs[result.ID].deliver(result.Result, result.Cursor)
 	}
 }
 
@@ -471,6 +491,7 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 	case msg.isCall():
 		resp := h.handleCall(ctx, msg)
+		h.recorder.record(ctx.ctx, start, msg, resp)
 		var logctx []any
 		logctx = append(logctx, "reqid", idForLog{msg.ID}, "duration", time.Since(start))
 		if resp.Error != nil {
@@ -506,13 +527,26 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
+	if callb != h.unsubscribeCb {
+		if err := PeerInfoFromContext(cp.ctx).Scope.check(msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+		if !h.rateLimiter.allow(cp.ctx, msg.Method) {
+			return msg.errorResponse(&internalServerError{errcodeLimitExceeded, errMsgRateLimited})
+		}
+	}
 
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	ctx, span := telemetry.StartServerSpan(cp.ctx, msg.Method)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("rpc.system", "jsonrpc"), attribute.String("rpc.method", msg.Method))
+	}
+	answer := h.runMethod(ctx, msg, callb, args)
+	endCallSpan(span, answer)
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -530,14 +564,26 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	return answer
 }
 
+// endCallSpan records the error of the answer, if any, and ends the span of the call.
+func endCallSpan(span trace.Span, answer *jsonrpcMessage) {
+	if answer.Error != nil && span.IsRecording() {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", answer.Error.Code))
+		span.SetStatus(codes.Error, answer.Error.Message)
+	}
+	span.End()
+}
+
 // handleSubscribe processes *_subscribe method calls.
 func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
 	if !h.allowSubscribe {
 		return msg.errorResponse(ErrNotificationsUnsupported)
 	}
 
+	// A resumed subscription carries the cursor to resume from as the last argument.
+	params, resumeFrom := splitResumeParam(msg.Params)
+
 	// Subscription method name is first argument.
-	name, err := parseSubscriptionName(msg.Params)
+	name, err := parseSubscriptionName(params)
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
@@ -546,21 +592,31 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if callb == nil {
 		return msg.errorResponse(&subscriptionNotFoundError{namespace, name})
 	}
+	if err := PeerInfoFromContext(cp.ctx).Scope.check(msg.Method); err != nil {
+		return msg.errorResponse(err)
+	}
+	if !h.rateLimiter.allow(cp.ctx, msg.Method) {
+		return msg.errorResponse(&internalServerError{errcodeLimitExceeded, errMsgRateLimited})
+	}
 
 	// Parse subscription name arg too, but remove it before calling the callback.
 	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
-	args, err := parsePositionalArguments(msg.Params, argTypes)
+	args, err := parsePositionalArguments(params, argTypes)
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	args = args[1:]
 
 	// Install notifier in context so the subscription handler can find it.
-	n := &Notifier{h: h, namespace: namespace}
+	n := &Notifier{h: h, namespace: namespace, resumeFrom: resumeFrom}
 	cp.notifiers = append(cp.notifiers, n)
 	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
 
-	return h.runMethod(ctx, msg, callb, args)
+	answer := h.runMethod(ctx, msg, callb, args)
+	if answer.Error == nil && n.rejectUnresumed() {
+		return msg.errorResponse(&invalidParamsError{fmt.Sprintf("subscription %s can not be resumed", name)})
+	}
+	return answer
 }
 
 // runMethod runs the Go callback for an RPC method.
@@ -579,6 +635,9 @@ func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 
 	s := h.serverSubs[id]
 	if s == nil {
+		if h.unsubscribeEventStream != nil && h.unsubscribeEventStream(ctx, id) {
+			return true, nil
+		}
 		return false, ErrSubscriptionNotFound
 	}
 	close(s.err)
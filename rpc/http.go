@@ -30,6 +30,8 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/internal/telemetry"
 )
 
 const (
@@ -203,6 +205,16 @@ func (c *Client) sendBatchHTTP(ctx context.Context, op *requestOp, msgs []*jsonr
 }
 
 func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadCloser, error) {
+	resp, err := hc.post(ctx, msg, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// post sends msg to the server. If accept is set, it replaces the accepted content type
+// of the request.
+func (hc *httpConn) post(ctx context.Context, msg interface{}, accept string) (*http.Response, error) {
 	body, err := json.Marshal(msg)
 	if err != nil {
 		return nil, err
@@ -218,7 +230,11 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	hc.mu.Lock()
 	req.Header = hc.headers.Clone()
 	hc.mu.Unlock()
+	if accept != "" {
+		req.Header.Set("accept", accept)
+	}
 	setHeaders(req.Header, headersFromContext(ctx))
+	telemetry.Inject(ctx, req.Header)
 
 	if hc.auth != nil {
 		if err := hc.auth(req.Header); err != nil {
@@ -244,7 +260,7 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 			Body:       body,
 		}
 	}
-	return resp.Body, nil
+	return resp, nil
 }
 
 // httpServerConn turns a HTTP connection into a Conn.
@@ -324,9 +340,26 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	connInfo.HTTP.Host = r.Host
 	connInfo.HTTP.Origin = r.Header.Get("Origin")
 	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
-	ctx := r.Context()
+	connInfo.RateLimitKey = s.rateLimiter.key(r)
+	connInfo.Scope = scopeOf(r)
+	ctx := telemetry.Extract(r.Context(), r.Header)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 
+	// A subscribe call of a client that accepts Server-Sent Events is answered with a
+	// stream of the notifications. Other calls are served as usual.
+	if wantsEventStream(r) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, int64(s.httpBodyLimit)))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if msgs, batch := parseMessage(body); !batch && msgs[0] != nil && msgs[0].isSubscribe() {
+			s.serveEventStream(ctx, w, msgs[0])
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
 	// All checks passed, create a codec that reads directly from the request body
 	// until EOF, writes the response to w, and orders the server to process a
 	// single request.
@@ -46,11 +46,19 @@ type JsonError = jsonError
 type subscriptionResult struct {
 	ID     string          `json:"subscription"`
 	Result json.RawMessage `json:"result,omitempty"`
+	Cursor json.RawMessage `json:"cursor,omitempty"`
 }
 
 type subscriptionResultEnc struct {
 	ID     string `json:"subscription"`
 	Result any    `json:"result"`
+	Cursor any    `json:"cursor,omitempty"`
+}
+
+// resumeParam is the last parameter of a subscribe call that resumes a subscription
+// after the given cursor.
+type resumeParam struct {
+	ResumeFrom json.RawMessage `json:"resumeFrom"`
 }
 
 type jsonrpcSubscriptionNotification struct {
@@ -356,6 +364,27 @@ func parseArgumentArray(dec *json.Decoder, types []reflect.Type) ([]reflect.Valu
 	return args, err
 }
 
+// splitResumeParam removes the resume parameter from the parameters of a subscribe call.
+// It returns the remaining parameters and the cursor, which is nil if the call does not
+// resume a subscription. A call with a cursor fails if the subscription can not be
+// resumed, see Notifier.rejectUnresumed.
+func splitResumeParam(rawArgs json.RawMessage) (json.RawMessage, json.RawMessage) {
+	var params []json.RawMessage
+	if err := json.Unmarshal(rawArgs, &params); err != nil || len(params) < 2 {
+		return rawArgs, nil
+	}
+	var fields map[string]json.RawMessage
+	last := params[len(params)-1]
+	if json.Unmarshal(last, &fields) != nil || len(fields) != 1 || fields["resumeFrom"] == nil {
+		return rawArgs, nil
+	}
+	rest, err := json.Marshal(params[:len(params)-1])
+	if err != nil {
+		return rawArgs, nil
+	}
+	return rest, fields["resumeFrom"]
+}
+
 // parseSubscriptionName extracts the subscription name from an encoded argument array.
 func parseSubscriptionName(rawArgs json.RawMessage) (string, error) {
 	dec := json.NewDecoder(bytes.NewReader(rawArgs))
@@ -0,0 +1,156 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/common/lru"
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
+	"golang.org/x/time/rate"
+)
+
+// maxRateLimitedClients is the number of clients whose token buckets are kept.
+// A client that was evicted starts again with a full bucket.
+const maxRateLimitedClients = 10_000
+
+var rateLimitedMeter = metrics.NewRegisteredMeter("rpc/ratelimited", nil)
+
+// RateLimitConfig configures the rate limiting of method calls per client.
+//
+// Every client has a token bucket that refills at Rate tokens per second and holds up to
+// Burst tokens. A call takes the cost of its method from the bucket and is rejected with
+// error code -32005 if there are not enough tokens left.
+type RateLimitConfig struct {
+	// Rate is the number of tokens per second of a client. Zero disables rate limiting.
+	Rate float64 `toml:",omitempty"`
+
+	// Burst is the maximum number of tokens of a client. Calls of methods that cost more
+	// than Burst are always rejected.
+	Burst int `toml:",omitempty"`
+
+	// MethodCosts are the costs of methods, by method name or by a prefix ending in "*",
+	// e.g. "debug_trace*". The longest match applies, other methods cost 1.
+	// A cost of 0 exempts a method from rate limiting.
+	MethodCosts map[string]int `toml:",omitempty"`
+
+	// APIKeyHeader is the HTTP header with the API key of the client. If it is empty or
+	// the header is missing, clients are identified by their IP address.
+	APIKeyHeader string `toml:",omitempty"`
+
+	// APIKeys are the API keys that identify clients. A client sending any other key is
+	// identified by its IP address, so it can not get fresh buckets by rotating keys.
+	APIKeys []string `toml:",omitempty"`
+}
+
+type rateLimitKeyContextKey struct{}
+
+// WithRateLimitKey returns a copy of the request context that identifies the client for
+// rate limiting by key, e.g. the subject of its authentication token. The key takes
+// precedence over the API key header and the remote address of the request.
+func WithRateLimitKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, rateLimitKeyContextKey{}, key)
+}
+
+// methodCost is the cost of the methods matching the pattern.
+type methodCost struct {
+	pattern string
+	prefix  bool
+	cost    int
+}
+
+type rateLimiter struct {
+	config  RateLimitConfig
+	costs   []methodCost // longest pattern first
+	apiKeys map[string]struct{}
+
+	mu      sync.Mutex
+	buckets *lru.Cache[string, *rate.Limiter]
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	l := &rateLimiter{
+		config:  config,
+		apiKeys: make(map[string]struct{}, len(config.APIKeys)),
+		buckets: lru.NewCache[string, *rate.Limiter](maxRateLimitedClients),
+	}
+	for _, key := range config.APIKeys {
+		l.apiKeys[key] = struct{}{}
+	}
+	for pattern, cost := range config.MethodCosts {
+		prefix := strings.HasSuffix(pattern, "*")
+		l.costs = append(l.costs, methodCost{strings.TrimSuffix(pattern, "*"), prefix, cost})
+	}
+	sort.Slice(l.costs, func(i, j int) bool {
+		if len(l.costs[i].pattern) != len(l.costs[j].pattern) {
+			return len(l.costs[i].pattern) > len(l.costs[j].pattern)
+		}
+		// an exact name wins over a prefix of the same length
+		return !l.costs[i].prefix && l.costs[j].prefix
+	})
+	return l
+}
+
+// cost returns the number of tokens a call of the method takes.
+func (l *rateLimiter) cost(method string) int {
+	for _, c := range l.costs {
+		if method == c.pattern || (c.prefix && strings.HasPrefix(method, c.pattern)) {
+			return c.cost
+		}
+	}
+	return 1
+}
+
+// allow takes the cost of the method from the bucket of the client of the call.
+// Calls of clients without a key, e.g. over IPC or in-process, are not limited.
+func (l *rateLimiter) allow(ctx context.Context, method string) bool {
+	if l == nil {
+		return true
+	}
+	key := PeerInfoFromContext(ctx).RateLimitKey
+	cost := l.cost(method)
+	if key == "" || cost == 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	bucket, ok := l.buckets.Get(key)
+	if !ok {
+		bucket = rate.NewLimiter(rate.Limit(l.config.Rate), l.config.Burst)
+		l.buckets.Add(key, bucket)
+	}
+	l.mu.Unlock()
+
+	if bucket.AllowN(time.Now(), cost) {
+		return true
+	}
+	rateLimitedMeter.Mark(1)
+	metrics.GetOrRegisterCounter("rpc/ratelimited/"+method, nil).Inc(1)
+	return false
+}
+
+// key identifies the client of the HTTP request: by the key set with WithRateLimitKey,
+// by its API key if it is one of the configured keys, or by its IP address.
+func (l *rateLimiter) key(r *http.Request) string {
+	if l == nil {
+		return ""
+	}
+	if key, ok := r.Context().Value(rateLimitKeyContextKey{}).(string); ok && key != "" {
+		return key
+	}
+	if l.config.APIKeyHeader != "" {
+		apiKey := r.Header.Get(l.config.APIKeyHeader)
+		if _, ok := l.apiKeys[apiKey]; ok && apiKey != "" {
+			return "apikey:" + apiKey
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
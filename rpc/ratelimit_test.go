@@ -0,0 +1,209 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newRateLimitedServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv := newTestServer()
+	// the buckets do not refill during the test
+	srv.SetRateLimit(RateLimitConfig{
+		Rate:         1e-9,
+		Burst:        3,
+		MethodCosts:  map[string]int{"test_echo": 2, "test_*": 1, "test_noArgsRets": 0},
+		APIKeyHeader: "X-Api-Key",
+		APIKeys:      []string{"secret"},
+	})
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func requireRateLimited(t *testing.T, err error) {
+	t.Helper()
+
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+}
+
+func TestRateLimitMethodCosts(t *testing.T) {
+	t.Parallel()
+
+	l := newRateLimiter(RateLimitConfig{
+		MethodCosts: map[string]int{"debug_trace*": 50, "debug_traceCall": 100, "debug_*": 5, "golembase_queryEntities": 10},
+	})
+	for method, cost := range map[string]int{
+		"debug_traceTransaction":  50,
+		"debug_traceCall":         100,
+		"debug_getRawBlock":       5,
+		"golembase_queryEntities": 10,
+		"eth_blockNumber":         1,
+	} {
+		if got := l.cost(method); got != cost {
+			t.Errorf("cost of %s: got %d, want %d", method, got, cost)
+		}
+	}
+}
+
+func TestRateLimitHTTP(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(newRateLimitedServer(t))
+	defer ts.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var result echoResult
+	if err := c.Call(&result, "test_echo", "hello", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	// echo costs 2 and only 1 token is left
+	requireRateLimited(t, c.Call(&result, "test_echo", "hello", 1, nil))
+	if err := c.Call(nil, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+	requireRateLimited(t, c.Call(nil, "test_null"))
+
+	// exempt methods are not limited
+	for range 5 {
+		if err := c.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// another API key has its own bucket
+	keyed, err := DialOptions(context.Background(), ts.URL, WithHeader("X-Api-Key", "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keyed.Close()
+	if err := keyed.Call(&result, "test_echo", "hello", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimitAPIKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(newRateLimitedServer(t))
+	defer ts.Close()
+
+	dial := func(apiKey string) *Client {
+		c, err := DialOptions(context.Background(), ts.URL, WithHeader("X-Api-Key", apiKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(c.Close)
+		return c
+	}
+
+	// unknown API keys are identified by the IP address
+	var info PeerInfo
+	if err := dial("rotated-1").Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.RateLimitKey != "ip:127.0.0.1" {
+		t.Fatalf("wrong rate limit key %q", info.RateLimitKey)
+	}
+
+	// so rotating them does not give a fresh bucket: peerInfo took 1 of the 3 tokens
+	var result echoResult
+	if err := dial("rotated-2").Call(&result, "test_echo", "hello", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	requireRateLimited(t, dial("rotated-3").Call(&result, "test_echo", "hello", 1, nil))
+}
+
+func TestRateLimitBatch(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(newRateLimitedServer(t))
+	defer ts.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	batch := []BatchElem{
+		{Method: "test_null", Result: new(any)},
+		{Method: "test_null", Result: new(any)},
+		{Method: "test_null", Result: new(any)},
+		{Method: "test_null", Result: new(any)},
+	}
+	if err := c.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	for i, elem := range batch[:3] {
+		if elem.Error != nil {
+			t.Fatalf("call %d failed: %v", i, elem.Error)
+		}
+	}
+	requireRateLimited(t, batch[3].Error)
+}
+
+func TestRateLimitWebsocket(t *testing.T) {
+	t.Parallel()
+
+	httpsrv := httptest.NewServer(newRateLimitedServer(t).WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	c, err := DialWebsocket(context.Background(), wsURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var info PeerInfo
+	if err := c.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.RateLimitKey != "ip:127.0.0.1" {
+		t.Fatalf("wrong rate limit key %q", info.RateLimitKey)
+	}
+
+	// peerInfo took 1 of the 3 tokens
+	var result echoResult
+	if err := c.Call(&result, "test_echo", "hello", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	requireRateLimited(t, c.Call(&result, "test_echo", "hello", 1, nil))
+}
+
+func TestRateLimitKeyFromContext(t *testing.T) {
+	t.Parallel()
+
+	srv := newRateLimitedServer(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.ServeHTTP(w, r.WithContext(WithRateLimitKey(r.Context(), "jwt:alice")))
+	}))
+	defer ts.Close()
+
+	c, err := DialOptions(context.Background(), ts.URL, WithHeader("X-Api-Key", "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var info PeerInfo
+	if err := c.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.RateLimitKey != "jwt:alice" {
+		t.Fatalf("wrong rate limit key %q", info.RateLimitKey)
+	}
+}
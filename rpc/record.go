@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/log"
+	"github.com/jeffcogswell/golembase-op-geth/metrics"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// RecordConfig configures the recording of method calls, see NewRecorder.
+type RecordConfig struct {
+	// Path is the file that calls are recorded to. Empty disables recording.
+	Path string `toml:",omitempty"`
+
+	// MaxSize is the size in megabytes at which the file is rotated. It defaults to
+	// 100 megabytes.
+	MaxSize int `toml:",omitempty"`
+
+	// MaxFiles is the number of rotated files that are kept. Zero keeps all of them.
+	MaxFiles int `toml:",omitempty"`
+}
+
+// RecordedCall is a method call recorded by a server, one line of a recording.
+type RecordedCall struct {
+	Time      time.Time       `json:"time"`
+	Duration  time.Duration   `json:"duration"` // in nanoseconds
+	Transport string          `json:"transport"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response"`
+}
+
+// recordQueueSize is the number of recorded calls that can wait to be written.
+const recordQueueSize = 4096
+
+var recordDroppedMeter = metrics.NewRegisteredMeter("rpc/record/dropped", nil)
+
+// Recorder records method calls and their responses as JSON lines to a rotating file.
+// A recorder can be shared by several servers, see Server.SetRecorder.
+//
+// Calls are written by a background goroutine, so that the file does not add to the
+// latency of the calls. If the file can not keep up, calls are dropped and counted by
+// the rpc/record/dropped meter.
+type Recorder struct {
+	lines     chan []byte
+	out       io.WriteCloser
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewRecorder creates a recorder. It returns nil if the config does not enable
+// recording.
+func NewRecorder(config RecordConfig) *Recorder {
+	if config.Path == "" {
+		return nil
+	}
+	r := &Recorder{
+		lines: make(chan []byte, recordQueueSize),
+		out: &lumberjack.Logger{
+			Filename:   config.Path,
+			MaxSize:    config.MaxSize,
+			MaxBackups: config.MaxFiles,
+		},
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go r.loop()
+	return r
+}
+
+// loop writes the recorded calls until the recorder is closed, then writes the calls
+// that are still queued.
+func (r *Recorder) loop() {
+	defer close(r.done)
+	for {
+		select {
+		case line := <-r.lines:
+			r.write(line)
+		case <-r.quit:
+			for {
+				select {
+				case line := <-r.lines:
+					r.write(line)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) write(line []byte) {
+	if _, err := r.out.Write(line); err != nil {
+		log.Warn("Failed to record RPC call", "err", err)
+	}
+}
+
+// record writes a call and its response. Calls are recorded in the order in which
+// they complete.
+func (r *Recorder) record(ctx context.Context, start time.Time, msg, resp *jsonrpcMessage) {
+	if r == nil {
+		return
+	}
+	call := RecordedCall{
+		Time:      start,
+		Duration:  time.Since(start),
+		Transport: PeerInfoFromContext(ctx).Transport,
+	}
+	var err error
+	if call.Request, err = json.Marshal(msg); err == nil {
+		call.Response, err = json.Marshal(resp)
+	}
+	var line []byte
+	if err == nil {
+		line, err = json.Marshal(call)
+	}
+	if err != nil {
+		log.Warn("Failed to record RPC call", "method", msg.Method, "err", err)
+		return
+	}
+
+	select {
+	case <-r.quit:
+	case r.lines <- append(line, '\n'):
+	default:
+		recordDroppedMeter.Mark(1)
+	}
+}
+
+// Close writes the queued calls and closes the file of the recording. Calls that
+// complete after Close are not recorded.
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.closeOnce.Do(func() {
+		close(r.quit)
+		<-r.done
+		r.closeErr = r.out.Close()
+	})
+	return r.closeErr
+}
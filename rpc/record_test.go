@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rpc.jsonl")
+	recorder := NewRecorder(RecordConfig{Path: path})
+	srv := newTestServer()
+	srv.SetRecorder(recorder)
+	defer srv.Stop()
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	wsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	defer wsrv.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var result echoResult
+	if err := c.Call(&result, "test_echo", "hello", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Call(nil, "test_returnError"); err == nil {
+		t.Fatal("expected error")
+	}
+	batch := []BatchElem{{Method: "test_null"}, {Method: "test_noArgsRets"}}
+	if err := c.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	wc, err := DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(wsrv.URL, "http:"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wc.Close()
+	if err := wc.Call(&result, "test_echo", "world", 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var calls []RecordedCall
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var call RecordedCall
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		calls = append(calls, call)
+	}
+
+	want := []struct {
+		transport, request, response string
+	}{
+		{"http", `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hello",1,null]}`, `{"jsonrpc":"2.0","id":1,"result":{"String":"hello","Int":1,"Args":null}}`},
+		{"http", `{"jsonrpc":"2.0","id":2,"method":"test_returnError"}`, `{"jsonrpc":"2.0","id":2,"error":{"code":444,"message":"testError","data":"testError data"}}`},
+		{"http", `{"jsonrpc":"2.0","id":3,"method":"test_null"}`, `{"jsonrpc":"2.0","id":3,"result":null}`},
+		{"http", `{"jsonrpc":"2.0","id":4,"method":"test_noArgsRets"}`, `{"jsonrpc":"2.0","id":4,"result":null}`},
+		{"ws", `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["world",2,null]}`, `{"jsonrpc":"2.0","id":1,"result":{"String":"world","Int":2,"Args":null}}`},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("recorded %d calls, want %d", len(calls), len(want))
+	}
+	for i, call := range calls {
+		if call.Transport != want[i].transport || string(call.Request) != want[i].request || string(call.Response) != want[i].response {
+			t.Errorf("call %d: got %s %s -> %s\nwant %s %s -> %s", i, call.Transport, call.Request, call.Response, want[i].transport, want[i].request, want[i].response)
+		}
+		if call.Time.IsZero() || call.Duration <= 0 {
+			t.Errorf("call %d: missing timing %v %v", i, call.Time, call.Duration)
+		}
+	}
+}
+
+func TestRecorderDisabled(t *testing.T) {
+	t.Parallel()
+
+	if r := NewRecorder(RecordConfig{}); r != nil {
+		t.Fatal("expected nil recorder without path")
+	}
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scope restricts the methods that a client may call, e.g. by the claims of its
+// authentication token.
+type Scope struct {
+	// Namespaces are the namespaces whose methods may be called, e.g. "eth".
+	Namespaces []string
+
+	// Methods are single methods that may be called, e.g. "engine_newPayloadV3".
+	// If neither Namespaces nor Methods are set, all methods may be called.
+	Methods []string
+
+	// Expiry is the time after which no method may be called. The zero time means
+	// that the scope does not expire.
+	Expiry time.Time
+}
+
+type scopeContextKey struct{}
+
+// WithScope returns a copy of the request context that restricts the client of the
+// request to the scope. On WebSocket connections, the scope applies to every call of
+// the connection.
+func WithScope(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(ctx, scopeContextKey{}, scope)
+}
+
+// scopeOf returns the scope set with WithScope on the context of the HTTP request.
+func scopeOf(r *http.Request) *Scope {
+	scope, _ := r.Context().Value(scopeContextKey{}).(*Scope)
+	return scope
+}
+
+// check returns an error if the scope does not allow the method. A nil scope allows
+// all methods.
+func (s *Scope) check(method string) error {
+	if s == nil {
+		return nil
+	}
+	if !s.Expiry.IsZero() && time.Now().After(s.Expiry) {
+		return &internalServerError{errcodeUnauthorized, errMsgAuthExpired}
+	}
+	if len(s.Namespaces) == 0 && len(s.Methods) == 0 {
+		return nil
+	}
+	namespace, _, _ := strings.Cut(method, serviceMethodSeparator)
+	if slices.Contains(s.Namespaces, namespace) || slices.Contains(s.Methods, method) {
+		return nil
+	}
+	return &internalServerError{errcodeUnauthorized, fmt.Sprintf("method %s is not authorized", method)}
+}
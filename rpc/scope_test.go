@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newScopedServer serves the test server to clients restricted to the scope.
+func newScopedServer(t *testing.T, scope *Scope) *httptest.Server {
+	t.Helper()
+
+	srv := newTestServer()
+	t.Cleanup(srv.Stop)
+	ws := srv.WebsocketHandler([]string{"*"})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(WithScope(r.Context(), scope))
+		if r.Header.Get("Upgrade") == "websocket" {
+			ws.ServeHTTP(w, r)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func requireUnauthorized(t *testing.T, err error, message string) {
+	t.Helper()
+
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeUnauthorized || !strings.Contains(err.Error(), message) {
+		t.Fatalf("expected unauthorized error %q, got %v", message, err)
+	}
+}
+
+func TestScopeCheck(t *testing.T) {
+	t.Parallel()
+
+	scope := &Scope{Namespaces: []string{"eth", "golembase"}, Methods: []string{"engine_newPayloadV3"}}
+	for method, allowed := range map[string]bool{
+		"eth_blockNumber":          true,
+		"golembase_queryEntities":  true,
+		"engine_newPayloadV3":      true,
+		"engine_forkchoiceUpdated": false,
+		"debug_traceCall":          false,
+		"ethx_foo":                 false,
+	} {
+		if err := scope.check(method); (err == nil) != allowed {
+			t.Errorf("%s: allowed %t, got error %v", method, allowed, err)
+		}
+	}
+
+	var unrestricted *Scope
+	if err := unrestricted.check("debug_traceCall"); err != nil {
+		t.Errorf("nil scope rejected call: %v", err)
+	}
+	expired := &Scope{Expiry: time.Now().Add(-time.Second)}
+	requireUnauthorized(t, expired.check("eth_blockNumber"), errMsgAuthExpired)
+}
+
+func TestScopeHTTP(t *testing.T) {
+	t.Parallel()
+
+	ts := newScopedServer(t, &Scope{Methods: []string{"test_echo"}})
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var result echoResult
+	if err := c.Call(&result, "test_echo", "hello", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	requireUnauthorized(t, c.Call(nil, "test_null"), "method test_null is not authorized")
+
+	// every call of a batch is checked
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []any{"hello", 1, nil}, Result: new(echoResult)},
+		{Method: "test_null"},
+	}
+	if err := c.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].Error != nil {
+		t.Fatalf("allowed call failed: %v", batch[0].Error)
+	}
+	requireUnauthorized(t, batch[1].Error, "method test_null is not authorized")
+
+	// subscriptions over HTTP are checked too
+	_, err = c.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 1, 1)
+	requireUnauthorized(t, err, "method nftest_subscribe is not authorized")
+}
+
+func TestScopeWebsocket(t *testing.T) {
+	t.Parallel()
+
+	expiry := time.Now().Add(time.Second)
+	ts := newScopedServer(t, &Scope{Namespaces: []string{"nftest"}, Expiry: expiry})
+	c, err := DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(ts.URL, "http:"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var result int
+	if err := c.Call(&result, "nftest_echo", 1); err != nil {
+		t.Fatal(err)
+	}
+	requireUnauthorized(t, c.Call(nil, "test_null"), "method test_null is not authorized")
+
+	// subscriptions in allowed namespaces can be created and ended
+	sub, err := c.Subscribe(context.Background(), "nftest", make(chan int, 1), "someSubscription", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub.Unsubscribe()
+
+	// the scope applies to the calls of the connection, until it expires
+	time.Sleep(time.Until(expiry))
+	requireUnauthorized(t, c.Call(&result, "nftest_echo", 1), errMsgAuthExpired)
+}
@@ -54,6 +54,9 @@ type Server struct {
 	batchItemLimit     int
 	batchResponseLimit int
 	httpBodyLimit      int
+	rateLimiter        *rateLimiter
+	recorder           *Recorder
+	eventStreams       map[ID]*eventStream // subscriptions over HTTP, by ID
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -61,6 +64,7 @@ func NewServer() *Server {
 	server := &Server{
 		idgen:         randomIDGenerator(),
 		codecs:        make(map[ServerCodec]struct{}),
+		eventStreams:  make(map[ID]*eventStream),
 		httpBodyLimit: defaultBodyLimit,
 	}
 	server.run.Store(true)
@@ -89,6 +93,28 @@ func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetRateLimit enables rate limiting of the method calls of HTTP and WebSocket clients.
+// A zero rate disables it.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRateLimit(config RateLimitConfig) {
+	if config.Rate <= 0 {
+		s.rateLimiter = nil
+		return
+	}
+	s.rateLimiter = newRateLimiter(config)
+}
+
+// SetRecorder enables the recording of the method calls of the server and their
+// responses. A nil recorder disables it.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRecorder(r *Recorder) {
+	s.recorder = r
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either an RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -114,6 +140,8 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		rateLimiter:        s.rateLimiter,
+		recorder:           s.recorder,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -140,7 +168,7 @@ func (s *Server) untrackCodec(codec ServerCodec) {
 
 // serveSingleRequest reads and processes a single RPC request from the given codec. This
 // is used to serve HTTP connections. Subscriptions and reverse calls are not allowed in
-// this mode.
+// this mode, subscriptions over HTTP are served by serveEventStream instead.
 func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 	// Don't serve if server is stopped.
 	if !s.run.Load() {
@@ -149,6 +177,9 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.rateLimiter = s.rateLimiter
+	h.recorder = s.recorder
+	h.unsubscribeEventStream = s.unsubscribeEventStream
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -226,6 +257,14 @@ type PeerInfo struct {
 	// Address of client. This will usually contain the IP address and port.
 	RemoteAddr string
 
+	// RateLimitKey identifies the client for rate limiting, e.g. "ip:127.0.0.1".
+	// It is empty if the server does not limit the rate of the connection.
+	RateLimitKey string
+
+	// Scope restricts the methods that the client may call. It is nil if the client
+	// may call all methods.
+	Scope *Scope
+
 	// Additional information for HTTP and WebSocket connections.
 	HTTP struct {
 		// Protocol version, i.e. "HTTP/1.1". This is not set for WebSocket.
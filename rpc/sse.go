@@ -0,0 +1,437 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeffcogswell/golembase-op-geth/log"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+
+	// The server sends a keepalive comment when a stream has been idle for
+	// wsPingInterval. The client drops a stream that has been silent for longer
+	// than eventStreamIdleTimeout, like a websocket that does not answer a ping.
+	eventStreamIdleTimeout = wsPingInterval + wsPongTimeout
+)
+
+var errEventStreamClosed = errors.New("event stream closed")
+
+// wantsEventStream reports whether an HTTP request accepts a stream of Server-Sent Events.
+func wantsEventStream(r *http.Request) bool {
+	for _, accept := range r.Header.Values("accept") {
+		for _, part := range strings.Split(accept, ",") {
+			if mt, _, err := mime.ParseMediaType(part); err == nil && mt == eventStreamContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eventStream is the codec of a subscription over HTTP. It writes the response to the
+// subscribe call and the notifications of the subscription as Server-Sent Events.
+//
+// Every stream carries a single subscription. The stream ends when the client
+// disconnects or unsubscribes with a separate *_unsubscribe call to the server.
+type eventStream struct {
+	server *Server
+	h      *handler
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	info   PeerInfo
+	owner  string // client that may end the stream, see eventStreamOwner
+
+	mu        sync.Mutex // serializes writes and guards the fields below
+	answered  bool       // true after the response to the subscribe call
+	id        ID         // subscription ID, set if the subscribe call succeeded
+	closeOnce sync.Once
+	closeCh   chan interface{}
+	pingReset chan struct{}
+}
+
+func newEventStream(ctx context.Context, s *Server, w http.ResponseWriter) *eventStream {
+	return &eventStream{
+		server:    s,
+		w:         w,
+		rc:        http.NewResponseController(w),
+		info:      PeerInfoFromContext(ctx),
+		owner:     eventStreamOwner(ctx),
+		closeCh:   make(chan interface{}),
+		pingReset: make(chan struct{}, 1),
+	}
+}
+
+// serveEventStream serves a subscribe call over a stream of Server-Sent Events. It blocks
+// until the stream ends.
+func (s *Server) serveEventStream(ctx context.Context, w http.ResponseWriter, msg *jsonrpcMessage) {
+	stream := newEventStream(ctx, s, w)
+	if !s.trackCodec(stream) {
+		return
+	}
+	defer s.untrackCodec(stream)
+
+	// The stream outlives the read and write timeouts of the HTTP server. Writes have
+	// their own deadline, and the keepalives detect clients that went away.
+	stream.rc.SetReadDeadline(time.Time{})
+	stream.rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("content-type", eventStreamContentType)
+	w.Header().Set("cache-control", "no-cache")
+
+	stream.h = newHandler(ctx, stream, s.idgen, &s.services, 0, 0)
+	stream.h.rateLimiter = s.rateLimiter
+	stream.h.recorder = s.recorder
+	stream.h.handleMsg(msg)
+
+	go stream.pingLoop()
+	select {
+	case <-stream.closed():
+	case <-ctx.Done():
+	}
+	stream.close()
+	stream.h.close(io.EOF, nil)
+	s.removeEventStream(stream)
+}
+
+// unsubscribeEventStream ends the subscription with the given ID and its event stream.
+// It serves *_unsubscribe calls that are sent over plain HTTP, next to the stream, and
+// only ends streams of the client of the call.
+func (s *Server) unsubscribeEventStream(ctx context.Context, id ID) bool {
+	s.mutex.Lock()
+	stream := s.eventStreams[id]
+	s.mutex.Unlock()
+
+	if stream == nil || stream.owner != eventStreamOwner(ctx) {
+		return false
+	}
+	stream.h.unsubscribe(context.Background(), id)
+	stream.close()
+	return true
+}
+
+// eventStreamOwner identifies the client of an HTTP request by its IP address and, if the
+// request was authenticated, by the key set with WithRateLimitKey. The unsubscribe call
+// is a separate request, so the connection of the stream can not be used.
+func eventStreamOwner(ctx context.Context) string {
+	addr := PeerInfoFromContext(ctx).RemoteAddr
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	key, _ := ctx.Value(rateLimitKeyContextKey{}).(string)
+	return host + "/" + key
+}
+
+func (s *Server) addEventStream(stream *eventStream) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.eventStreams[stream.id] = stream
+}
+
+func (s *Server) removeEventStream(stream *eventStream) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.eventStreams[stream.id] == stream {
+		delete(s.eventStreams, stream.id)
+	}
+}
+
+func (es *eventStream) peerInfo() PeerInfo {
+	return es.info
+}
+
+func (es *eventStream) remoteAddr() string {
+	return es.info.RemoteAddr
+}
+
+// readBatch blocks until the stream is closed, the only call of a stream is passed to
+// the handler by serveEventStream.
+func (es *eventStream) readBatch() ([]*jsonrpcMessage, bool, error) {
+	<-es.closeCh
+	return nil, false, io.EOF
+}
+
+func (es *eventStream) writeJSON(ctx context.Context, v interface{}, isError bool) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	// The stream ends if the subscribe call failed.
+	endStream := false
+	if msg, ok := v.(*jsonrpcMessage); ok && msg.isResponse() && !es.answered {
+		es.answered = true
+		if msg.Error == nil && json.Unmarshal(msg.Result, &es.id) == nil {
+			es.server.addEventStream(es)
+		} else {
+			endStream = true
+		}
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultWriteTimeout)
+	}
+	event := append(append([]byte("data: "), data...), "\n\n"...)
+	if err := es.writeLocked(deadline, event); err != nil {
+		return err
+	}
+	if endStream {
+		es.closeLocked()
+	}
+
+	// Delay the next keepalive.
+	select {
+	case es.pingReset <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// writeLocked writes and flushes an event. It assumes es.mu is held.
+func (es *eventStream) writeLocked(deadline time.Time, event []byte) error {
+	select {
+	case <-es.closeCh:
+		return errEventStreamClosed
+	default:
+	}
+
+	es.rc.SetWriteDeadline(deadline)
+	_, err := es.w.Write(event)
+	if err == nil {
+		err = es.rc.Flush()
+	}
+	if err != nil {
+		es.closeLocked()
+	}
+	return err
+}
+
+// pingLoop sends keepalive comments when the stream is idle.
+func (es *eventStream) pingLoop() {
+	var pingTimer = time.NewTimer(wsPingInterval)
+	defer pingTimer.Stop()
+
+	for {
+		select {
+		case <-es.closed():
+			return
+
+		case <-es.pingReset:
+			if !pingTimer.Stop() {
+				<-pingTimer.C
+			}
+			pingTimer.Reset(wsPingInterval)
+
+		case <-pingTimer.C:
+			es.mu.Lock()
+			es.writeLocked(time.Now().Add(wsPingWriteTimeout), []byte(": keepalive\n\n"))
+			es.mu.Unlock()
+			pingTimer.Reset(wsPingInterval)
+		}
+	}
+}
+
+func (es *eventStream) close() {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	es.closeLocked()
+}
+
+// closeLocked closes the stream. It assumes es.mu is held, so that nothing is written
+// to the response after serveEventStream returned.
+func (es *eventStream) closeLocked() {
+	es.closeOnce.Do(func() { close(es.closeCh) })
+}
+
+func (es *eventStream) closed() <-chan interface{} {
+	return es.closeCh
+}
+
+// eventStreamReader reads the events of a stream of Server-Sent Events.
+type eventStreamReader struct {
+	r *bufio.Reader
+}
+
+func newEventStreamReader(r io.Reader) *eventStreamReader {
+	return &eventStreamReader{r: bufio.NewReader(r)}
+}
+
+// next reads the next event and returns its data. The data is nil for events without
+// data, e.g. keepalive comments.
+func (s *eventStreamReader) next() ([]byte, error) {
+	var data []byte
+	for {
+		line, err := s.r.ReadBytes('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		switch {
+		case len(line) == 0:
+			return data, nil
+		case line[0] == ':':
+			// comment
+		default:
+			field, value, _ := bytes.Cut(line, []byte(":"))
+			if string(field) != "data" {
+				continue
+			}
+			if data != nil {
+				data = append(data, '\n')
+			}
+			data = append(data, bytes.TrimPrefix(value, []byte(" "))...)
+		}
+	}
+}
+
+// subscribeHTTP subscribes over a stream of Server-Sent Events. Notifications are read
+// from the stream until the subscription ends or the client is closed.
+func (c *Client) subscribeHTTP(ctx context.Context, namespace string, channel reflect.Value, args ...interface{}) (*ClientSubscription, error) {
+	hc := c.writeConn.(*httpConn)
+	select {
+	case <-hc.closed():
+		return nil, ErrClientQuit
+	default:
+	}
+
+	if c.eventStreamsUnsupported.Load() {
+		return nil, ErrNotificationsUnsupported
+	}
+
+	msg, err := c.newMessage(namespace+subscribeMethodSuffix, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// ctx only cancels the subscribe call, the stream outlives it.
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+	resp, err := hc.post(streamCtx, msg, eventStreamContentType)
+	if err != nil {
+		stop()
+		cancel()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	answer, stream, err := readSubscribeAnswer(resp)
+	if errors.Is(err, ErrNotificationsUnsupported) {
+		c.eventStreamsUnsupported.Store(true)
+	}
+	if !stop() {
+		err = ctx.Err()
+	}
+	if err == nil && answer.Error != nil {
+		err = answer.Error
+	}
+	var subid string
+	if err == nil {
+		err = json.Unmarshal(answer.Result, &subid)
+	}
+	if err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, err
+	}
+
+	sub := newClientSubscription(c, namespace, channel)
+	sub.setID(subid)
+	go sub.run()
+	go c.readEventStream(sub, stream, resp.Body, cancel)
+	return sub, nil
+}
+
+// readSubscribeAnswer reads the response to a subscribe call, which is the first event of
+// the stream. A server that does not support subscriptions over HTTP answers with plain
+// JSON instead.
+func readSubscribeAnswer(resp *http.Response) (*jsonrpcMessage, *eventStreamReader, error) {
+	var answer jsonrpcMessage
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("content-type"))
+	if mt != eventStreamContentType {
+		if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+			return nil, nil, err
+		}
+		if answer.Error != nil && !ErrNotificationsUnsupported.Is(answer.Error) {
+			return nil, nil, answer.Error
+		}
+		return nil, nil, ErrNotificationsUnsupported
+	}
+
+	stream := newEventStreamReader(resp.Body)
+	for {
+		data, err := stream.next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if data != nil {
+			return &answer, stream, json.Unmarshal(data, &answer)
+		}
+	}
+}
+
+// readEventStream delivers the notifications of the stream to the subscription.
+func (c *Client) readEventStream(sub *ClientSubscription, stream *eventStreamReader, body io.Closer, cancel context.CancelFunc) {
+	defer body.Close()
+	hc := c.writeConn.(*httpConn)
+
+	// The stream is dropped when the subscription ends, when the client is closed, and
+	// when the server sends neither notifications nor keepalives.
+	idle := time.AfterFunc(eventStreamIdleTimeout, cancel)
+	defer idle.Stop()
+	go func() {
+		select {
+		case <-sub.unsubDone:
+		case <-hc.closed():
+		}
+		cancel()
+	}()
+
+	for {
+		data, err := stream.next()
+		if err != nil {
+			select {
+			case <-hc.closed():
+				err = ErrClientQuit
+			default:
+			}
+			sub.close(err)
+			return
+		}
+		idle.Reset(eventStreamIdleTimeout)
+		if data == nil {
+			continue
+		}
+
+		var msg jsonrpcMessage
+		var result subscriptionResult
+		if json.Unmarshal(data, &msg) != nil || !msg.isNotification() || json.Unmarshal(msg.Params, &result) != nil {
+			log.Debug("Dropping invalid subscription message")
+			continue
+		}
+		if result.ID == sub.id() && !sub.deliver(result.Result, result.Cursor) {
+			return
+		}
+	}
+}
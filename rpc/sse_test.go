@@ -0,0 +1,284 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type slowNotificationService struct {
+	delay time.Duration
+}
+
+func (s slowNotificationService) Slow(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	subscription := notifier.CreateSubscription()
+	go func() {
+		time.Sleep(s.delay)
+		notifier.Notify(subscription.ID, "late")
+	}()
+	return subscription, nil
+}
+
+func TestEventStreamSubscription(t *testing.T) {
+	t.Parallel()
+
+	service := &notificationTestService{unsubscribed: make(chan string, 1)}
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("nftest", service); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ch := make(chan int)
+	sub, err := c.Subscribe(context.Background(), "nftest", ch, "someSubscription", 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for want := 10; want < 15; want++ {
+		select {
+		case got := <-ch:
+			if got != want {
+				t.Fatalf("wrong notification %d, want %d", got, want)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for notification")
+		}
+	}
+
+	// unsubscribing over plain HTTP ends the subscription on the server
+	sub.Unsubscribe()
+	select {
+	case <-service.unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not ended on the server")
+	}
+	if _, ok := <-sub.Err(); ok {
+		t.Fatal("error channel not closed after unsubscribe")
+	}
+
+	// other calls are served as usual
+	var result int
+	if err := c.Call(&result, "nftest_echo", 42); err != nil {
+		t.Fatal(err)
+	}
+	if result != 42 {
+		t.Fatalf("wrong result %d", result)
+	}
+}
+
+func TestEventStreamOutlivesServerTimeouts(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("test", slowNotificationService{delay: 1500 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(srv)
+	ts.Config.ReadTimeout = 500 * time.Millisecond
+	ts.Config.WriteTimeout = 500 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ch := make(chan string)
+	sub, err := c.Subscribe(context.Background(), "test", ch, "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	select {
+	case got := <-ch:
+		if got != "late" {
+			t.Fatalf("wrong notification %q", got)
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+}
+
+func TestEventStreamSubscribeError(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_, err = c.Subscribe(context.Background(), "nftest", make(chan int), "unknownSubscription")
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || !strings.Contains(err.Error(), "unknownSubscription") {
+		t.Fatalf("expected subscription not found error, got %v", err)
+	}
+}
+
+func TestEventStreamUnsupported(t *testing.T) {
+	t.Parallel()
+
+	// a server without support for event streams answers the subscribe call like any other call
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("content-type", contentType)
+		io.WriteString(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"notifications not supported"}}`)
+	}))
+	defer ts.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if !c.SupportsSubscriptions() {
+		t.Fatal("subscriptions over HTTP should be supported before the server answered")
+	}
+	_, err = c.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 1, 1)
+	if !errors.Is(err, ErrNotificationsUnsupported) {
+		t.Fatalf("expected ErrNotificationsUnsupported, got %v", err)
+	}
+	if c.SupportsSubscriptions() {
+		t.Fatal("subscriptions should not be supported after the server answered without an event stream")
+	}
+
+	// later subscribe calls are not sent to the server
+	_, err = c.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 1, 1)
+	if !errors.Is(err, ErrNotificationsUnsupported) {
+		t.Fatalf("expected ErrNotificationsUnsupported, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("server received %d requests, want 1", n)
+	}
+}
+
+func TestEventStreamUnsubscribeOtherClient(t *testing.T) {
+	t.Parallel()
+
+	service := &notificationTestService{unsubscribed: make(chan string, 1)}
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("nftest", service); err != nil {
+		t.Fatal(err)
+	}
+	// clients are told apart by the key of their authentication, as they share the IP address
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.ServeHTTP(w, r.WithContext(WithRateLimitKey(r.Context(), r.Header.Get("X-Client"))))
+	}))
+	defer ts.Close()
+
+	dial := func(name string) *Client {
+		c, err := DialOptions(context.Background(), ts.URL, WithHeader("X-Client", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(c.Close)
+		return c
+	}
+	alice, mallory := dial("alice"), dial("mallory")
+
+	sub, err := alice.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result bool
+	err = mallory.Call(&result, "nftest_unsubscribe", sub.id())
+	if err == nil || !strings.Contains(err.Error(), ErrSubscriptionNotFound.Error()) {
+		t.Fatalf("expected subscription not found error, got %v", err)
+	}
+	select {
+	case <-service.unsubscribed:
+		t.Fatal("subscription was ended by another client")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := alice.Call(&result, "nftest_unsubscribe", sub.id()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-service.unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not ended on the server")
+	}
+}
+
+func TestEventStreamClientClose(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("test", slowNotificationService{delay: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := c.Subscribe(context.Background(), "test", make(chan string), "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Close()
+	select {
+	case err := <-sub.Err():
+		if err != nil {
+			t.Fatalf("expected nil error after client close, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not ended by client close")
+	}
+}
+
+func TestEventStreamReader(t *testing.T) {
+	t.Parallel()
+
+	stream := newEventStreamReader(strings.NewReader(": keepalive\n\ndata: {\"a\":\ndata:1}\r\nid: 5\n\n"))
+	data, err := stream.next()
+	if err != nil || data != nil {
+		t.Fatalf("expected keepalive, got %q, %v", data, err)
+	}
+	data, err = stream.next()
+	if err != nil || string(data) != "{\"a\":\n1}" {
+		t.Fatalf("wrong event data %q, %v", data, err)
+	}
+	if _, err := stream.next(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
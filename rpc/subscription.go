@@ -105,9 +105,19 @@ type Notifier struct {
 
 	mu           sync.Mutex
 	sub          *Subscription
-	buffer       []any
+	buffer       []notification
 	callReturned bool
 	activated    bool
+
+	resumeFrom json.RawMessage // cursor sent by a client that resumes a subscription
+	resumeRead bool            // true once the subscription handler read resumeFrom
+	rejected   bool            // true if the subscribe call failed after the subscription was created
+}
+
+// notification is a buffered notification.
+type notification struct {
+	data   any
+	cursor any
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -127,9 +137,33 @@ func (n *Notifier) CreateSubscription() *Subscription {
 	return n.sub
 }
 
+// ResumeFrom reports whether the client resumes a subscription, and decodes the cursor
+// of the last notification it received into v. The subscription handler should send the
+// notifications that the client missed after that cursor before any new ones.
+func (n *Notifier) ResumeFrom(v any) (bool, error) {
+	if n.resumeFrom == nil {
+		return false, nil
+	}
+	n.mu.Lock()
+	n.resumeRead = true
+	n.mu.Unlock()
+
+	if err := json.Unmarshal(n.resumeFrom, v); err != nil {
+		return true, &invalidParamsError{"invalid resume cursor: " + err.Error()}
+	}
+	return true, nil
+}
+
 // Notify sends a notification to the client with the given data as payload.
 // If an error occurs the RPC connection is closed and the error is returned.
 func (n *Notifier) Notify(id ID, data any) error {
+	return n.NotifyWithCursor(id, data, nil)
+}
+
+// NotifyWithCursor sends a notification like Notify, along with a cursor that identifies
+// its position in the stream of notifications. A client that reconnects can resume the
+// subscription after the cursor of the last notification it received, see ResumeFrom.
+func (n *Notifier) NotifyWithCursor(id ID, data any, cursor any) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
@@ -138,10 +172,13 @@ func (n *Notifier) Notify(id ID, data any) error {
 	} else if n.sub.ID != id {
 		panic("Notify with wrong ID")
 	}
+	if n.rejected {
+		return ErrSubscriptionNotFound
+	}
 	if n.activated {
-		return n.send(n.sub, data)
+		return n.send(n.sub, data, cursor)
 	}
-	n.buffer = append(n.buffer, data)
+	n.buffer = append(n.buffer, notification{data, cursor})
 	return nil
 }
 
@@ -151,9 +188,30 @@ func (n *Notifier) takeSubscription() *Subscription {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	n.callReturned = true
+	if n.rejected {
+		return nil
+	}
 	return n.sub
 }
 
+// rejectUnresumed reports whether the subscribe call carries a cursor that the subscription
+// handler did not read with ResumeFrom, i.e. the subscription can not be resumed. The call
+// has to fail then, so the subscription created by the handler is ended and dropped.
+func (n *Notifier) rejectUnresumed() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.resumeFrom == nil || n.resumeRead {
+		return false
+	}
+	n.rejected = true
+	n.buffer = nil
+	if n.sub != nil {
+		close(n.sub.err)
+	}
+	return true
+}
+
 // activate is called after the subscription ID was sent to client. Notifications are
 // buffered before activation. This prevents notifications being sent to the client before
 // the subscription ID is sent to the client.
@@ -161,8 +219,8 @@ func (n *Notifier) activate() error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
-	for _, data := range n.buffer {
-		if err := n.send(n.sub, data); err != nil {
+	for _, notif := range n.buffer {
+		if err := n.send(n.sub, notif.data, notif.cursor); err != nil {
 			return err
 		}
 	}
@@ -170,13 +228,14 @@ func (n *Notifier) activate() error {
 	return nil
 }
 
-func (n *Notifier) send(sub *Subscription, data any) error {
+func (n *Notifier) send(sub *Subscription, data any, cursor any) error {
 	msg := jsonrpcSubscriptionNotification{
 		Version: vsn,
 		Method:  n.namespace + notificationMethodSuffix,
 		Params: subscriptionResultEnc{
 			ID:     string(sub.ID),
 			Result: data,
+			Cursor: cursor,
 		},
 	}
 	return n.h.conn.writeJSON(context.Background(), &msg, false)
@@ -207,7 +266,13 @@ type ClientSubscription struct {
 	etype     reflect.Type
 	channel   reflect.Value
 	namespace string
-	subid     string
+	args      []interface{} // arguments of the subscribe call, for resubscribing
+
+	// The subscription ID and the cursor of the last notification change when the
+	// client resubscribes after a reconnect.
+	mu         sync.Mutex
+	subid      string
+	lastCursor json.RawMessage
 
 	// The in channel receives notification values from client dispatcher.
 	in chan json.RawMessage
@@ -268,10 +333,35 @@ func (sub *ClientSubscription) Unsubscribe() {
 	})
 }
 
+func (sub *ClientSubscription) id() string {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return sub.subid
+}
+
+func (sub *ClientSubscription) setID(id string) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	sub.subid = id
+}
+
+// cursor returns the cursor of the last notification, or nil if the server did not send
+// one.
+func (sub *ClientSubscription) cursor() json.RawMessage {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return sub.lastCursor
+}
+
 // deliver is called by the client's message dispatcher to send a notification value.
-func (sub *ClientSubscription) deliver(result json.RawMessage) (ok bool) {
+func (sub *ClientSubscription) deliver(result json.RawMessage, cursor json.RawMessage) (ok bool) {
 	select {
 	case sub.in <- result:
+		if cursor != nil {
+			sub.mu.Lock()
+			sub.lastCursor = cursor
+			sub.mu.Unlock()
+		}
 		return true
 	case <-sub.forwardDone:
 		return false
@@ -373,6 +463,6 @@ func (sub *ClientSubscription) requestUnsubscribe() error {
 	var result interface{}
 	ctx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
 	defer cancel()
-	err := sub.client.CallContext(ctx, &result, sub.namespace+unsubscribeMethodSuffix, sub.subid)
+	err := sub.client.CallContext(ctx, &result, sub.namespace+unsubscribeMethodSuffix, sub.id())
 	return err
 }
@@ -20,11 +20,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"net"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -282,3 +284,265 @@ func TestNotify(t *testing.T) {
 		t.Errorf("have:\n%v\nwant:\n%v\n", have, want)
 	}
 }
+
+func TestNotifyWithCursor(t *testing.T) {
+	t.Parallel()
+
+	out := new(bytes.Buffer)
+	id := ID("test")
+	notifier := &Notifier{
+		h:         &handler{conn: &mockConn{json.NewEncoder(out)}},
+		sub:       &Subscription{ID: id},
+		activated: true,
+	}
+	notifier.NotifyWithCursor(id, "hello", 5)
+	have := strings.TrimSpace(out.String())
+	want := `{"jsonrpc":"2.0","method":"_subscription","params":{"subscription":"test","result":"hello","cursor":5}}`
+	if have != want {
+		t.Errorf("have:\n%v\nwant:\n%v\n", have, want)
+	}
+}
+
+func TestSplitResumeParam(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		params, rest, cursor string
+	}{
+		{`["events"]`, `["events"]`, ``},
+		{`["events",{"resumeFrom":"0x5"}]`, `["events"]`, `"0x5"`},
+		{`["logs",{"address":"0x01"},{"resumeFrom":{"blockNumber":"0x1"}}]`, `["logs",{"address":"0x01"}]`, `{"blockNumber":"0x1"}`},
+		{`["logs",{"address":"0x01","resumeFrom":"0x5"}]`, `["logs",{"address":"0x01","resumeFrom":"0x5"}]`, ``},
+	}
+	for _, test := range tests {
+		rest, cursor := splitResumeParam(json.RawMessage(test.params))
+		if string(rest) != test.rest || string(cursor) != test.cursor {
+			t.Errorf("params %s: got %s, %s, want %s, %s", test.params, rest, cursor, test.rest, test.cursor)
+		}
+	}
+}
+
+func TestSubscribeResumeNotResumable(t *testing.T) {
+	t.Parallel()
+
+	service := &notificationTestService{unsubscribed: make(chan string, 1)}
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("nftest", service); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(srv)
+	defer client.Close()
+
+	// someSubscription does not read the cursor, so it can not be resumed
+	_, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 0, resumeParam{ResumeFrom: json.RawMessage("5")})
+	if err == nil || !strings.Contains(err.Error(), "can not be resumed") {
+		t.Fatalf("expected error for resuming, got %v", err)
+	}
+	// and the subscription created by the handler is ended
+	select {
+	case <-service.unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not ended on the server")
+	}
+}
+
+// resumeTestService keeps a log of events. Its subscriptions replay the events after the
+// cursor of a resumed subscription, up to maxReplay events back.
+type resumeTestService struct {
+	maxReplay int
+
+	mu     sync.Mutex
+	events []int
+	subs   map[chan int]struct{}
+}
+
+func newResumeTestService(maxReplay int) *resumeTestService {
+	return &resumeTestService{maxReplay: maxReplay, subs: make(map[chan int]struct{})}
+}
+
+// publish appends the next event to the log and sends it to the subscriptions.
+func (s *resumeTestService) publish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := len(s.events) + 1
+	s.events = append(s.events, v)
+	for ch := range s.subs {
+		ch <- v
+	}
+}
+
+func (s *resumeTestService) Events(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	var cursor int
+	resume, err := notifier.ResumeFrom(&cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if resume && len(s.events)-cursor > s.maxReplay {
+		return nil, errors.New("too far back")
+	}
+	sub := notifier.CreateSubscription()
+	if resume {
+		for _, v := range s.events[cursor:] {
+			notifier.NotifyWithCursor(sub.ID, v, v)
+		}
+	}
+	ch := make(chan int, 100)
+	s.subs[ch] = struct{}{}
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.subs, ch)
+			s.mu.Unlock()
+		}()
+		for {
+			select {
+			case v := <-ch:
+				notifier.NotifyWithCursor(sub.ID, v, v)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// newDroppableClient creates a client with auto-reconnect. The drop function drops its
+// connection to the server, and fails reconnects until restore is called.
+func newDroppableClient(t *testing.T, srv *Server) (client *Client, drop, restore func()) {
+	var (
+		mu   sync.Mutex
+		conn net.Conn
+		down bool
+	)
+	connect := func(context.Context) (ServerCodec, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			return nil, errors.New("server down")
+		}
+		p1, p2 := net.Pipe()
+		go srv.ServeCodec(NewCodec(p1), 0)
+		conn = p2
+		return NewCodec(p2), nil
+	}
+	cfg := new(clientConfig)
+	WithAutoReconnect().applyOption(cfg)
+	client, err := newClient(context.Background(), cfg, connect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drop = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		down = true
+		conn.Close()
+	}
+	restore = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		down = false
+	}
+	return client, drop, restore
+}
+
+func TestClientSubscriptionResume(t *testing.T) {
+	t.Parallel()
+
+	service := newResumeTestService(10)
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("test", service); err != nil {
+		t.Fatal(err)
+	}
+	client, drop, restore := newDroppableClient(t, srv)
+	defer client.Close()
+
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "test", ch, "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	receive := func(want int) {
+		t.Helper()
+		select {
+		case got := <-ch:
+			if got != want {
+				t.Fatalf("wrong event %d, want %d", got, want)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %d", want)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		service.publish()
+		receive(i)
+	}
+
+	// Events published while the connection is down are replayed after the reconnect,
+	// then the subscription continues with new events.
+	drop()
+	service.publish()
+	service.publish()
+	restore()
+	for i := 4; i <= 5; i++ {
+		receive(i)
+	}
+	service.publish()
+	receive(6)
+
+	select {
+	case got := <-ch:
+		t.Fatalf("unexpected event %d", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestClientSubscriptionResumeRejected(t *testing.T) {
+	t.Parallel()
+
+	service := newResumeTestService(2)
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("test", service); err != nil {
+		t.Fatal(err)
+	}
+	client, drop, restore := newDroppableClient(t, srv)
+	defer client.Close()
+
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "test", ch, "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.publish()
+	<-ch
+
+	// The events missed while the connection is down exceed the replay window.
+	drop()
+	for i := 0; i < 3; i++ {
+		service.publish()
+	}
+	restore()
+	select {
+	case err := <-sub.Err():
+		if err == nil || !strings.Contains(err.Error(), "too far back") {
+			t.Fatalf("wrong subscription error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not ended")
+	}
+}